@@ -4,6 +4,7 @@ go 1.22
 
 require (
 	github.com/alecthomas/chroma/v2 v2.23.1
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/gdamore/tcell/v2 v2.7.4
 	github.com/rivo/tview v0.0.0-20240625185742-b0a7293b8130
 	gopkg.in/yaml.v3 v3.0.1
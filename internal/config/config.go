@@ -0,0 +1,657 @@
+// Package config loads lazyhydra's configuration file and resolves the paths it names.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// xdgDir returns the lazyhydra directory under an XDG base directory: $env when it
+// holds an absolute path (relative ones are ignored, as the spec requires), otherwise
+// the default below the home directory.
+func xdgDir(env string, defaultDir ...string) string {
+	if xdg := os.Getenv(env); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "lazyhydra")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(append(append([]string{home}, defaultDir...), "lazyhydra")...)
+}
+
+// Dir returns the lazyhydra configuration directory.
+// Priority: $LAZYHYDRA_CONFIG_DIR > $XDG_CONFIG_HOME/lazyhydra > ~/.config/lazyhydra
+func Dir() string {
+	if dir := os.Getenv("LAZYHYDRA_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// StateDir returns the lazyhydra state directory for logs and other runtime data.
+// Priority: $XDG_STATE_HOME/lazyhydra > ~/.local/state/lazyhydra
+func StateDir() string {
+	return xdgDir("XDG_STATE_HOME", ".local", "state")
+}
+
+// DataDir returns the lazyhydra data directory for templates.
+// Priority: $XDG_DATA_HOME/lazyhydra > ~/.local/share/lazyhydra
+func DataDir() string {
+	return xdgDir("XDG_DATA_HOME", ".local", "share")
+}
+
+// TemplatesDir returns the directory holding override templates
+func TemplatesDir() string {
+	return filepath.Join(DataDir(), "templates")
+}
+
+// MigrateTemplates moves templates from the config directory, where older versions kept
+// them, to TemplatesDir. Nothing is moved once the new location exists.
+func MigrateTemplates(store fsys.Store) error {
+	legacy := filepath.Join(Dir(), "templates")
+	dir := TemplatesDir()
+	if _, err := store.Stat(legacy); err != nil {
+		return nil
+	}
+	if _, err := store.Stat(dir); err == nil {
+		return nil
+	}
+
+	if err := store.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return fmt.Errorf("creating data directory: %w", err)
+	}
+	if err := store.Rename(legacy, dir); err != nil {
+		return fmt.Errorf("moving templates to %s: %w", dir, err)
+	}
+	logging.Logger.Info("moved templates", "from", legacy, "to", dir)
+	return nil
+}
+
+// HookSet lists shell commands to run after overrides are applied or removed, or the
+// applied state is saved. It is read from the config file and from apply.md frontmatter.
+type HookSet struct {
+	Apply  []string `yaml:"apply"`
+	Remove []string `yaml:"remove"`
+	Save   []string `yaml:"save"`
+}
+
+// Markers configures the markers shown for override types in the Applied panel. Text
+// and Colors are keyed by override type ("+", "=", ...) and replace the style's defaults.
+type Markers struct {
+	Style  string            `yaml:"style"`
+	Text   map[string]string `yaml:"text"`
+	Colors map[string]string `yaml:"colors"`
+}
+
+// Icons sets the glyph shown before each override in the lists
+type Icons struct {
+	Style string            `yaml:"style"` // IconStyleNone, IconStyleASCII, IconStyleUnicode or IconStyleNerdFont
+	Kinds map[string]string `yaml:"kinds"` // glyph by kind of override: merge, replace, value, sweep or composite
+	Tags  map[string]string `yaml:"tags"`  // glyph by tag, shown instead of the kind's
+}
+
+// Icon styles, chosen with icons.style
+const (
+	IconStyleNone     = "none"     // no icons
+	IconStyleASCII    = "ascii"    // letters, for any terminal
+	IconStyleUnicode  = "unicode"  // symbols most fonts have
+	IconStyleNerdFont = "nerdfont" // glyphs of a Nerd Font (https://www.nerdfonts.com)
+)
+
+// Layout arranges the TUI's panels. Weights share out the space between two panels.
+type Layout struct {
+	Direction      string `yaml:"direction"`       // LayoutHorizontal or LayoutVertical
+	Split          int    `yaml:"split"`           // tenths of the width (height when vertical) taken by the lists
+	Lists          [2]int `yaml:"lists"`           // weights of Available and Applied
+	Content        [2]int `yaml:"content"`         // weights of Override Content and Override String
+	OverrideString bool   `yaml:"override_string"` // show the Override String panel
+}
+
+// Layout directions, chosen with layout.direction
+const (
+	LayoutHorizontal = "horizontal" // the lists left of the content, stacked
+	LayoutVertical   = "vertical"   // the lists above the content, side by side
+)
+
+// check reports layout settings the TUI cannot arrange
+func (l Layout) check() error {
+	switch {
+	case l.Direction != LayoutHorizontal && l.Direction != LayoutVertical:
+		return fmt.Errorf("layout.direction: unknown direction %q (want horizontal or vertical)", l.Direction)
+	case l.Split < 1 || l.Split > 9:
+		return fmt.Errorf("layout.split: %d is not between 1 and 9 tenths", l.Split)
+	case l.Lists[0] < 1 || l.Lists[1] < 1:
+		return fmt.Errorf("layout.lists: weights must be at least 1, got %v", l.Lists)
+	case l.Content[0] < 1 || l.Content[1] < 1:
+		return fmt.Errorf("layout.content: weights must be at least 1, got %v", l.Content)
+	}
+	return nil
+}
+
+// Marker styles, chosen with markers.style
+const (
+	MarkerStyleSymbols = "symbols" // the type itself: + = ++ --
+	MarkerStyleBadges  = "badges"  // words such as [merge] and [replace]
+	MarkerStyleShapes  = "shapes"  // a different shape for each type
+)
+
+// Config holds application configuration loaded from config.yaml
+type Config struct {
+	EnvVarName         string            `yaml:"env_var_name"`
+	OverrideStrVarName string            `yaml:"override_str_var_name"`
+	DerivedVars        map[string]string `yaml:"derived_vars"`     // env var name -> derived value
+	ExportGroupVar     string            `yaml:"export_group_var"` // name of each export group's override string, with {GROUP}
+	OverridesDir       string            `yaml:"overrides_dir"`
+	HydraConfigsDir    string            `yaml:"hydra_configs_dir"`
+	ProjectEnvFile     string            `yaml:"project_env_file"`
+	EnvFormat          string            `yaml:"env_format"`
+	DirenvCommand      string            `yaml:"direnv_command"`
+	OverridesFile      string            `yaml:"overrides_file"`
+	PreviewWrites      bool              `yaml:"preview_writes"`
+	ReadOnly           bool              `yaml:"read_only"`
+	RunInject          string            `yaml:"run_inject"`
+	RunCommand         string            `yaml:"run_command"`
+	CommandTemplate    string            `yaml:"command_template"`
+	RunTargets         []RunTarget       `yaml:"run_targets"`
+	Projects           []Bookmark        `yaml:"projects"`
+	PrimaryConfig      string            `yaml:"primary_config"`
+	HydraVersion       string            `yaml:"hydra_version"` // HydraVersionAuto or one of HydraVersions
+	ShowDescriptions   bool              `yaml:"show_descriptions"`
+	OverrideFormat     string            `yaml:"override_format"`
+	BranchEnvironments bool              `yaml:"branch_environments"`
+	RecordGitCommit    bool              `yaml:"record_git_commit"`
+	Hooks              HookSet           `yaml:"hooks"`
+	Markers            Markers           `yaml:"markers"`
+	Layout             Layout            `yaml:"layout"`
+	Icons              Icons             `yaml:"icons"`
+
+	overrideTmpl *template.Template // parsed OverrideFormat
+	commandTmpl  *template.Template // parsed CommandTemplate
+	hydraVersion string             // Hydra version in effect, see Hydra
+	hydraSource  string
+	root         string // project root $PROJECT_ROOT expands to, see ExpandPath
+}
+
+// Values of derived_vars, computed from the applied set when saving
+const (
+	DerivedCount = "count" // number of applied overrides
+	DerivedHash  = "hash"  // short hash of the applied set, the same whenever the same overrides are applied
+	DerivedNames = "names" // comma-separated names of the applied overrides
+	DerivedArgs  = "args"  // the override string on one line, e.g. for WANDB_NOTES
+)
+
+var derivedValues = []string{DerivedCount, DerivedHash, DerivedNames, DerivedArgs}
+
+// DefaultExportGroupVar names the env var of each export group, e.g. HYDRA_TRAIN_STR
+const DefaultExportGroupVar = "HYDRA_" + groupPlaceholder + "_STR"
+
+// groupPlaceholder stands for the export group in export_group_var
+const groupPlaceholder = "{GROUP}"
+
+// ProjectFile marks the project root. It may set the env var names, env file and Hydra
+// version for its project, overriding config.yaml.
+const ProjectFile = ".lazyhydra.yaml"
+
+// projectConfig holds the settings ProjectFile may set
+type projectConfig struct {
+	EnvVarName         string            `yaml:"env_var_name"`
+	OverrideStrVarName string            `yaml:"override_str_var_name"`
+	DerivedVars        map[string]string `yaml:"derived_vars"`
+	ExportGroupVar     string            `yaml:"export_group_var"`
+	ProjectEnvFile     string            `yaml:"project_env_file"`
+	HydraVersion       string            `yaml:"hydra_version"`
+}
+
+// envVarNamePattern matches names the env file formats can all export
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Env file formats, chosen with env_format
+const (
+	EnvFormatDirenv     = "direnv"     // export lines in .envrc, loaded by direnv
+	EnvFormatDotenv     = "dotenv"     // NAME="value" lines in .env
+	EnvFormatPowerShell = "powershell" // $env:NAME = 'value' lines in .env.ps1, dot-sourced
+)
+
+// DefaultDirenvCommand is run in the project root after saving an env file in the
+// direnv format, so the new values load without a prompt
+const DefaultDirenvCommand = "direnv allow"
+
+// defaultEnvFiles is the project_env_file used for each env_format unless set
+var defaultEnvFiles = map[string]string{
+	EnvFormatDirenv:     ".envrc",
+	EnvFormatDotenv:     ".env",
+	EnvFormatPowerShell: ".env.ps1",
+}
+
+// defaultEnvFormat is direnv, except on Windows where direnv is rarely available
+func defaultEnvFormat() string {
+	if runtime.GOOS == "windows" {
+		return EnvFormatPowerShell
+	}
+	return EnvFormatDirenv
+}
+
+// DefaultOverrideFormat renders config group overrides as +experiment/config/logging=name_override,
+// or +experiment/config/logging@pkg=name_override for overrides placed in a package
+const DefaultOverrideFormat = "{{.Type}}{{.BlockPath}}{{if .Package}}@{{.Package}}{{end}}={{.Name}}_override"
+
+// OverrideStringData is what the override_format template is executed with
+type OverrideStringData struct {
+	Type       string // "+", "=", ...
+	Block      string // e.g., "experiment.config.logging"
+	BlockPath  string // block with slashes, e.g., "experiment/config/logging"
+	ModulePath string
+	Module     string
+	File       string
+	Package    string // package from frontmatter, e.g. "_global_"; empty for the default
+	Name       string
+}
+
+// ParseOverrideFormat parses an override_format template
+func ParseOverrideFormat(format string) (*template.Template, error) {
+	return template.New("override_format").Option("missingkey=error").Parse(format)
+}
+
+// FormatOverride renders a config group override with override_format. When the
+// template fails for this override, the default format is used instead.
+func (c *Config) FormatOverride(data OverrideStringData) string {
+	var b strings.Builder
+	if err := c.overrideTmpl.Execute(&b, data); err != nil {
+		logging.Logger.Warn("override_format failed, using the default", "override", data.Name, "error", err)
+		b.Reset()
+		template.Must(ParseOverrideFormat(DefaultOverrideFormat)).Execute(&b, data)
+	}
+	return b.String()
+}
+
+// DefaultCommandTemplate renders the command previewed with the applied overrides
+const DefaultCommandTemplate = "python train.py {{.Overrides}}"
+
+// CommandData is what command_template is executed with. The override strings are
+// shell-quoted, so the rendered command can be pasted into a shell.
+type CommandData struct {
+	Overrides string            // arguments of every applied override
+	Groups    map[string]string // arguments of each export group, by group
+	Root      string            // project root
+}
+
+// ParseCommandTemplate parses a command_template
+func ParseCommandTemplate(text string) (*template.Template, error) {
+	return template.New("command_template").Option("missingkey=zero").Parse(text)
+}
+
+// RenderCommand renders command_template for the applied overrides
+func (c *Config) RenderCommand(data CommandData) (string, error) {
+	var b strings.Builder
+	if err := c.commandTmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// RunTarget is a named command, such as train or eval, that the run menu starts with
+// the applied overrides
+type RunTarget struct {
+	Name     string `yaml:"name"`
+	Command  string `yaml:"command"`  // command template, executed like command_template
+	Terminal bool   `yaml:"terminal"` // run with the whole terminal instead of in the output panel
+
+	tmpl *template.Template // parsed Command
+}
+
+// Render renders the target's command for the applied overrides
+func (t *RunTarget) Render(data CommandData) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// parseRunTargets checks run_targets and parses their command templates
+func (c *Config) parseRunTargets() error {
+	seen := make(map[string]bool)
+	for i := range c.RunTargets {
+		t := &c.RunTargets[i]
+		if t.Name == "" || t.Command == "" {
+			return fmt.Errorf("run_targets: entry %d needs a name and a command", i+1)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("run_targets: %s is defined twice", t.Name)
+		}
+		seen[t.Name] = true
+		tmpl, err := ParseCommandTemplate(t.Command)
+		if err != nil {
+			return fmt.Errorf("run_targets: parsing the command of %s: %w", t.Name, err)
+		}
+		t.tmpl = tmpl
+	}
+	return nil
+}
+
+// Bookmark is a project root the project switcher lists and --project opens by name
+type Bookmark struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"` // ~/ and environment variables are expanded
+}
+
+// Root returns the bookmarked project root with its path expanded
+func (b Bookmark) Root() string {
+	return filepath.Clean(ExpandPath(b.Path))
+}
+
+// checkProjects reports bookmarks without a name or path and names used twice
+func (c *Config) checkProjects() error {
+	seen := make(map[string]bool)
+	for i, b := range c.Projects {
+		if b.Name == "" || b.Path == "" {
+			return fmt.Errorf("projects: entry %d needs a name and a path", i+1)
+		}
+		if seen[b.Name] {
+			return fmt.Errorf("projects: %s is defined twice", b.Name)
+		}
+		seen[b.Name] = true
+	}
+	return nil
+}
+
+// FindProject returns the bookmark called name
+func (c *Config) FindProject(name string) (Bookmark, bool) {
+	for _, b := range c.Projects {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return Bookmark{}, false
+}
+
+// Default returns the default configuration
+func Default() *Config {
+	return &Config{
+		EnvVarName:         "HYDRA_OVERRIDES",
+		OverrideStrVarName: "HYDRA_OVERRIDE_STR",
+		ExportGroupVar:     DefaultExportGroupVar,
+		OverridesDir:       "$PROJECT_ROOT/conf/overrides",
+		HydraConfigsDir:    "$PROJECT_ROOT/conf",
+		ProjectEnvFile:     defaultEnvFiles[defaultEnvFormat()],
+		EnvFormat:          defaultEnvFormat(),
+		DirenvCommand:      DefaultDirenvCommand,
+		RunInject:          "both",
+		PrimaryConfig:      "config",
+		HydraVersion:       HydraVersionAuto,
+		ShowDescriptions:   true,
+		RecordGitCommit:    true,
+		OverrideFormat:     DefaultOverrideFormat,
+		Markers:            Markers{Style: MarkerStyleSymbols},
+		Icons:              Icons{Style: IconStyleNone},
+		Layout:             Layout{Direction: LayoutHorizontal, Split: 4, Lists: [2]int{1, 1}, Content: [2]int{3, 1}, OverrideString: true},
+		CommandTemplate:    DefaultCommandTemplate,
+		overrideTmpl:       template.Must(ParseOverrideFormat(DefaultOverrideFormat)),
+		commandTmpl:        template.Must(ParseCommandTemplate(DefaultCommandTemplate)),
+	}
+}
+
+// Path returns the path of the config file
+func Path() string {
+	return filepath.Join(Dir(), "config.yaml")
+}
+
+// Load reads the config file from store, falling back to the defaults when it does not
+// exist, and applies the env var settings of the ProjectFile of the project in
+// $PROJECT_ROOT
+func Load(store fsys.Store) (*Config, error) {
+	return LoadRoot(store, os.Getenv("PROJECT_ROOT"))
+}
+
+// LoadRoot is Load for the project at root: $PROJECT_ROOT in the config's paths refers to
+// root whatever the environment says
+func LoadRoot(store fsys.Store, root string) (*Config, error) {
+	configPath := Path()
+
+	config := Default()
+	config.root = root
+	data, err := store.ReadFile(configPath)
+	switch {
+	case os.IsNotExist(err):
+		logging.Logger.Debug("config file not found, using defaults", "path", configPath)
+	case err != nil:
+		return nil, fmt.Errorf("reading config: %w", err)
+	default:
+		config.ProjectEnvFile = "" // follows env_format unless set
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("parsing config: %w", err)
+		}
+		if _, ok := defaultEnvFiles[config.EnvFormat]; !ok {
+			return nil, fmt.Errorf("unknown env_format %q (want direnv, dotenv or powershell)", config.EnvFormat)
+		}
+		switch config.Markers.Style {
+		case MarkerStyleSymbols, MarkerStyleBadges, MarkerStyleShapes:
+		default:
+			return nil, fmt.Errorf("unknown markers.style %q (want symbols, badges or shapes)", config.Markers.Style)
+		}
+		if config.ProjectEnvFile == "" {
+			config.ProjectEnvFile = defaultEnvFiles[config.EnvFormat]
+		}
+		switch config.Icons.Style {
+		case IconStyleNone, IconStyleASCII, IconStyleUnicode, IconStyleNerdFont:
+		default:
+			return nil, fmt.Errorf("unknown icons.style %q (want none, ascii, unicode or nerdfont)", config.Icons.Style)
+		}
+		if err := config.Layout.check(); err != nil {
+			return nil, err
+		}
+		if config.overrideTmpl, err = ParseOverrideFormat(config.OverrideFormat); err != nil {
+			return nil, fmt.Errorf("parsing override_format: %w", err)
+		}
+		if config.commandTmpl, err = ParseCommandTemplate(config.CommandTemplate); err != nil {
+			return nil, fmt.Errorf("parsing command_template: %w", err)
+		}
+		if err := config.parseRunTargets(); err != nil {
+			return nil, err
+		}
+		if err := config.checkProjects(); err != nil {
+			return nil, err
+		}
+		if err := checkHydraVersion("hydra_version", config.HydraVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.loadProject(store); err != nil {
+		return nil, err
+	}
+	if err := config.checkEnvVars(); err != nil {
+		return nil, err
+	}
+	config.resolveHydraVersion(store)
+
+	logging.Logger.Debug("loaded config", "path", configPath,
+		"env_var_name", config.EnvVarName,
+		"override_str_var_name", config.OverrideStrVarName,
+		"derived_vars", config.DerivedVars,
+		"export_group_var", config.ExportGroupVar,
+		"overrides_dir", config.OverridesDir,
+		"hydra_configs_dir", config.HydraConfigsDir,
+		"project_env_file", config.ProjectEnvFile,
+		"env_format", config.EnvFormat,
+		"direnv_command", config.DirenvCommand,
+		"hydra_version", config.hydraVersion)
+	return config, nil
+}
+
+// loadProject applies the env var, env file and Hydra version settings of
+// $PROJECT_ROOT/.lazyhydra.yaml, so each project can export its own variables to its own
+// file and target its own Hydra
+func (c *Config) loadProject(store fsys.Store) error {
+	if c.root == "" {
+		return nil
+	}
+	path := filepath.Join(c.root, ProjectFile)
+	data, err := store.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var project projectConfig
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if project.EnvVarName != "" {
+		c.EnvVarName = project.EnvVarName
+	}
+	if project.OverrideStrVarName != "" {
+		c.OverrideStrVarName = project.OverrideStrVarName
+	}
+	if project.DerivedVars != nil {
+		c.DerivedVars = project.DerivedVars
+	}
+	if project.ExportGroupVar != "" {
+		c.ExportGroupVar = project.ExportGroupVar
+	}
+	if project.ProjectEnvFile != "" {
+		c.ProjectEnvFile = project.ProjectEnvFile
+	}
+	if project.HydraVersion != "" {
+		if err := checkHydraVersion(path+": hydra_version", project.HydraVersion); err != nil {
+			return err
+		}
+		c.HydraVersion = project.HydraVersion
+	}
+	logging.Logger.Debug("loaded project config", "path", path)
+	return nil
+}
+
+// checkEnvVars reports env var names that cannot be exported or clash, and unknown
+// derived values
+func (c *Config) checkEnvVars() error {
+	names := map[string]string{}
+	check := func(name, key string) error {
+		if !envVarNamePattern.MatchString(name) {
+			return fmt.Errorf("%s: %q is not a valid environment variable name", key, name)
+		}
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("%s and %s both use %s", other, key, name)
+		}
+		names[name] = key
+		return nil
+	}
+
+	if err := check(c.EnvVarName, "env_var_name"); err != nil {
+		return err
+	}
+	if err := check(c.OverrideStrVarName, "override_str_var_name"); err != nil {
+		return err
+	}
+	if !strings.Contains(c.ExportGroupVar, groupPlaceholder) || !envVarNamePattern.MatchString(c.GroupVar("x")) {
+		return fmt.Errorf("export_group_var: %q must be a variable name containing %s", c.ExportGroupVar, groupPlaceholder)
+	}
+	if strings.HasPrefix(c.ExportGroupVar, c.EnvVarName+"_") {
+		return fmt.Errorf("export_group_var: %q must not start with %s_, which names the environments' variables", c.ExportGroupVar, c.EnvVarName)
+	}
+	for _, name := range c.DerivedVarNames() {
+		value := c.DerivedVars[name]
+		if !slices.Contains(derivedValues, value) {
+			return fmt.Errorf("derived_vars: unknown value %q for %s (want %s)", value, name, strings.Join(derivedValues, ", "))
+		}
+		if err := check(name, "derived_vars"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnvFilePath returns the env file of the project at root: project_env_file with ~/ and
+// environment variables such as $PROJECT_ROOT expanded, relative to root unless absolute
+func (c *Config) EnvFilePath(root string) string {
+	path := c.ExpandPath(c.ProjectEnvFile)
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(root, path)
+}
+
+// GroupVar returns the env var holding the override string of an export group:
+// export_group_var with {GROUP} replaced by the group in upper case, e.g. HYDRA_TRAIN_STR
+func (c *Config) GroupVar(group string) string {
+	upper := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, group)
+	return strings.ReplaceAll(c.ExportGroupVar, groupPlaceholder, upper)
+}
+
+// DerivedVarNames returns the names of the derived_vars, sorted
+func (c *Config) DerivedVarNames() []string {
+	names := make([]string, 0, len(c.DerivedVars))
+	for name := range c.DerivedVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// windowsEnvPattern matches %VAR% references in Windows paths
+var windowsEnvPattern = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_]*)%`)
+
+// ExpandPath expands a leading ~/ and environment variables such as $PROJECT_ROOT.
+// On Windows ~\ and %VAR% references such as %USERPROFILE% are expanded too.
+func ExpandPath(path string) string {
+	return ExpandRootPath(path, "")
+}
+
+// ExpandPath is the package's ExpandPath with $PROJECT_ROOT standing for the root the
+// config was loaded for
+func (c *Config) ExpandPath(path string) string {
+	return ExpandRootPath(path, c.root)
+}
+
+// ExpandRootPath is ExpandPath with $PROJECT_ROOT expanded to root rather than read from
+// the environment, unless root is empty
+func ExpandRootPath(path, root string) string {
+	lookup := func(name string) (string, bool) {
+		if name == "PROJECT_ROOT" && root != "" {
+			return root, true
+		}
+		return os.LookupEnv(name)
+	}
+	if runtime.GOOS == "windows" {
+		path = windowsEnvPattern.ReplaceAllStringFunc(path, func(ref string) string {
+			if value, ok := lookup(ref[1 : len(ref)-1]); ok {
+				return value
+			}
+			return ref
+		})
+		if strings.HasPrefix(path, `~\`) {
+			path = "~/" + path[2:]
+		}
+	}
+	if strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[2:])
+	}
+	// Expand environment variables (handles $VAR and ${VAR})
+	return os.Expand(path, func(name string) string {
+		value, _ := lookup(name)
+		return value
+	})
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandRootPath(t *testing.T) {
+	t.Setenv("PROJECT_ROOT", "/env/root")
+	t.Setenv("DATA", "/data")
+	tests := []struct {
+		name, path, root, want string
+	}{
+		{"root", "$PROJECT_ROOT/conf", "/proj", "/proj/conf"},
+		{"braced root", "${PROJECT_ROOT}/conf", "/proj", "/proj/conf"},
+		{"root from the environment", "$PROJECT_ROOT/conf", "", "/env/root/conf"},
+		{"other variable", "$DATA/overrides", "/proj", "/data/overrides"},
+		{"plain", "/abs/conf", "/proj", "/abs/conf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandRootPath(tt.path, tt.root); filepath.ToSlash(got) != tt.want {
+				t.Errorf("ExpandRootPath(%q, %q) = %s, want %s", tt.path, tt.root, got, tt.want)
+			}
+		})
+	}
+}
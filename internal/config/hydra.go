@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+)
+
+// HydraVersions are the Hydra releases hydra_version selects the override syntax of.
+// Their grammars differ in small ways: ++ arrived in 1.1, as did the oc.* resolvers of
+// OmegaConf 2.1, whose env resolver replaced ${env:...}; hydra.job.chdir in 1.2.
+var HydraVersions = []string{"1.0", "1.1", "1.2", "1.3"}
+
+// HydraVersionAuto detects the Hydra version installed in the project's environment
+const HydraVersionAuto = "auto"
+
+// checkHydraVersion reports a hydra_version that is neither auto nor a known release
+func checkHydraVersion(setting, version string) error {
+	if version == HydraVersionAuto || slices.Contains(HydraVersions, version) {
+		return nil
+	}
+	return fmt.Errorf("%s: unknown Hydra version %q (want auto or one of %s)", setting, version, strings.Join(HydraVersions, ", "))
+}
+
+// Hydra returns the Hydra version the override strings are written for, as major.minor,
+// and where it came from: hydra_version, or the environment it was detected in. The
+// version is empty when it is not set and no Hydra install was found; the strings then
+// follow the latest grammar.
+func (c *Config) Hydra() (version, source string) {
+	return c.hydraVersion, c.hydraSource
+}
+
+// HydraBefore reports whether the Hydra version in effect is older than version. An
+// unknown version counts as the latest.
+func (c *Config) HydraBefore(version string) bool {
+	return c.hydraVersion != "" && compareVersions(c.hydraVersion, version) < 0
+}
+
+// compareVersions compares dotted version numbers such as 1.3 and 1.10
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			fmt.Sscan(as[i], &x)
+		}
+		if i < len(bs) {
+			fmt.Sscan(bs[i], &y)
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+// resolveHydraVersion sets the Hydra version in effect from hydra_version, detecting
+// it in the project's Python environment when it is auto
+func (c *Config) resolveHydraVersion(store fsys.Store) {
+	c.hydraVersion, c.hydraSource = "", ""
+	if c.HydraVersion != HydraVersionAuto {
+		c.hydraVersion, c.hydraSource = c.HydraVersion, "hydra_version"
+		return
+	}
+	c.hydraVersion, c.hydraSource = detectHydraVersion(store, c.root)
+	if c.hydraVersion != "" {
+		logging.Logger.Debug("detected Hydra", "version", c.hydraVersion, "environment", c.hydraSource)
+	}
+}
+
+// detectHydraVersion looks for the hydra-core package in the active virtualenv or conda
+// environment, then in a .venv or venv in the project root, and returns its major.minor
+// version and the environment it is installed in
+func detectHydraVersion(store fsys.Store, root string) (version, env string) {
+	var envs []string
+	for _, name := range []string{"VIRTUAL_ENV", "CONDA_PREFIX"} {
+		if dir := os.Getenv(name); dir != "" {
+			envs = append(envs, dir)
+		}
+	}
+	if root != "" {
+		envs = append(envs, filepath.Join(root, ".venv"), filepath.Join(root, "venv"))
+	}
+
+	for _, env := range envs {
+		// lib/pythonX.Y/site-packages, or Lib/site-packages on Windows
+		sitePackages := []string{filepath.Join(env, "Lib", "site-packages")}
+		if entries, err := store.ReadDir(filepath.Join(env, "lib")); err == nil {
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), "python") {
+					sitePackages = append(sitePackages, filepath.Join(env, "lib", e.Name(), "site-packages"))
+				}
+			}
+		}
+		for _, dir := range sitePackages {
+			entries, err := store.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				// e.g. hydra_core-1.3.2.dist-info
+				rest, ok := strings.CutPrefix(e.Name(), "hydra_core-")
+				if !ok || !(strings.HasSuffix(rest, ".dist-info") || strings.HasSuffix(rest, ".egg-info")) {
+					continue
+				}
+				if parts := strings.SplitN(rest, ".", 3); len(parts) == 3 {
+					return parts[0] + "." + parts[1], env
+				}
+			}
+		}
+	}
+	return "", ""
+}
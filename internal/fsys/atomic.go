@@ -0,0 +1,70 @@
+package fsys
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces the file name with data so that it is never left partially
+// written: the data goes to a temporary file in the same directory, which is renamed
+// over name once complete. An existing file keeps its permissions; perm applies to a
+// new one. When name is a symlink, the file it points to is replaced.
+func WriteFileAtomic(store Store, name string, data []byte, perm fs.FileMode) error {
+	if m, ok := store.(*Mount); ok {
+		store = m.storeFor(name)
+	}
+	if info, err := store.Stat(name); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if _, ok := store.(osStore); ok {
+		return writeFileAtomicOS(name, data, perm)
+	}
+
+	// Other stores cannot resolve symlinks, so write through them in place
+	if info, err := store.Lstat(name); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+		return store.WriteFile(name, data, perm)
+	}
+	tmp := filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".tmp")
+	if err := store.WriteFile(tmp, data, perm); err != nil {
+		store.Remove(tmp)
+		return err
+	}
+	if err := store.Rename(tmp, name); err != nil {
+		store.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomicOS is WriteFileAtomic on the real file system. The temporary file is
+// synced before the rename, so a crash leaves either the old or the new content.
+func writeFileAtomicOS(name string, data []byte, perm fs.FileMode) error {
+	if target, err := filepath.EvalSymlinks(name); err == nil {
+		name = target
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // fails harmlessly once renamed
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
@@ -0,0 +1,122 @@
+// Package fsys abstracts the file system lazyhydra reads and writes: the config file,
+// the overrides directory, the env file and the symlinks in the Hydra config tree.
+// Production code uses OS, with the project directory on a Remote store mounted over it
+// for projects on another machine or in a container; tests can use an in-memory Memory
+// store instead.
+package fsys
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Store is the set of file operations lazyhydra performs. Errors follow the os package,
+// so os.IsNotExist and errors.Is(err, fs.ErrNotExist) work for every implementation.
+type Store interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// CreateFile creates name with data, failing with fs.ErrExist when it exists. The
+	// file appears with all of its data at once, so it can serve as a lock.
+	CreateFile(name string, data []byte, perm fs.FileMode) error
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Stat(name string) (fs.FileInfo, error)
+	Lstat(name string) (fs.FileInfo, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	Remove(name string) error
+	RemoveAll(path string) error
+	Rename(oldpath, newpath string) error
+	Symlink(oldname, newname string) error
+}
+
+// OS is the Store backed by the real file system
+var OS Store = osStore{}
+
+type osStore struct{}
+
+func (osStore) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+func (osStore) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+func (osStore) CreateFile(name string, data []byte, perm fs.FileMode) error {
+	// The data goes to a temporary file first, which is hard linked to name: unlike
+	// O_EXCL, the link never exposes a file that is still empty
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, perm)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Link(tmp, name); err != nil {
+		var linkErr *os.LinkError
+		if errors.As(err, &linkErr) {
+			return &fs.PathError{Op: "open", Path: name, Err: linkErr.Err}
+		}
+		return err
+	}
+	return nil
+}
+func (osStore) ReadDir(name string) ([]fs.DirEntry, error)   { return os.ReadDir(name) }
+func (osStore) Stat(name string) (fs.FileInfo, error)        { return os.Stat(name) }
+func (osStore) Lstat(name string) (fs.FileInfo, error)       { return os.Lstat(name) }
+func (osStore) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+func (osStore) Remove(name string) error                     { return os.Remove(name) }
+func (osStore) RemoveAll(path string) error                  { return os.RemoveAll(path) }
+func (osStore) Rename(oldpath, newpath string) error         { return os.Rename(oldpath, newpath) }
+func (osStore) Symlink(oldname, newname string) error        { return os.Symlink(oldname, newname) }
+
+// WalkDir walks the tree rooted at root like filepath.WalkDir, reading it from store
+func WalkDir(store Store, root string, fn fs.WalkDirFunc) error {
+	info, err := store.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(store, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func walkDir(store Store, path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, fs.SkipDir) && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := store.ReadDir(path)
+	if err != nil {
+		// Let fn decide whether a directory that cannot be read stops the walk
+		if err = fn(path, d, err); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		if err := walkDir(store, filepath.Join(path, entry.Name()), entry, fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
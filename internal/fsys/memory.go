@@ -0,0 +1,327 @@
+package fsys
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// maxSymlinks bounds how many symlinks are followed when resolving a path
+const maxSymlinks = 40
+
+// Memory is a Store that keeps everything in memory, for tests of the code that loads
+// and saves overrides and state. The root directory always exists; everything else has
+// to be created, as on a real disk.
+type Memory struct {
+	mu    sync.Mutex
+	files map[string]*memFile
+}
+
+// memFile is a file, directory or symlink in a Memory store
+type memFile struct {
+	data    []byte
+	mode    fs.FileMode // type bits and permissions
+	modTime time.Time
+	target  string // symlink target
+}
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{files: make(map[string]*memFile)}
+}
+
+func pathError(op, path string, err error) error {
+	return &fs.PathError{Op: op, Path: path, Err: err}
+}
+
+// isRoot reports whether path is the root of the tree, which always exists
+func isRoot(path string) bool {
+	return filepath.Dir(path) == path
+}
+
+// lookup returns the entry at a cleaned path without following symlinks
+func (m *Memory) lookup(path string) (*memFile, bool) {
+	if isRoot(path) {
+		return &memFile{mode: fs.ModeDir | 0755}, true
+	}
+	f, ok := m.files[path]
+	return f, ok
+}
+
+// resolve follows symlinks until it reaches an entry that is not one. It returns the
+// resolved path and entry, or fs.ErrNotExist when the chain ends nowhere.
+func (m *Memory) resolve(path string) (string, *memFile, error) {
+	for i := 0; i < maxSymlinks; i++ {
+		f, ok := m.lookup(path)
+		if !ok {
+			return path, nil, fs.ErrNotExist
+		}
+		if f.mode&fs.ModeSymlink == 0 {
+			return path, f, nil
+		}
+		target := f.target
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = filepath.Clean(target)
+	}
+	return path, nil, syscall.ELOOP
+}
+
+// checkParent returns an error unless the directory that would hold path exists
+func (m *Memory) checkParent(op, path string) error {
+	_, parent, err := m.resolve(filepath.Dir(path))
+	if err != nil {
+		return pathError(op, path, err)
+	}
+	if !parent.mode.IsDir() {
+		return pathError(op, path, syscall.ENOTDIR)
+	}
+	return nil
+}
+
+// hasChildren reports whether anything lives below the directory at path
+func (m *Memory) hasChildren(path string) bool {
+	prefix := path + string(filepath.Separator)
+	for p := range m.files {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Memory) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, f, err := m.resolve(filepath.Clean(name))
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if f.mode.IsDir() {
+		return nil, pathError("read", name, syscall.EISDIR)
+	}
+	return append([]byte(nil), f.data...), nil
+}
+
+func (m *Memory) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path, f, err := m.resolve(filepath.Clean(name))
+	if err == nil {
+		if f.mode.IsDir() {
+			return pathError("open", name, syscall.EISDIR)
+		}
+		f.data = append([]byte(nil), data...)
+		f.modTime = time.Now()
+		return nil
+	}
+	if err := m.checkParent("open", path); err != nil {
+		return err
+	}
+	m.files[path] = &memFile{data: append([]byte(nil), data...), mode: perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (m *Memory) CreateFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := filepath.Clean(name)
+	if _, ok := m.lookup(path); ok {
+		return pathError("open", name, fs.ErrExist)
+	}
+	if err := m.checkParent("open", path); err != nil {
+		return err
+	}
+	m.files[path] = &memFile{data: append([]byte(nil), data...), mode: perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (m *Memory) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path, f, err := m.resolve(filepath.Clean(name))
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if !f.mode.IsDir() {
+		return nil, pathError("readdirent", name, syscall.ENOTDIR)
+	}
+
+	var entries []fs.DirEntry
+	for p, child := range m.files {
+		if filepath.Dir(p) == path && p != path {
+			entries = append(entries, fs.FileInfoToDirEntry(child.info(filepath.Base(p))))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m *Memory) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := filepath.Clean(name)
+	_, f, err := m.resolve(path)
+	if err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	return f.info(filepath.Base(path)), nil
+}
+
+func (m *Memory) Lstat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := filepath.Clean(name)
+	f, ok := m.lookup(path)
+	if !ok {
+		return nil, pathError("lstat", name, fs.ErrNotExist)
+	}
+	return f.info(filepath.Base(path)), nil
+}
+
+func (m *Memory) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Collect the missing directories up to the first one that exists
+	var missing []string
+	for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+		if _, f, err := m.resolve(p); err == nil {
+			if !f.mode.IsDir() {
+				return pathError("mkdir", p, syscall.ENOTDIR)
+			}
+			break
+		}
+		missing = append(missing, p)
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		m.files[missing[i]] = &memFile{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	}
+	return nil
+}
+
+func (m *Memory) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := filepath.Clean(name)
+	f, ok := m.files[path]
+	if !ok {
+		return pathError("remove", name, fs.ErrNotExist)
+	}
+	if f.mode.IsDir() && m.hasChildren(path) {
+		return pathError("remove", name, syscall.ENOTEMPTY)
+	}
+	delete(m.files, path)
+	return nil
+}
+
+func (m *Memory) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	prefix := path + string(filepath.Separator)
+	for p := range m.files {
+		if p == path || strings.HasPrefix(p, prefix) {
+			delete(m.files, p)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	f, ok := m.files[oldpath]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	if err := m.checkParent("rename", newpath); err != nil {
+		return err
+	}
+	if existing, ok := m.files[newpath]; ok && existing.mode.IsDir() {
+		if !f.mode.IsDir() {
+			return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EISDIR}
+		}
+		if m.hasChildren(newpath) {
+			return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.ENOTEMPTY}
+		}
+	}
+
+	moved := map[string]*memFile{newpath: f}
+	prefix := oldpath + string(filepath.Separator)
+	for p, child := range m.files {
+		if strings.HasPrefix(p, prefix) {
+			moved[newpath+string(filepath.Separator)+strings.TrimPrefix(p, prefix)] = child
+			delete(m.files, p)
+		}
+	}
+	delete(m.files, oldpath)
+	for p, child := range moved {
+		m.files[p] = child
+	}
+	return nil
+}
+
+func (m *Memory) Symlink(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := filepath.Clean(newname)
+	if _, ok := m.lookup(path); ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+	if err := m.checkParent("symlink", path); err != nil {
+		return err
+	}
+	m.files[path] = &memFile{mode: fs.ModeSymlink | 0777, target: oldname, modTime: time.Now()}
+	return nil
+}
+
+// Readlink returns the target of a symlink, so tests can check where overrides point
+func (m *Memory) Readlink(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.files[filepath.Clean(name)]
+	if !ok {
+		return "", pathError("readlink", name, fs.ErrNotExist)
+	}
+	if f.mode&fs.ModeSymlink == 0 {
+		return "", pathError("readlink", name, errors.New("not a symlink"))
+	}
+	return f.target, nil
+}
+
+// memInfo is the fs.FileInfo of a memFile
+type memInfo struct {
+	name string
+	file *memFile
+}
+
+func (f *memFile) info(name string) fs.FileInfo {
+	return memInfo{name: name, file: f}
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return int64(len(i.file.data)) }
+func (i memInfo) Mode() fs.FileMode  { return i.file.mode }
+func (i memInfo) ModTime() time.Time { return i.file.modTime }
+func (i memInfo) IsDir() bool        { return i.file.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
@@ -0,0 +1,62 @@
+package fsys
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Mount is a Store that serves the paths inside one directory from another store and
+// all others from a base store, e.g. a project on another machine while the config and
+// state stay on this one. Renaming between the two fails with EXDEV, like renaming
+// across file systems, so moves fall back to copying.
+type Mount struct {
+	base  Store
+	dir   string
+	store Store
+}
+
+// NewMount returns a Store that reads and writes the paths inside dir through store and
+// everything else through base
+func NewMount(base Store, dir string, store Store) *Mount {
+	return &Mount{base: base, dir: filepath.Clean(dir), store: store}
+}
+
+// storeFor returns the store that holds name
+func (m *Mount) storeFor(name string) Store {
+	rel, err := filepath.Rel(m.dir, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return m.base
+	}
+	return m.store
+}
+
+func (m *Mount) ReadFile(name string) ([]byte, error) { return m.storeFor(name).ReadFile(name) }
+func (m *Mount) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return m.storeFor(name).WriteFile(name, data, perm)
+}
+func (m *Mount) CreateFile(name string, data []byte, perm fs.FileMode) error {
+	return m.storeFor(name).CreateFile(name, data, perm)
+}
+func (m *Mount) ReadDir(name string) ([]fs.DirEntry, error) { return m.storeFor(name).ReadDir(name) }
+func (m *Mount) Stat(name string) (fs.FileInfo, error)      { return m.storeFor(name).Stat(name) }
+func (m *Mount) Lstat(name string) (fs.FileInfo, error)     { return m.storeFor(name).Lstat(name) }
+func (m *Mount) MkdirAll(path string, perm fs.FileMode) error {
+	return m.storeFor(path).MkdirAll(path, perm)
+}
+func (m *Mount) Remove(name string) error    { return m.storeFor(name).Remove(name) }
+func (m *Mount) RemoveAll(path string) error { return m.storeFor(path).RemoveAll(path) }
+
+func (m *Mount) Rename(oldpath, newpath string) error {
+	store := m.storeFor(oldpath)
+	if store != m.storeFor(newpath) {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	return store.Rename(oldpath, newpath)
+}
+
+func (m *Mount) Symlink(oldname, newname string) error {
+	return m.storeFor(newname).Symlink(oldname, newname)
+}
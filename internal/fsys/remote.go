@@ -0,0 +1,279 @@
+package fsys
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramy/lazyhydra/internal/logging"
+)
+
+// Exit statuses the scripts of a Remote store use to report a missing or existing file
+const (
+	remoteNotExist = 3
+	remoteExist    = 4
+)
+
+// remoteInfoFormat is the find -printf format of the file information Remote reads:
+// type, permissions, size, modification time and name, NUL terminated
+const remoteInfoFormat = `%y %m %s %T@ %f\0`
+
+// Remote is a Store on another machine or in a container, reached through a command
+// that runs shell scripts there: every operation runs a short script. The other side
+// needs a POSIX shell with GNU coreutils and findutils, as any Linux system has.
+type Remote struct {
+	Name    string // what the files are on, for messages, e.g. user@host
+	command func(script string, terminal bool) *exec.Cmd
+}
+
+// NewSSH returns a Store for the files on host, reached with ssh. The commands share
+// one master connection, so only the first pays for the handshake and asks for a
+// password.
+func NewSSH(host string) *Remote {
+	controlPath := filepath.Join(os.TempDir(), "lazyhydra-ssh-%C")
+	return &Remote{Name: host, command: func(script string, terminal bool) *exec.Cmd {
+		args := []string{
+			"-o", "ControlMaster=auto",
+			"-o", "ControlPath=" + controlPath,
+			"-o", "ControlPersist=60",
+		}
+		if terminal {
+			args = append(args, "-t")
+		}
+		args = append(args, "--", host, script)
+		return exec.Command("ssh", args...)
+	}}
+}
+
+// NewDocker returns a Store for the files in a running container, reached with docker
+// exec
+func NewDocker(container string) *Remote {
+	return &Remote{Name: "container " + container, command: func(script string, terminal bool) *exec.Cmd {
+		args := []string{"exec", "-i"}
+		if terminal {
+			args = append(args, "-t")
+		}
+		args = append(args, container, "sh", "-c", script)
+		return exec.Command("docker", args...)
+	}}
+}
+
+// Command returns a command that runs script in a shell on the other side. With
+// terminal, it gets a terminal, for interactive programs.
+func (s *Remote) Command(script string, terminal bool) *exec.Cmd {
+	return s.command(script, terminal)
+}
+
+// quote single-quotes s for the shell on the other side
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// run runs script on the other side with stdin as its input and returns what it
+// printed. The remoteNotExist and remoteExist statuses become fs.ErrNotExist and
+// fs.ErrExist.
+func (s *Remote) run(script string, stdin []byte) ([]byte, error) {
+	cmd := s.Command(script, false)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err := cmd.Run()
+	logging.Logger.Debug("remote file operation", "on", s.Name, "script", script, "duration", time.Since(start), "error", err)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return stdout.Bytes(), nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == remoteNotExist:
+		return nil, fs.ErrNotExist
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == remoteExist:
+		return nil, fs.ErrExist
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		err = fmt.Errorf("%w: %s", err, msg)
+	}
+	return nil, fmt.Errorf("%s: %w", s.Name, err)
+}
+
+// exists is a script line that exits with remoteNotExist unless name exists, or with
+// lstat unless name exists as a symlink either
+func exists(name string, lstat bool) string {
+	if lstat {
+		return fmt.Sprintf("test -e %[1]s || test -L %[1]s || exit %[2]d\n", quote(name), remoteNotExist)
+	}
+	return fmt.Sprintf("test -e %s || exit %d\n", quote(name), remoteNotExist)
+}
+
+func (s *Remote) ReadFile(name string) ([]byte, error) {
+	data, err := s.run(exists(name, false)+"cat -- "+quote(name), nil)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	return data, nil
+}
+
+func (s *Remote) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	// Like os.WriteFile, perm only applies to a new file
+	script := exists(path.Dir(name), false) + fmt.Sprintf("test -e %[1]s; new=$?\ncat > %[1]s || exit 1\n"+
+		"[ $new = 0 ] || chmod %[2]o %[1]s", quote(name), perm.Perm())
+	if _, err := s.run(script, data); err != nil {
+		return pathError("open", name, err)
+	}
+	return nil
+}
+
+func (s *Remote) CreateFile(name string, data []byte, perm fs.FileMode) error {
+	// Written to a temporary file and hard linked, as ln fails when name exists
+	script := exists(path.Dir(name), false) + fmt.Sprintf("tmp=%[1]s.tmp-$$\ncat > \"$tmp\" && chmod %[2]o \"$tmp\" || { rm -f -- \"$tmp\"; exit 1; }\n"+
+		"ln -- \"$tmp\" %[1]s; status=$?\nrm -f -- \"$tmp\"\n[ $status = 0 ] && exit 0\ntest -e %[1]s && exit %[3]d\nexit 1", quote(name), perm.Perm(), remoteExist)
+	if _, err := s.run(script, data); err != nil {
+		return pathError("open", name, err)
+	}
+	return nil
+}
+
+func (s *Remote) ReadDir(name string) ([]fs.DirEntry, error) {
+	// The trailing slash lists the directory a symlink points to, like os.ReadDir
+	script := exists(name, false) + fmt.Sprintf("find %s/ -mindepth 1 -maxdepth 1 -printf '%s'", quote(name), remoteInfoFormat)
+	output, err := s.run(script, nil)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	infos, err := parseRemoteInfos(output)
+	if err != nil {
+		return nil, pathError("readdirent", name, err)
+	}
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (s *Remote) Stat(name string) (fs.FileInfo, error) {
+	return s.stat("stat", exists(name, false)+fmt.Sprintf("find -L %s -maxdepth 0 -printf '%s'", quote(name), remoteInfoFormat), name)
+}
+
+func (s *Remote) Lstat(name string) (fs.FileInfo, error) {
+	return s.stat("lstat", exists(name, true)+fmt.Sprintf("find %s -maxdepth 0 -printf '%s'", quote(name), remoteInfoFormat), name)
+}
+
+func (s *Remote) stat(op, script, name string) (fs.FileInfo, error) {
+	output, err := s.run(script, nil)
+	if err != nil {
+		return nil, pathError(op, name, err)
+	}
+	infos, err := parseRemoteInfos(output)
+	if err == nil && len(infos) != 1 {
+		err = fmt.Errorf("unexpected find output %q", output)
+	}
+	if err != nil {
+		return nil, pathError(op, name, err)
+	}
+	return infos[0], nil
+}
+
+func (s *Remote) MkdirAll(name string, perm fs.FileMode) error {
+	if _, err := s.run("mkdir -p -- "+quote(name), nil); err != nil {
+		return pathError("mkdir", name, err)
+	}
+	return nil
+}
+
+func (s *Remote) Remove(name string) error {
+	script := exists(name, true) + fmt.Sprintf("if test -d %[1]s && ! test -L %[1]s; then rmdir -- %[1]s; else rm -f -- %[1]s; fi", quote(name))
+	if _, err := s.run(script, nil); err != nil {
+		return pathError("remove", name, err)
+	}
+	return nil
+}
+
+func (s *Remote) RemoveAll(name string) error {
+	if _, err := s.run("rm -rf -- "+quote(name), nil); err != nil {
+		return pathError("unlinkat", name, err)
+	}
+	return nil
+}
+
+func (s *Remote) Rename(oldpath, newpath string) error {
+	if _, err := s.run(exists(oldpath, true)+fmt.Sprintf("mv -fT -- %s %s", quote(oldpath), quote(newpath)), nil); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	return nil
+}
+
+func (s *Remote) Symlink(oldname, newname string) error {
+	script := fmt.Sprintf("test -e %[1]s || test -L %[1]s && exit %[2]d\nln -s -- %[3]s %[1]s", quote(newname), remoteExist, quote(oldname))
+	if _, err := s.run(script, nil); err != nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: err}
+	}
+	return nil
+}
+
+// remoteInfo is the fs.FileInfo of a file on a Remote store
+type remoteInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i remoteInfo) Name() string       { return i.name }
+func (i remoteInfo) Size() int64        { return i.size }
+func (i remoteInfo) Mode() fs.FileMode  { return i.mode }
+func (i remoteInfo) ModTime() time.Time { return i.modTime }
+func (i remoteInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i remoteInfo) Sys() any           { return nil }
+
+// remoteFileTypes maps the file types find prints to their mode bits
+var remoteFileTypes = map[string]fs.FileMode{
+	"f": 0,
+	"d": fs.ModeDir,
+	"l": fs.ModeSymlink,
+	"p": fs.ModeNamedPipe,
+	"s": fs.ModeSocket,
+	"c": fs.ModeDevice | fs.ModeCharDevice,
+	"b": fs.ModeDevice,
+}
+
+// parseRemoteInfos parses the records find prints with remoteInfoFormat
+func parseRemoteInfos(output []byte) ([]fs.FileInfo, error) {
+	var infos []fs.FileInfo
+	for _, record := range strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00") {
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, " ", 5)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unexpected find output %q", record)
+		}
+		fileType, ok := remoteFileTypes[fields[0]]
+		perm, err := strconv.ParseUint(fields[1], 8, 32)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("unexpected find output %q", record)
+		}
+		size, _ := strconv.ParseInt(fields[2], 10, 64)
+		seconds, _ := strconv.ParseFloat(fields[3], 64)
+		infos = append(infos, remoteInfo{
+			name:    fields[4],
+			size:    size,
+			mode:    fileType | fs.FileMode(perm)&fs.ModePerm,
+			modTime: time.Unix(0, int64(seconds*float64(time.Second))),
+		})
+	}
+	return infos, nil
+}
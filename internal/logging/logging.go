@@ -0,0 +1,11 @@
+// Package logging holds the debug logger shared by lazyhydra's packages.
+package logging
+
+import (
+	"io"
+	"log/slog"
+)
+
+// Logger receives debug logs. It discards everything unless debug logging is enabled
+// with --debug or LAZYHYDRA_LOG=debug.
+var Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
@@ -0,0 +1,138 @@
+package override
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Args returns the Hydra CLI arguments for the override. Config group overrides are
+// rendered with the override_format of cfg.
+func (o *Override) Args(cfg *config.Config) []string {
+	if o.IsComposite() {
+		// Composites contribute through the overrides they include
+		return nil
+	}
+	if o.Block == "" {
+		// Value override: flatten override.yaml into key=value pairs
+		// e.g., ++episodes=3 ++model.hidden_size=256, in the syntax of cfg's Hydra version.
+		// Keys are relative to the override's package.
+		flat := FlattenYAML(o.RenderedContent())
+		prefix := valuePrefix(o, cfg) + o.keyPrefix()
+		var args []string
+		for _, kv := range flat {
+			args = append(args, fmt.Sprintf("%s%s=%s", prefix, kv[0], hydraValue(kv[1], cfg)))
+		}
+		return args
+	}
+	// Config group override rendered with override_format, by default
+	// [type][block_as_path][@package]=[name]_override, e.g., +experiment/config/logging=detailed_logging_override
+	data := config.OverrideStringData{
+		Type:       o.Type,
+		Block:      o.Block,
+		BlockPath:  strings.ReplaceAll(o.Block, ".", "/"),
+		ModulePath: o.ModulePath,
+		Module:     o.Module,
+		File:       o.File,
+		Package:    o.Package,
+		Name:       o.Name,
+	}
+	return []string{cfg.FormatOverride(data)}
+}
+
+// FlattenYAML parses YAML content and returns a sorted list of [key, value] pairs
+// with nested keys joined by dots. E.g., {model: {hidden_size: 256}} -> [["model.hidden_size", "256"]]
+// Values are in Hydra override syntax, quoted where needed (see FormatHydraValue).
+func FlattenYAML(content string) [][2]string {
+	var data map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content), &data); err != nil {
+		return nil
+	}
+
+	var result [][2]string
+	flattenMap("", data, &result)
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
+
+func flattenMap(prefix string, m map[string]interface{}, result *[][2]string) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			flattenMap(key, val, result)
+		default:
+			*result = append(*result, [2]string{key, FormatHydraValue(val)})
+		}
+	}
+}
+
+// hydraSpecialChars are characters that end or change the meaning of an unquoted value
+// in Hydra's override grammar (sweeps, lists, dicts, interpolations, quotes, escapes).
+const hydraSpecialChars = " \t,'\"\\[]{}()=:$*?!#|&;<>`~"
+
+// Number patterns of Hydra's override grammar, which allows _ between digits
+const (
+	hydraDigits     = `[0-9](_?[0-9])*`
+	hydraInt        = `[+-]?(0|[1-9](_?[0-9])*)`
+	hydraPointFloat = `(` + hydraDigits + `)?\.` + hydraDigits + `|` + hydraDigits + `\.`
+	hydraFloat      = `[+-]?((` + hydraPointFloat + `)|(` + hydraDigits + `|` + hydraPointFloat + `)e[+-]?` + hydraDigits + `|inf|nan)`
+)
+
+// hydraTypedPattern matches unquoted values Hydra parses as an int, float, bool or null
+// rather than a string, e.g. 123, 1e-3, inf, True and null
+var hydraTypedPattern = regexp.MustCompile(`^(?i:` + hydraInt + `|` + hydraFloat + `|true|false|null)$`)
+
+// HydraQuote returns a string value ready for a Hydra override, single-quoting it when
+// it contains whitespace or grammar characters, or would be read back as another type.
+// Quotes inside are backslash-escaped.
+func HydraQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, hydraSpecialChars) && !hydraTypedPattern.MatchString(s) {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// FormatHydraValue renders a parsed YAML value in Hydra override syntax: strings are
+// quoted as needed, lists become [a,b] and maps {k:v}.
+func FormatHydraValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return HydraQuote(val)
+	case []interface{}:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = FormatHydraValue(item)
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = HydraQuote(k) + ":" + FormatHydraValue(val[k])
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
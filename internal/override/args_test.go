@@ -0,0 +1,95 @@
+package override
+
+import "testing"
+
+func TestHydraQuote(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "adam", "adam"},
+		{"path", "/a/b.c", "/a/b.c"},
+		{"empty", "", "''"},
+		{"space", "hello world", "'hello world'"},
+		{"tab", "a\tb", "'a\tb'"},
+		{"dollar", "$HOME", "'$HOME'"},
+		{"interpolation", "${oc.env:HOME}", "'${oc.env:HOME}'"},
+		{"single quote", "it's", `'it\'s'`},
+		{"double quote", `say "hi"`, `'say "hi"'`},
+		{"backslash", `C:\tmp`, `'C:\\tmp'`},
+		{"backslash and quote", `a\'b`, `'a\\\'b'`},
+		{"comma", "a,b", "'a,b'"},
+		{"brackets", "[1]", "'[1]'"},
+		{"equals", "k=v", "'k=v'"},
+		{"int", "123", "'123'"},
+		{"signed int", "-7", "'-7'"},
+		{"int with underscores", "1_000", "'1_000'"},
+		{"float", "0.5", "'0.5'"},
+		{"leading point float", ".5", "'.5'"},
+		{"exponent float", "1e-3", "'1e-3'"},
+		{"inf", "-inf", "'-inf'"},
+		{"nan", "NaN", "'NaN'"},
+		{"true", "true", "'true'"},
+		{"mixed case bool", "False", "'False'"},
+		{"null", "null", "'null'"},
+		{"leading zero", "0123", "0123"},
+		{"version", "1.2.3", "1.2.3"},
+		{"word containing null", "nullable", "nullable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HydraQuote(tt.in); got != tt.want {
+				t.Errorf("HydraQuote(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatHydraValue(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+		want string
+	}{
+		{"nil", nil, "null"},
+		{"int", 3, "3"},
+		{"float", 0.25, "0.25"},
+		{"bool", true, "true"},
+		{"string", "adam", "adam"},
+		{"numeric string", "42", "'42'"},
+		{"string with space", "two words", "'two words'"},
+		{"empty string", "", "''"},
+		{"list", []interface{}{1, "two words", 3}, "[1,'two words',3]"},
+		{"empty list", []interface{}{}, "[]"},
+		{"nested list", []interface{}{[]interface{}{"a", "b"}, "c,d"}, "[[a,b],'c,d']"},
+		{"map", map[string]interface{}{"b": 2, "a": "x y"}, "{a:'x y',b:2}"},
+		{"map with quoted key", map[string]interface{}{"a b": "$x"}, "{'a b':'$x'}"},
+		{"map with list", map[string]interface{}{"l": []interface{}{"true", false}}, "{l:['true',false]}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatHydraValue(tt.in); got != tt.want {
+				t.Errorf("FormatHydraValue(%#v) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenYAML(t *testing.T) {
+	content := "name: hello world\nmodel:\n  depth: 50\n  act: relu\nlst: [1, two, \"3\"]\nempty: \"\"\n"
+	want := [][2]string{
+		{"empty", "''"},
+		{"lst", "[1,two,'3']"},
+		{"model.act", "relu"},
+		{"model.depth", "50"},
+		{"name", "'hello world'"},
+	}
+	got := FlattenYAML(content)
+	if len(got) != len(want) {
+		t.Fatalf("FlattenYAML() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("FlattenYAML()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
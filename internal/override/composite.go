@@ -0,0 +1,79 @@
+package override
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsComposite reports whether an override bundles other overrides via includes
+func (o *Override) IsComposite() bool {
+	return len(o.Includes) > 0
+}
+
+// ResolveIncludes links every composite override to the overrides it includes. Names
+// that do not exist or refer to other composites are recorded so lint can report them.
+func ResolveIncludes(overrides []*Override) {
+	byName := make(map[string]*Override)
+	for _, o := range overrides {
+		byName[o.Name] = o
+	}
+
+	for _, o := range overrides {
+		o.members = nil
+		o.badIncludes = nil
+		for _, name := range o.Includes {
+			member, ok := byName[name]
+			switch {
+			case !ok:
+				o.badIncludes = append(o.badIncludes, fmt.Sprintf("included override %q does not exist", name))
+			case member.IsComposite():
+				o.badIncludes = append(o.badIncludes, fmt.Sprintf("included override %q is itself composite; nesting is not supported", name))
+			default:
+				o.members = append(o.members, member)
+			}
+		}
+	}
+}
+
+// CompositeProblems returns the lint problems of a composite override. A composite is
+// only complete when every override it includes can be applied.
+func (o *Override) CompositeProblems() []string {
+	problems := append([]string(nil), o.badIncludes...)
+	for _, m := range o.members {
+		if m.IsIncomplete() {
+			problems = append(problems, fmt.Sprintf("included override %q is incomplete", m.Name))
+		}
+	}
+	return problems
+}
+
+// Members returns the overrides a composite includes, as resolved by ResolveIncludes
+func (o *Override) Members() []*Override {
+	return o.members
+}
+
+// BadIncludes returns the problems found resolving a composite's includes
+func (o *Override) BadIncludes() []string {
+	return o.badIncludes
+}
+
+// RenameInclude returns apply.md content with oldName replaced by newName in the
+// includes list of the frontmatter, preserving the body and any other keys.
+func RenameInclude(content, oldName, newName string) (string, error) {
+	if _, _, ok := SplitFrontmatter(content); !ok {
+		return content, nil
+	}
+	return editFrontmatter(content, func(root *yaml.Node) {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "includes" {
+				continue
+			}
+			for _, item := range root.Content[i+1].Content {
+				if item.Kind == yaml.ScalarNode && item.Value == oldName {
+					item.Value = newName
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package override
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+)
+
+// Folder is a folder found while scanning the overrides directory
+type Folder struct {
+	Name     string // folder name, used as the override name
+	Dir      string // parent folder relative to the overrides directory, "" at the top level
+	Path     string // full path to the folder
+	HasApply bool   // whether the folder contains an apply.md
+}
+
+// ScanFolders walks the overrides directory in store. A folder containing apply.md is an
+// override; a folder without one that has subfolders is a group and is searched for
+// nested overrides (e.g. overrides/logging/wandb_off). Leaf folders without apply.md
+// are returned with HasApply false so callers can report them.
+func ScanFolders(store fsys.Store, root string) ([]Folder, error) {
+	var folders []Folder
+
+	var scan func(rel string) error
+	scan = func(rel string) error {
+		entries, err := store.ReadDir(filepath.Join(root, rel))
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			childRel := filepath.Join(rel, entry.Name())
+			path := filepath.Join(root, childRel)
+			folder := Folder{Name: entry.Name(), Dir: filepath.ToSlash(rel), Path: path}
+
+			if _, err := store.Stat(filepath.Join(path, "apply.md")); err == nil {
+				folder.HasApply = true
+				folders = append(folders, folder)
+				continue
+			}
+
+			if hasSubfolders(store, path) {
+				if err := scan(childRel); err != nil {
+					return err
+				}
+				continue
+			}
+			folders = append(folders, folder)
+		}
+		return nil
+	}
+
+	if err := scan(""); err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+func hasSubfolders(store fsys.Store, path string) bool {
+	entries, err := store.ReadDir(path)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ModTime returns the latest modification time of an override folder and its files
+func ModTime(store fsys.Store, path string) time.Time {
+	var latest time.Time
+	for _, p := range []string{path, filepath.Join(path, "apply.md"), filepath.Join(path, "override.yaml")} {
+		if info, err := store.Stat(p); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
@@ -0,0 +1,81 @@
+package override
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+)
+
+// HistoryDir is the folder inside an override folder that keeps earlier versions of its
+// apply.md and override.yaml. Like every hidden folder it is never loaded as an override.
+const HistoryDir = ".history"
+
+// MaxVersions is how many earlier versions of each file are kept
+const MaxVersions = 20
+
+// versionTimeFormat prefixes the version files, so they sort by the time they were replaced
+const versionTimeFormat = "20060102-150405.000"
+
+// Version is an earlier content of one of an override's files
+type Version struct {
+	File string    // apply.md or override.yaml
+	Time time.Time // when this content was replaced
+	Path string
+}
+
+// SaveVersion records previous as an earlier version of file in the override folder,
+// unless it is the same as the latest version kept. The oldest versions beyond
+// MaxVersions are removed.
+func SaveVersion(store fsys.Store, folder, file string, previous []byte) error {
+	versions := Versions(store, folder, file)
+	if len(versions) > 0 {
+		if latest, err := store.ReadFile(versions[0].Path); err == nil && bytes.Equal(latest, previous) {
+			return nil
+		}
+	}
+
+	dir := filepath.Join(folder, HistoryDir)
+	if err := store.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := time.Now().Format(versionTimeFormat) + "_" + file
+	if err := store.WriteFile(filepath.Join(dir, name), previous, 0644); err != nil {
+		return err
+	}
+
+	for i := MaxVersions - 1; i < len(versions); i++ {
+		store.Remove(versions[i].Path)
+	}
+	return nil
+}
+
+// Versions returns the earlier versions kept for file in the override folder, newest
+// first, or the versions of every file when file is empty
+func Versions(store fsys.Store, folder, file string) []Version {
+	dir := filepath.Join(folder, HistoryDir)
+	entries, err := store.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var versions []Version
+	for _, entry := range entries {
+		stamp, name, ok := strings.Cut(entry.Name(), "_")
+		if !ok || entry.IsDir() || (file != "" && name != file) {
+			continue
+		}
+		t, err := time.ParseInLocation(versionTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, Version{File: name, Time: t, Path: filepath.Join(dir, entry.Name())})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Time.After(versions[j].Time)
+	})
+	return versions
+}
@@ -0,0 +1,64 @@
+package override
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+)
+
+// ocResolverPattern matches the OmegaConf 2.1 resolvers Hydra 1.1 introduced, e.g. ${oc.env:HOME}
+var ocResolverPattern = regexp.MustCompile(`\$\{oc\.(\w+):`)
+
+// valuePrefix returns the prefix of the key=value arguments of a value override. A
+// delete (--) is written as Hydra's ~key=value once the Hydra version is known.
+func valuePrefix(o *Override, cfg *config.Config) string {
+	if version, _ := cfg.Hydra(); o.Type == "--" && version != "" {
+		return "~"
+	}
+	return o.Type
+}
+
+// hydraValue adapts a value to the Hydra version in effect: the environment resolver is
+// ${env:...} before Hydra 1.1 and ${oc.env:...} since, the old name being removed in 1.2.
+// Values are left as written while the version is unknown.
+func hydraValue(value string, cfg *config.Config) string {
+	if version, _ := cfg.Hydra(); version == "" {
+		return value
+	}
+	if cfg.HydraBefore("1.1") {
+		return strings.ReplaceAll(value, "${oc.env:", "${env:")
+	}
+	return strings.ReplaceAll(value, "${env:", "${oc.env:")
+}
+
+// HydraProblems returns what the override uses that the Hydra version in effect does
+// not support. Config group overrides are written the same for every version.
+func (o *Override) HydraProblems(cfg *config.Config) []string {
+	version, _ := cfg.Hydra()
+	if version == "" || o.IsComposite() || o.Block != "" {
+		return nil
+	}
+
+	var problems []string
+	if o.Type == "++" && cfg.HydraBefore("1.1") {
+		problems = append(problems, fmt.Sprintf("type ++ needs Hydra 1.1 or later (Hydra %s is in use)", version))
+	}
+	seen := make(map[string]bool)
+	for _, kv := range FlattenYAML(o.RenderedContent()) {
+		if kv[0] == "hydra.job.chdir" && cfg.HydraBefore("1.2") {
+			problems = append(problems, fmt.Sprintf("hydra.job.chdir needs Hydra 1.2 or later (Hydra %s is in use)", version))
+		}
+		if !cfg.HydraBefore("1.1") {
+			continue
+		}
+		for _, m := range ocResolverPattern.FindAllStringSubmatch(kv[1], -1) {
+			if m[1] != "env" && !seen[m[1]] {
+				seen[m[1]] = true
+				problems = append(problems, fmt.Sprintf("resolver oc.%s in %s needs Hydra 1.1 or later (Hydra %s is in use)", m[1], kv[0], version))
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,57 @@
+package override
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LintProblems returns the metadata problems that keep an override from producing a
+// working override string. Overrides with problems are incomplete and cannot be applied.
+func (o *Override) LintProblems() []string {
+	frontmatter, _, ok := SplitFrontmatter(o.ApplyInfo)
+	if !ok {
+		return []string{"apply.md has no --- frontmatter block"}
+	}
+	var meta Meta
+	if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+		return []string{fmt.Sprintf("invalid frontmatter: %v", err)}
+	}
+
+	if o.IsComposite() {
+		return o.CompositeProblems()
+	}
+
+	var problems []string
+	switch o.Type {
+	case "":
+		problems = append(problems, "type is empty")
+	case "+", "=":
+		if o.Block == "" {
+			problems = append(problems, fmt.Sprintf("block is empty; %q overrides need a config group block (use ++ or -- for value overrides)", o.Type))
+		}
+	case "++", "--":
+	default:
+		problems = append(problems, fmt.Sprintf("type %q is not one of %s", o.Type, strings.Join(Types, ", ")))
+	}
+
+	if o.Block == "" && (o.Package == "_group_" || o.Package == "_here_") {
+		problems = append(problems, fmt.Sprintf("package %s needs a config group block; value overrides take %s or a key path", o.Package, PackageGlobal))
+	}
+
+	if o.Block == "" && (o.Type == "++" || o.Type == "--") {
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(o.RenderedContent()), &parsed); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid override.yaml: %v", err))
+		} else if len(FlattenYAML(o.RenderedContent())) == 0 {
+			problems = append(problems, "override.yaml has no values")
+		}
+	}
+	return problems
+}
+
+// IsIncomplete reports whether an override has metadata problems
+func (o *Override) IsIncomplete() bool {
+	return len(o.LintProblems()) > 0
+}
@@ -0,0 +1,265 @@
+// Package override reads override folders, the apply.md frontmatter that describes
+// them, and renders them as Hydra override arguments.
+package override
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// Override represents a single Hydra override configuration
+type Override struct {
+	Name        string
+	Type        string            // "+" or "="
+	Block       string            // e.g., "experiment.config.logging"
+	File        string            // target config file within the block
+	Package     string            // package the config is placed in, e.g. "_global_" or "model.backbone"
+	ModulePath  string            // e.g., "experiment/config"
+	Module      string            // e.g., "logging"
+	Description string            // one-line summary
+	Content     string            // content of override.yaml
+	ApplyInfo   string            // content of apply.md
+	FolderPath  string            // full path to override folder
+	Dir         string            // group folder relative to the overrides dir, e.g. "logging"
+	Priority    int               // sort priority from frontmatter, higher first
+	Params      map[string]string // parameter defaults from frontmatter
+	ParamValues map[string]string // parameter values chosen in this project
+	Modified    time.Time         // latest mtime of the folder and its files
+	Includes    []string          // names of the overrides a composite override bundles
+	Tags        []string          // free-form labels from frontmatter
+	Hooks       config.HookSet    // commands run when the override is applied or removed
+	Archived    bool              // hidden from the Available list unless archived ones are shown
+	ExportGroup string            // entrypoint whose env var the override is exported to, e.g. "train"
+
+	members     []*Override // resolved Includes
+	badIncludes []string    // problems found resolving Includes
+}
+
+// Meta is the YAML frontmatter of an override's apply.md
+type Meta struct {
+	Type        string            `yaml:"type"`
+	Block       string            `yaml:"block"`
+	File        string            `yaml:"file"`
+	Package     string            `yaml:"package"`
+	ModulePath  string            `yaml:"module_path"`
+	Module      string            `yaml:"module"`
+	Description string            `yaml:"description"`
+	Priority    int               `yaml:"priority"`
+	Params      map[string]string `yaml:"params"`
+	Includes    []string          `yaml:"includes"`
+	Tags        []string          `yaml:"tags"`
+	Hooks       config.HookSet    `yaml:"hooks"`
+	Archived    bool              `yaml:"archived"`
+	ExportGroup string            `yaml:"export_group"`
+}
+
+// Types are the override types offered when creating an override
+var Types = []string{"+", "=", "++", "--"}
+
+// SplitFrontmatter splits apply.md content into its YAML frontmatter and the body after it.
+// ok is false when the content has no frontmatter.
+func SplitFrontmatter(content string) (frontmatter, body string, ok bool) {
+	if !strings.HasPrefix(content, "---") {
+		return "", content, false
+	}
+	parts := strings.SplitN(content[3:], "---", 2)
+	if len(parts) < 2 {
+		return parts[0], "", true
+	}
+	return parts[0], parts[1], true
+}
+
+// ParseApplyInfo sets the override's metadata from the frontmatter of its apply.md.
+func (o *Override) ParseApplyInfo() {
+	// Nested overrides default their module path to the folder they live in
+	o.ModulePath = o.Dir
+
+	frontmatter, _, ok := SplitFrontmatter(o.ApplyInfo)
+	if !ok {
+		return
+	}
+
+	var meta Meta
+	if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+		return
+	}
+	o.Type = meta.Type
+	o.Block = meta.Block
+	o.File = meta.File
+	o.Package = meta.Package
+	if meta.ModulePath != "" {
+		o.ModulePath = meta.ModulePath
+	}
+	o.Module = meta.Module
+	o.Description = meta.Description
+	o.Priority = meta.Priority
+	o.Params = meta.Params
+	o.Includes = meta.Includes
+	o.Tags = meta.Tags
+	o.Hooks = meta.Hooks
+	o.Archived = meta.Archived
+	o.ExportGroup = meta.ExportGroup
+}
+
+// SetFrontmatterFields returns apply.md content with the given frontmatter keys set,
+// preserving the body and any other keys. Keys are written in the order given.
+func SetFrontmatterFields(content string, fields [][2]string) (string, error) {
+	return editFrontmatter(content, func(root *yaml.Node) {
+		for _, field := range fields {
+			setFrontmatterValue(root, field[0], &yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: field[1]})
+		}
+	})
+}
+
+// SetFrontmatterFlag returns apply.md content with a boolean frontmatter key set to
+// true, or removed when on is false, preserving the body and any other keys.
+func SetFrontmatterFlag(content, key string, on bool) (string, error) {
+	return editFrontmatter(content, func(root *yaml.Node) {
+		if on {
+			setFrontmatterValue(root, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+			return
+		}
+		removeFrontmatterValue(root, key)
+	})
+}
+
+// RemoveFrontmatterField returns apply.md content without the given frontmatter key,
+// preserving the body and any other keys.
+func RemoveFrontmatterField(content, key string) (string, error) {
+	return editFrontmatter(content, func(root *yaml.Node) {
+		removeFrontmatterValue(root, key)
+	})
+}
+
+// SetFrontmatterTags returns apply.md content with its tags replaced, or removed when
+// tags is empty
+func SetFrontmatterTags(content string, tags []string) (string, error) {
+	return editFrontmatter(content, func(root *yaml.Node) {
+		if len(tags) == 0 {
+			removeFrontmatterValue(root, "tags")
+			return
+		}
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, tag := range tags {
+			seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: tag})
+		}
+		setFrontmatterValue(root, "tags", seq)
+	})
+}
+
+// setFrontmatterValue sets key in the frontmatter mapping, appending it when missing
+func setFrontmatterValue(root *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			root.Content[i+1] = value
+			return
+		}
+	}
+	root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+// removeFrontmatterValue removes key from the frontmatter mapping
+func removeFrontmatterValue(root *yaml.Node, key string) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// editFrontmatter parses the frontmatter of apply.md content, lets edit change its
+// mapping and returns the content with the result, preserving the body.
+func editFrontmatter(content string, edit func(root *yaml.Node)) (string, error) {
+	frontmatter, body, ok := SplitFrontmatter(content)
+	if !ok {
+		body = "\n" + content
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		return "", fmt.Errorf("parsing frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("frontmatter is not a mapping")
+	}
+	edit(root)
+
+	var buf strings.Builder
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return "", fmt.Errorf("encoding frontmatter: %w", err)
+	}
+	encoder.Close()
+
+	return "---\n" + buf.String() + "---" + body, nil
+}
+
+// Load reads the override folders under dir in store, sorted by name and with the includes of
+// composite overrides resolved. Folders without an apply.md are skipped, and so are
+// folders reusing the name of one already loaded.
+func Load(store fsys.Store, dir string) ([]*Override, error) {
+	logging.Logger.Debug("loading overrides", "dir", dir)
+
+	folders, err := ScanFolders(store, dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading overrides directory: %w", err)
+	}
+
+	var overrides []*Override
+	seen := make(map[string]string)
+	for _, folder := range folders {
+		overridePath := folder.Path
+		applyPath := filepath.Join(overridePath, "apply.md")
+		overrideYAMLPath := filepath.Join(overridePath, "override.yaml")
+
+		applyContent, err := store.ReadFile(applyPath)
+		if err != nil {
+			logging.Logger.Debug("skipping override folder", "path", overridePath, "error", err)
+			continue
+		}
+
+		// Names identify overrides in the env file and Hydra, so they must be unique
+		if other, ok := seen[folder.Name]; ok {
+			logging.Logger.Warn("skipping override with duplicate name", "path", overridePath, "other", other)
+			continue
+		}
+		seen[folder.Name] = overridePath
+
+		o := &Override{
+			Name:       folder.Name,
+			FolderPath: overridePath,
+			Dir:        folder.Dir,
+			ApplyInfo:  string(applyContent),
+			Modified:   ModTime(store, overridePath),
+		}
+		o.ParseApplyInfo()
+
+		if overrideContent, err := store.ReadFile(overrideYAMLPath); err == nil {
+			o.Content = string(overrideContent)
+		}
+
+		logging.Logger.Debug("loaded override", "name", o.Name, "type", o.Type, "block", o.Block)
+		overrides = append(overrides, o)
+	}
+
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].Name < overrides[j].Name
+	})
+	ResolveIncludes(overrides)
+
+	return overrides, nil
+}
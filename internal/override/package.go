@@ -0,0 +1,57 @@
+package override
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PackageGlobal is the package of the config root
+const PackageGlobal = "_global_"
+
+// packageHeaderPattern matches Hydra's package directive, e.g. "# @package _global_"
+var packageHeaderPattern = regexp.MustCompile(`^#\s*@package\s+(\S+)\s*$`)
+
+// ContentPackage returns the package set by the # @package header of a config, or ""
+// without one. Hydra only reads the header before the config's first line of content.
+func ContentPackage(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if m := packageHeaderPattern.FindStringSubmatch(line); m != nil {
+			return m[1]
+		}
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+	}
+	return ""
+}
+
+// WithPackageHeader returns content starting with a # @package header for pkg, replacing
+// any header it has. Content is returned as is when pkg is empty.
+func WithPackageHeader(content, pkg string) string {
+	if pkg == "" {
+		return content
+	}
+	header := "# @package " + pkg
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if packageHeaderPattern.MatchString(trimmed) {
+			lines[i] = header
+			return strings.Join(lines, "\n")
+		}
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+	}
+	return header + "\n" + content
+}
+
+// keyPrefix returns what the keys of a value override are prefixed with to place them
+// in its package: nothing for the root, otherwise the package path and a dot
+func (o *Override) keyPrefix() string {
+	if o.Package == "" || o.Package == PackageGlobal {
+		return ""
+	}
+	return o.Package + "."
+}
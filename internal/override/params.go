@@ -0,0 +1,60 @@
+package override
+
+import (
+	"regexp"
+	"sort"
+)
+
+// paramPattern matches {{name}} placeholders in a parameterized override.yaml
+var paramPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// ParamNames returns the override's parameters: the keys of its frontmatter params
+// section plus any other placeholders used in override.yaml, sorted.
+func (o *Override) ParamNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range o.Params {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for _, m := range paramPattern.FindAllStringSubmatch(o.Content, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasParams reports whether the override needs values filled in before it is applied
+func (o *Override) HasParams() bool {
+	return len(o.ParamNames()) > 0
+}
+
+// ParamValue returns the value chosen for a parameter, or its default from frontmatter
+func (o *Override) ParamValue(name string) string {
+	if value, ok := o.ParamValues[name]; ok {
+		return value
+	}
+	return o.Params[name]
+}
+
+// RenderedContent returns override.yaml with its placeholders replaced by the chosen
+// parameter values. Overrides without parameters are returned unchanged.
+func (o *Override) RenderedContent() string {
+	if !o.HasParams() {
+		return o.Content
+	}
+	return ReplaceParams(o.Content, func(name, _ string) string {
+		return o.ParamValue(name)
+	})
+}
+
+// ReplaceParams returns text with every {{name}} placeholder replaced by what replace
+// returns for the parameter's name and the placeholder as written
+func ReplaceParams(text string, replace func(name, placeholder string) string) string {
+	return paramPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		return replace(paramPattern.FindStringSubmatch(placeholder)[1], placeholder)
+	})
+}
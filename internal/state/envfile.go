@@ -0,0 +1,358 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+)
+
+// DefaultEnvironment is the applied set used until another environment is chosen
+const DefaultEnvironment = "default"
+
+// ActiveEnvVar is the env file variable naming the active environment. It is only
+// written when the active environment is not the default one.
+const ActiveEnvVar = "LAZYHYDRA_ENV"
+
+// EnvironmentsVar is the env file variable listing the environments other than the
+// active one whose applied sets the env file holds (see EnvironmentVar).
+const EnvironmentsVar = "LAZYHYDRA_ENVS"
+
+// GroupVarsVar is the env file variable listing the export group variables written by
+// the last save, so the variable of a group no override has any more is still removed.
+const GroupVarsVar = "LAZYHYDRA_GROUP_VARS"
+
+// environmentNamePattern restricts environment names so they map to env var suffixes
+var environmentNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ValidateEnvironmentName reports why a name cannot be used for an environment
+func ValidateEnvironmentName(name string) error {
+	if !environmentNamePattern.MatchString(name) {
+		return fmt.Errorf("environment name %q must be lowercase letters, digits and underscores", name)
+	}
+	return nil
+}
+
+// EnvState is the applied state of every environment recorded in the env file
+type EnvState struct {
+	Active string                     // environment exported as env_var_name and override_str_var_name
+	Sets   map[string]map[string]bool // applied override names by environment
+}
+
+// EnvFilePath returns the path of the file the applied state is persisted to
+func (p *Project) EnvFilePath() string {
+	return p.Config.EnvFilePath(p.Root)
+}
+
+// EnvironmentVar returns the env file variable holding the applied set of an environment
+// while it is not the active one, e.g. HYDRA_OVERRIDES_PROD.
+func (p *Project) EnvironmentVar(env string) string {
+	return p.Config.EnvVarName + "_" + strings.ToUpper(env)
+}
+
+// DecodeAppliedNames decodes a persisted applied set
+func DecodeAppliedNames(value string) (map[string]bool, error) {
+	applied := make(map[string]bool)
+	value = strings.Trim(value, "\"'")
+	if value == "" {
+		return applied, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return applied, fmt.Errorf("decoding persisted state: %w", err)
+	}
+	for _, name := range strings.Split(string(decoded), ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			applied[name] = true
+		}
+	}
+	return applied, nil
+}
+
+// EncodeAppliedNames encodes an applied set for the env file
+func EncodeAppliedNames(names []string) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Join(names, ",")))
+}
+
+// ReadEnvState reads the applied sets of all environments from the env file. The set
+// in env_var_name belongs to the active environment; the others are kept in
+// per-environment variables (see EnvironmentVar) of the environments EnvironmentsVar lists.
+func (p *Project) ReadEnvState() (EnvState, error) {
+	state := EnvState{Active: DefaultEnvironment, Sets: make(map[string]map[string]bool)}
+
+	data, err := p.FS.ReadFile(p.EnvFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, err
+	}
+
+	envs := make(map[string]string)
+	for _, env := range p.envIndex(data, EnvironmentsVar) {
+		if ValidateEnvironmentName(env) == nil {
+			envs[p.EnvironmentVar(env)] = env
+		}
+	}
+	var activeSet map[string]bool
+	var firstErr error
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		name, value, ok := p.parseEnvLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		switch {
+		case name == ActiveEnvVar:
+			state.Active = strings.Trim(value, "\"'")
+		case name == p.Config.EnvVarName:
+			set, err := DecodeAppliedNames(value)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			activeSet = set
+		case envs[name] != "":
+			set, err := DecodeAppliedNames(value)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			state.Sets[envs[name]] = set
+		}
+	}
+	if activeSet != nil {
+		state.Sets[state.Active] = activeSet
+	}
+	if firstErr != nil {
+		return state, firstErr
+	}
+	return state, scanner.Err()
+}
+
+// ReadAppliedState reads the names of the overrides persisted for the current environment.
+func (p *Project) ReadAppliedState() (map[string]bool, error) {
+	state, err := p.ReadEnvState()
+	applied := state.Sets[p.Env]
+	if applied == nil {
+		applied = make(map[string]bool)
+	}
+	return applied, err
+}
+
+// environmentLines returns the env file lines that record the environments other than
+// the current one, and the active environment when it is not the default.
+func (p *Project) environmentLines() []string {
+	state, _ := p.ReadEnvState()
+
+	var envs []string
+	for env, set := range state.Sets {
+		if env != p.Env && len(set) > 0 {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+
+	var lines []string
+	for _, env := range envs {
+		names := p.orderedNames(state.Sets[env])
+		lines = append(lines, p.envLine(p.EnvironmentVar(env), EncodeAppliedNames(names)))
+	}
+	if len(envs) > 0 {
+		lines = append(lines, p.envLine(EnvironmentsVar, strings.Join(envs, " ")))
+	}
+	if p.Env != DefaultEnvironment {
+		lines = append(lines, p.envLine(ActiveEnvVar, p.Env))
+	}
+	return lines
+}
+
+// orderedNames returns the names in set in the order of the overrides, followed by the
+// names that match no override, so a missing override is not dropped by saving.
+func (p *Project) orderedNames(set map[string]bool) []string {
+	var names []string
+	found := make(map[string]bool)
+	for _, o := range p.Overrides {
+		if set[o.Name] {
+			names = append(names, o.Name)
+			found[o.Name] = true
+		}
+	}
+	var missing []string
+	for name, ok := range set {
+		if ok && !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return append(names, missing...)
+}
+
+// BuildEnvFile returns the env file content that saving the current state would write,
+// along with the names of the applied overrides it records.
+func (p *Project) BuildEnvFile() ([]byte, []string) {
+	envrcPath := p.EnvFilePath()
+
+	var lines []string
+	if existing, err := p.FS.ReadFile(envrcPath); err == nil {
+		managed := p.ManagedEnvVars(existing)
+		scanner := bufio.NewScanner(bytes.NewReader(existing))
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !p.IsManagedEnvLine(line, managed) {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	appliedNames := p.orderedNames(p.Applied)
+
+	// Other environments keep their applied sets in their own variables
+	lines = append(lines, p.environmentLines()...)
+
+	if len(appliedNames) > 0 {
+		lines = append(lines, p.envLine(p.Config.EnvVarName, EncodeAppliedNames(appliedNames)))
+	}
+
+	// Always write the override string (empty string if no overrides)
+	// Join with spaces for the env file (display uses newlines for readability)
+	overrideStr := strings.ReplaceAll(p.BuildString(), "\n", " ")
+	lines = append(lines, p.envLine(p.Config.OverrideStrVarName, overrideStr))
+
+	for _, v := range p.DerivedVars(appliedNames) {
+		lines = append(lines, p.envLine(v[0], v[1]))
+	}
+	var groupVars []string
+	for _, v := range p.GroupVars() {
+		lines = append(lines, p.envLine(v[0], v[1]))
+		groupVars = append(groupVars, v[0])
+	}
+	if len(groupVars) > 0 {
+		lines = append(lines, p.envLine(GroupVarsVar, strings.Join(groupVars, " ")))
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n"), appliedNames
+}
+
+// envIndex returns the space-separated names the variable index holds in the env file
+// content data
+func (p *Project) envIndex(data []byte, index string) []string {
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		name, value, ok := p.parseEnvLine(scanner.Text())
+		if ok && name == index {
+			names = append(names, strings.Fields(strings.Trim(value, "\"'"))...)
+		}
+	}
+	return names
+}
+
+// ManagedEnvVars returns the environment and export group variables lazyhydra manages
+// in the env file content data: those of the environments listed in EnvironmentsVar,
+// of the current export groups and those listed in GroupVarsVar. Other variables named
+// like an environment's or a group's are left alone.
+func (p *Project) ManagedEnvVars(data []byte) map[string]bool {
+	managed := make(map[string]bool)
+	for _, env := range p.envIndex(data, EnvironmentsVar) {
+		managed[p.EnvironmentVar(env)] = true
+	}
+	for _, group := range p.ExportGroups() {
+		managed[p.Config.GroupVar(group)] = true
+	}
+	for _, name := range p.envIndex(data, GroupVarsVar) {
+		managed[name] = true
+	}
+	return managed
+}
+
+// IsManagedEnvLine reports whether an env file line is written by lazyhydra, given the
+// variables ManagedEnvVars found in the file
+func (p *Project) IsManagedEnvLine(line string, managed map[string]bool) bool {
+	name, _, ok := p.parseEnvLine(line)
+	return ok && (name == p.Config.EnvVarName ||
+		name == ActiveEnvVar ||
+		name == EnvironmentsVar ||
+		name == GroupVarsVar ||
+		name == p.Config.OverrideStrVarName ||
+		p.Config.DerivedVars[name] != "" ||
+		managed[name])
+}
+
+// GroupVars returns the override string of every export group as name and value pairs.
+// A group is skipped when its variable would replace another one lazyhydra writes.
+func (p *Project) GroupVars() [][2]string {
+	var vars [][2]string
+	for _, group := range p.ExportGroups() {
+		name := p.Config.GroupVar(group)
+		if name == p.Config.EnvVarName || name == p.Config.OverrideStrVarName || p.Config.DerivedVars[name] != "" {
+			logging.Logger.Warn("export group variable clashes with another variable, skipping it", "group", group, "variable", name)
+			continue
+		}
+		vars = append(vars, [2]string{name, strings.ReplaceAll(p.BuildGroupString(group), "\n", " ")})
+	}
+	return vars
+}
+
+// AppliedHash returns a 12-character hash of a set of override names, the same
+// whenever the same overrides are applied
+func AppliedHash(names []string) string {
+	// Sorted, so the hash does not depend on the order overrides were applied in
+	sorted := slices.Clone(names)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, ",")))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// DerivedVars returns the derived_vars as name and value pairs for the applied
+// override names
+func (p *Project) DerivedVars(appliedNames []string) [][2]string {
+	var vars [][2]string
+	for _, name := range p.Config.DerivedVarNames() {
+		var value string
+		switch p.Config.DerivedVars[name] {
+		case config.DerivedCount:
+			value = strconv.Itoa(len(appliedNames))
+		case config.DerivedHash:
+			value = AppliedHash(appliedNames)
+		case config.DerivedNames:
+			value = strings.Join(appliedNames, ",")
+		case config.DerivedArgs:
+			value = strings.ReplaceAll(p.BuildString(), "\n", " ")
+		}
+		vars = append(vars, [2]string{name, value})
+	}
+	return vars
+}
+
+// WriteEnvFile saves the applied state to the env file and returns the content written.
+// The file is replaced atomically, so an interrupted write cannot truncate it.
+func (p *Project) WriteEnvFile() ([]byte, error) {
+	envrcPath := p.EnvFilePath()
+
+	content, appliedNames := p.BuildEnvFile()
+	// project_env_file may be in a directory of its own, e.g. env/.envrc.local
+	if err := p.FS.MkdirAll(filepath.Dir(envrcPath), 0755); err != nil {
+		return nil, err
+	}
+	if err := fsys.WriteFileAtomic(p.FS, envrcPath, content, 0644); err != nil {
+		logging.Logger.Debug("writing persisted state failed", "path", envrcPath, "error", err)
+		return nil, err
+	}
+	logging.Logger.Debug("wrote persisted state", "path", envrcPath, "applied", appliedNames)
+	return content, nil
+}
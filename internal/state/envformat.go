@@ -0,0 +1,66 @@
+package state
+
+import (
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+)
+
+// envLine renders an env file line setting name to value in the configured env_format
+func (p *Project) envLine(name, value string) string {
+	switch p.Config.EnvFormat {
+	case config.EnvFormatDotenv:
+		return name + "=" + dotenvQuote(value)
+	case config.EnvFormatPowerShell:
+		return "$env:" + name + " = " + powerShellQuote(value)
+	default:
+		return "export " + name + "=" + envQuote(value)
+	}
+}
+
+// parseEnvLine returns the variable an env file line sets and its raw, still quoted
+// value. Lines in another format, comments and blank lines are not ok.
+func (p *Project) parseEnvLine(line string) (name, value string, ok bool) {
+	switch p.Config.EnvFormat {
+	case config.EnvFormatDotenv:
+		// dotenv files may use export like a shell script
+		line = strings.TrimPrefix(line, "export ")
+	case config.EnvFormatPowerShell:
+		if !strings.HasPrefix(line, "$env:") {
+			return "", "", false
+		}
+		line = strings.TrimPrefix(line, "$env:")
+	default:
+		if !strings.HasPrefix(line, "export ") {
+			return "", "", false
+		}
+		line = strings.TrimPrefix(line, "export ")
+	}
+	name, value, ok = strings.Cut(line, "=")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !ok || name == "" || strings.ContainsAny(name, " \t#") {
+		return "", "", false
+	}
+	return name, value, true
+}
+
+// envQuote returns s as a double-quoted shell word for the env file, escaping the
+// characters the shell still interprets inside double quotes.
+func envQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`")
+	return `"` + r.Replace(s) + `"`
+}
+
+// dotenvQuote returns s as a single-quoted dotenv value. Loaders such as python-dotenv
+// expand ${VAR} inside double quotes, which would empty Hydra interpolations, but not
+// inside single quotes, where only backslashes and single quotes are unescaped.
+func dotenvQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + r.Replace(s) + "'"
+}
+
+// powerShellQuote returns s as a single-quoted PowerShell string, which is taken
+// literally apart from doubled single quotes.
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,90 @@
+package state
+
+import (
+	"os/exec"
+	"regexp"
+	"testing"
+)
+
+// quoteTests are tricky values with what envQuote, dotenvQuote and powerShellQuote make
+// of each
+var quoteTests = []struct {
+	name, in                string
+	env, dotenv, powerShell string
+}{
+	{"plain", "+a/b=foo", `"+a/b=foo"`, `'+a/b=foo'`, `'+a/b=foo'`},
+	{"empty", "", `""`, `''`, `''`},
+	{"spaces", "++name='hello world'", `"++name='hello world'"`, `'++name=\'hello world\''`, `'++name=''hello world'''`},
+	{"dollar", "++dir=${oc.env:HOME}", `"++dir=\${oc.env:HOME}"`, `'++dir=${oc.env:HOME}'`, `'++dir=${oc.env:HOME}'`},
+	{"interpolation", "++out=${hydra:runtime.cwd}/${name}", `"++out=\${hydra:runtime.cwd}/\${name}"`, `'++out=${hydra:runtime.cwd}/${name}'`, `'++out=${hydra:runtime.cwd}/${name}'`},
+	{"double quote", `++msg="hi"`, `"++msg=\"hi\""`, `'++msg="hi"'`, `'++msg="hi"'`},
+	{"backslash", `++path='C:\\tmp'`, `"++path='C:\\\\tmp'"`, `'++path=\'C:\\\\tmp\''`, `'++path=''C:\\tmp'''`},
+	{"backtick", "++cmd=`ls`", "\"++cmd=\\`ls\\`\"", "'++cmd=`ls`'", "'++cmd=`ls`'"},
+	{"comma", "++lst=[1,2]", `"++lst=[1,2]"`, `'++lst=[1,2]'`, `'++lst=[1,2]'`},
+	{"map", "++opt={a:1,b:'x y'}", `"++opt={a:1,b:'x y'}"`, `'++opt={a:1,b:\'x y\'}'`, `'++opt={a:1,b:''x y''}'`},
+	{"newline", "+a/b=foo\n++x=1", "\"+a/b=foo\n++x=1\"", "'+a/b=foo\n++x=1'", "'+a/b=foo\n++x=1'"},
+}
+
+func TestEnvQuote(t *testing.T) {
+	for _, tt := range quoteTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := envQuote(tt.in); got != tt.env {
+				t.Errorf("envQuote(%q) = %s, want %s", tt.in, got, tt.env)
+			}
+		})
+	}
+}
+
+func TestDotenvQuote(t *testing.T) {
+	for _, tt := range quoteTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dotenvQuote(tt.in); got != tt.dotenv {
+				t.Errorf("dotenvQuote(%q) = %s, want %s", tt.in, got, tt.dotenv)
+			}
+		})
+	}
+}
+
+func TestPowerShellQuote(t *testing.T) {
+	for _, tt := range quoteTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := powerShellQuote(tt.in); got != tt.powerShell {
+				t.Errorf("powerShellQuote(%q) = %s, want %s", tt.in, got, tt.powerShell)
+			}
+		})
+	}
+}
+
+// TestEnvQuoteShell checks that a shell reading the env file gets every value back as is
+func TestEnvQuoteShell(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh to run the env file with")
+	}
+	for _, tt := range quoteTests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := exec.Command(sh, "-c", "export V="+envQuote(tt.in)+"; printf %s \"$V\"").Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.in {
+				t.Errorf("sh read %q back as %q", tt.in, out)
+			}
+		})
+	}
+}
+
+// TestDotenvQuoteLoad checks that unquoting a value the way python-dotenv does for
+// single quotes, unescaping only \\ and \' and expanding nothing, gives it back as is
+func TestDotenvQuoteLoad(t *testing.T) {
+	unescape := regexp.MustCompile(`\\[\\']`)
+	for _, tt := range quoteTests {
+		t.Run(tt.name, func(t *testing.T) {
+			quoted := dotenvQuote(tt.in)
+			got := unescape.ReplaceAllStringFunc(quoted[1:len(quoted)-1], func(s string) string { return s[1:] })
+			if got != tt.in {
+				t.Errorf("python-dotenv would read %q back as %q", tt.in, got)
+			}
+		})
+	}
+}
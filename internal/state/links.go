@@ -0,0 +1,138 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/ramy/lazyhydra/internal/override"
+	"gopkg.in/yaml.v3"
+)
+
+// SymlinkPath returns the path where the symlink should be created for an override.
+// E.g., for block "experiment.config.logging" and name "detailed_logging",
+// returns: hydra_configs_dir/experiment/config/logging/detailed_logging_override.yaml
+func (p *Project) SymlinkPath(o *override.Override) string {
+	hydraDir := p.Config.ExpandPath(p.Config.HydraConfigsDir)
+	blockPath := strings.ReplaceAll(o.Block, ".", string(filepath.Separator))
+	return filepath.Join(hydraDir, blockPath, o.Name+"_override.yaml")
+}
+
+// Link creates a symlink from the override's override.yaml into the Hydra configs tree.
+func (p *Project) Link(o *override.Override) error {
+	if p.DryRun || p.ReadOnly {
+		return nil
+	}
+
+	// Parameterized overrides are rendered with their chosen values first
+	if o.HasParams() {
+		if err := p.WriteInstance(o); err != nil {
+			return err
+		}
+	}
+	if o.Block == "" {
+		return nil
+	}
+
+	source := p.OverrideSource(o)
+	linkPath := p.SymlinkPath(o)
+
+	// Create intermediate directories
+	if err := p.FS.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return fmt.Errorf("creating symlink directory: %w", err)
+	}
+
+	// Remove existing symlink if present (idempotent)
+	if info, err := p.FS.Lstat(linkPath); err == nil {
+		if info.Mode()&os.ModeSymlink != 0 {
+			p.FS.Remove(linkPath)
+		} else {
+			return fmt.Errorf("symlink path exists and is not a symlink: %s", linkPath)
+		}
+	}
+
+	return p.FS.Symlink(source, linkPath)
+}
+
+// Unlink removes the symlink for an override from the Hydra configs tree.
+func (p *Project) Unlink(o *override.Override) error {
+	if o.Block == "" || p.DryRun || p.ReadOnly {
+		return nil
+	}
+
+	linkPath := p.SymlinkPath(o)
+
+	info, err := p.FS.Lstat(linkPath)
+	if err != nil {
+		return nil // doesn't exist, nothing to do
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return p.FS.Remove(linkPath)
+	}
+
+	return nil
+}
+
+// ReconcileSymlinks ensures symlinks match the persisted applied state.
+func (p *Project) ReconcileSymlinks() {
+	for _, o := range p.Overrides {
+		if p.Applied[o.Name] {
+			p.Link(o)
+		} else {
+			p.Unlink(o)
+		}
+	}
+}
+
+// InstanceDir returns the per-project folder holding a parameterized override's chosen
+// values (params.yaml) and its rendered override.yaml.
+func (p *Project) InstanceDir(o *override.Override) string {
+	return filepath.Join(p.Root, ".lazyhydra", "instances", o.Name)
+}
+
+// OverrideSource returns the file an applied override's symlink points to: the rendered
+// instance for parameterized overrides, override.yaml otherwise.
+func (p *Project) OverrideSource(o *override.Override) string {
+	if o.HasParams() {
+		return filepath.Join(p.InstanceDir(o), "override.yaml")
+	}
+	return filepath.Join(o.FolderPath, "override.yaml")
+}
+
+// LoadParamValues reads the values chosen for a parameterized override in this project
+func (p *Project) LoadParamValues(o *override.Override) {
+	o.ParamValues = nil
+	data, err := p.FS.ReadFile(filepath.Join(p.InstanceDir(o), "params.yaml"))
+	if err != nil {
+		return
+	}
+	if err := yaml.Unmarshal(data, &o.ParamValues); err != nil {
+		logging.Logger.Warn("ignoring invalid params file", "override", o.Name, "error", err)
+	}
+}
+
+// WriteInstance writes a parameterized override's chosen values and rendered content
+// to its instance folder, with a # @package header when the override sets a package.
+func (p *Project) WriteInstance(o *override.Override) error {
+	values := make(map[string]string)
+	for _, name := range o.ParamNames() {
+		values[name] = o.ParamValue(name)
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	dir := p.InstanceDir(o)
+	if err := p.FS.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating instance directory: %w", err)
+	}
+	if err := p.FS.WriteFile(filepath.Join(dir, "params.yaml"), data, 0644); err != nil {
+		return err
+	}
+	content := override.WithPackageHeader(o.RenderedContent(), o.Package)
+	return p.FS.WriteFile(filepath.Join(dir, "override.yaml"), []byte(content), 0644)
+}
@@ -0,0 +1,145 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/ramy/lazyhydra/internal/override"
+	"gopkg.in/yaml.v3"
+)
+
+// overridesFileHeader starts the file written to overrides_file. The package directive
+// puts values at the config root and lets the defaults list name groups absolutely.
+const overridesFileHeader = `# @package _global_
+# Applied overrides, written by lazyhydra. Edits are lost on the next save.
+`
+
+// OverridesFilePath returns where the applied overrides are written as a Hydra config,
+// or "" when overrides_file is not set. Relative paths are inside the project root.
+func (p *Project) OverridesFilePath() string {
+	if p.Config.OverridesFile == "" {
+		return ""
+	}
+	path := p.Config.ExpandPath(p.Config.OverridesFile)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.Root, path)
+	}
+	return path
+}
+
+// BuildOverridesFile renders the applied overrides as a Hydra config to include from a
+// defaults list: config group overrides become defaults entries, "+" ones appended and
+// the rest overriding, and value overrides are merged into the values below them.
+// Overrides that delete keys cannot be expressed and are listed in comments instead.
+func (p *Project) BuildOverridesFile() []byte {
+	var defaults []map[string]string
+	values := make(map[string]interface{})
+	var skipped []string
+
+	for _, o := range p.Overrides {
+		if !p.Applied[o.Name] || o.IsComposite() {
+			continue
+		}
+		if o.Type == "--" || o.Type == "~" {
+			skipped = append(skipped, o.Args(p.Config)...)
+			continue
+		}
+		if o.Block != "" {
+			// The option is the file Link puts in the group, see SymlinkPath
+			group := "/" + strings.ReplaceAll(o.Block, ".", "/")
+			if o.Package != "" {
+				group += "@" + o.Package
+			}
+			if o.Type != "+" {
+				group = "override " + group
+			}
+			defaults = append(defaults, map[string]string{group: o.Name + "_override"})
+			continue
+		}
+		var content map[string]interface{}
+		if err := yaml.Unmarshal([]byte(o.RenderedContent()), &content); err != nil {
+			logging.Logger.Warn("skipping override with invalid override.yaml", "override", o.Name, "error", err)
+			continue
+		}
+		// A value override placed in a package sets its keys below the package
+		if o.Package != "" && o.Package != override.PackageGlobal {
+			keys := strings.Split(o.Package, ".")
+			for i := len(keys) - 1; i >= 0; i-- {
+				content = map[string]interface{}{keys[i]: content}
+			}
+		}
+		mergeValues(values, content)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(overridesFileHeader)
+	for _, arg := range skipped {
+		fmt.Fprintf(&b, "# not representable here: %s\n", arg)
+	}
+
+	// Build the document by hand so the defaults list comes before the values
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	if len(defaults) > 0 {
+		var list yaml.Node
+		list.Encode(defaults)
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "defaults"}, &list)
+	}
+	var merged yaml.Node
+	merged.Encode(values)
+	doc.Content = append(doc.Content, merged.Content...)
+	if len(doc.Content) > 0 {
+		encoder := yaml.NewEncoder(&b)
+		encoder.SetIndent(2)
+		encoder.Encode(doc)
+		encoder.Close()
+	}
+	return b.Bytes()
+}
+
+// mergeValues merges src into dst, splitting dotted keys such as model.hidden_size
+// into nested maps. Values from src win.
+func mergeValues(dst, src map[string]interface{}) {
+	for key, value := range src {
+		target := dst
+		parts := strings.Split(key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := target[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				target[part] = next
+			}
+			target = next
+		}
+		last := parts[len(parts)-1]
+		if m, ok := value.(map[string]interface{}); ok {
+			existing, ok := target[last].(map[string]interface{})
+			if !ok {
+				existing = make(map[string]interface{})
+				target[last] = existing
+			}
+			mergeValues(existing, m)
+			continue
+		}
+		target[last] = value
+	}
+}
+
+// WriteOverridesFile writes the applied overrides to overrides_file, when it is set
+func (p *Project) WriteOverridesFile() error {
+	path := p.OverridesFilePath()
+	if path == "" {
+		return nil
+	}
+	if err := p.FS.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating overrides_file directory: %w", err)
+	}
+	if err := fsys.WriteFileAtomic(p.FS, path, p.BuildOverridesFile(), 0644); err != nil {
+		return fmt.Errorf("writing overrides_file: %w", err)
+	}
+	logging.Logger.Debug("wrote overrides file", "path", path)
+	return nil
+}
@@ -0,0 +1,245 @@
+// Package state tracks which overrides are applied in a project: the env file that
+// records them for every environment, and the symlinks and rendered instances that
+// make them visible to Hydra.
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/override"
+)
+
+// Project holds a project's overrides and the set applied in its active environment.
+// Its files are read and written through FS, which is fsys.OS outside of tests.
+type Project struct {
+	Config    *config.Config
+	FS        fsys.Store
+	Root      string // project root, where the env file and rendered instances live
+	Env       string // active environment, see envfile.go
+	DryRun    bool   // leave symlinks and instances alone
+	ReadOnly  bool   // like DryRun; saving is refused by the caller
+	Overrides []*override.Override
+	Applied   map[string]bool
+}
+
+// LoadOverrides reads the overrides directory along with the parameter values chosen
+// for them in this project.
+func (p *Project) LoadOverrides() error {
+	overrides, err := override.Load(p.FS, p.Config.ExpandPath(p.Config.OverridesDir))
+	if err != nil {
+		return err
+	}
+	for _, o := range overrides {
+		p.LoadParamValues(o)
+	}
+	p.Overrides = append(p.Overrides, overrides...)
+	return nil
+}
+
+// LoadApplied adds the overrides persisted for the environment to the applied set.
+// Without an environment, the one that was active last is used.
+func (p *Project) LoadApplied() (map[string]bool, error) {
+	if p.Env == "" {
+		state, _ := p.ReadEnvState()
+		p.Env = state.Active
+	}
+
+	applied, err := p.ReadAppliedState()
+	for name := range applied {
+		p.Applied[name] = true
+	}
+	return applied, err
+}
+
+// Find returns the override with the given name, or nil
+func (p *Project) Find(name string) *override.Override {
+	for _, o := range p.Overrides {
+		if o.Name == name {
+			return o
+		}
+	}
+	return nil
+}
+
+// MissingApplied returns the sorted names in the applied set that match no override,
+// e.g. because the folder was deleted or renamed outside lazyhydra. They stay recorded
+// in the env file until they are pruned or the override is recreated.
+func (p *Project) MissingApplied() []string {
+	var missing []string
+	for name, ok := range p.Applied {
+		if ok && p.Find(name) == nil {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// BuildString returns the override string of the applied overrides, one override per line
+func (p *Project) BuildString() string {
+	return p.BuildGroupString("")
+}
+
+// BuildArgs returns the Hydra CLI arguments of all applied overrides
+func (p *Project) BuildArgs() []string {
+	return p.BuildGroupArgs("")
+}
+
+// inGroup reports whether an override belongs to the override string of an export
+// group: the group's own overrides and those in no group, which every group shares.
+// The empty group takes every override.
+func inGroup(o *override.Override, group string) bool {
+	return group == "" || o.ExportGroup == "" || o.ExportGroup == group
+}
+
+// BuildGroupString returns the override string of the applied overrides of an export
+// group, one override per line
+func (p *Project) BuildGroupString(group string) string {
+	var parts []string
+
+	for _, o := range p.Overrides {
+		if !p.Applied[o.Name] || !inGroup(o, group) {
+			continue
+		}
+
+		if s := strings.Join(o.Args(p.Config), " "); s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// BuildGroupArgs returns the Hydra CLI arguments of the applied overrides of an export
+// group
+func (p *Project) BuildGroupArgs(group string) []string {
+	var args []string
+	for _, o := range p.Overrides {
+		if p.Applied[o.Name] && inGroup(o, group) {
+			args = append(args, o.Args(p.Config)...)
+		}
+	}
+	return args
+}
+
+// ExportGroups returns the export groups the overrides are assigned to, sorted
+func (p *Project) ExportGroups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, o := range p.Overrides {
+		if o.ExportGroup != "" && !seen[o.ExportGroup] {
+			seen[o.ExportGroup] = true
+			groups = append(groups, o.ExportGroup)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Apply links an override and marks it applied. A composite is applied together with
+// the overrides it includes; if one of them cannot be linked, the ones linked so far are
+// unlinked again so the set is applied completely or not at all. It returns the
+// overrides that became applied.
+func (p *Project) Apply(o *override.Override) ([]*override.Override, error) {
+	if !o.IsComposite() {
+		err := p.Link(o)
+		p.Applied[o.Name] = true
+		return []*override.Override{o}, err
+	}
+
+	var linked []*override.Override
+	for _, m := range o.Members() {
+		if p.Applied[m.Name] {
+			continue
+		}
+		if err := p.Link(m); err != nil {
+			for _, l := range linked {
+				p.Unlink(l)
+			}
+			return nil, fmt.Errorf("applying %s: %w", o.Name, err)
+		}
+		linked = append(linked, m)
+	}
+
+	for _, m := range linked {
+		p.Applied[m.Name] = true
+	}
+	p.Applied[o.Name] = true
+	return append(linked, o), nil
+}
+
+// Remove unlinks an override and marks it removed. Removing a composite also removes
+// the overrides it includes, keeping those that another applied composite still includes.
+func (p *Project) Remove(o *override.Override) {
+	delete(p.Applied, o.Name)
+	if !o.IsComposite() {
+		p.Unlink(o)
+		return
+	}
+
+	kept := make(map[string]bool)
+	for _, other := range p.Overrides {
+		if !p.Applied[other.Name] {
+			continue
+		}
+		for _, m := range other.Members() {
+			kept[m.Name] = true
+		}
+	}
+	for _, m := range o.Members() {
+		if !kept[m.Name] {
+			p.Unlink(m)
+			delete(p.Applied, m.Name)
+		}
+	}
+}
+
+// Rename renames an override's folder to newName, moving its symlink and applied state
+// along with it. The override is left as it was when the folder cannot be renamed.
+func (p *Project) Rename(o *override.Override, newName string) error {
+	oldName := o.Name
+	newPath := filepath.Join(filepath.Dir(o.FolderPath), newName)
+	wasApplied := p.Applied[oldName]
+
+	// Remove the old symlink before renaming
+	if wasApplied {
+		p.Unlink(o)
+	}
+
+	if err := p.FS.Rename(o.FolderPath, newPath); err != nil {
+		// Re-link if the rename failed
+		if wasApplied {
+			p.Link(o)
+		}
+		return err
+	}
+
+	o.Name = newName
+	o.FolderPath = newPath
+
+	// Update the applied set and re-create the symlink with the new name
+	if wasApplied {
+		delete(p.Applied, oldName)
+		p.Applied[newName] = true
+		p.Link(o)
+	}
+	return nil
+}
+
+// Forget drops a deleted override from the project: its symlink is removed and it is
+// no longer applied or listed.
+func (p *Project) Forget(o *override.Override) {
+	p.Unlink(o)
+	delete(p.Applied, o.Name)
+	for i, other := range p.Overrides {
+		if other.Name == o.Name {
+			p.Overrides = append(p.Overrides[:i], p.Overrides[i+1:]...)
+			break
+		}
+	}
+}
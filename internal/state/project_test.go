@@ -0,0 +1,345 @@
+package state
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+)
+
+// newTestProject returns a project rooted at /proj in a Memory store, with the
+// overrides in files written to conf/overrides and none applied
+func newTestProject(t *testing.T, files map[string]string) (*Project, *fsys.Memory) {
+	t.Helper()
+	store := fsys.NewMemory()
+	cfg := config.Default()
+	cfg.OverridesDir = "/proj/conf/overrides"
+	cfg.HydraConfigsDir = "/proj/conf"
+	cfg.ProjectEnvFile = ".envrc"
+	cfg.EnvFormat = config.EnvFormatDirenv
+
+	for name, content := range files {
+		path := filepath.Join(cfg.OverridesDir, name)
+		if err := store.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &Project{Config: cfg, FS: store, Root: "/proj", Env: DefaultEnvironment, Applied: make(map[string]bool)}
+	if err := p.LoadOverrides(); err != nil {
+		t.Fatal(err)
+	}
+	return p, store
+}
+
+var testOverrides = map[string]string{
+	"foo/apply.md":       "---\ntype: \"+\"\nblock: a.b\n---\n",
+	"foo/override.yaml":  "lr: 0.1\n",
+	"bar/apply.md":       "---\ntype: \"++\"\n---\n",
+	"bar/override.yaml":  "seed: 7\n",
+	"notes/readme.txt":   "not an override\n",
+	"group/baz/apply.md": "---\ntype: \"=\"\nblock: model\n---\n",
+}
+
+func overrideNames(p *Project) []string {
+	var names []string
+	for _, o := range p.Overrides {
+		names = append(names, o.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestLoadOverrides(t *testing.T) {
+	p, _ := newTestProject(t, testOverrides)
+
+	if got, want := overrideNames(p), []string{"bar", "baz", "foo"}; !slices.Equal(got, want) {
+		t.Fatalf("loaded %v, want %v", got, want)
+	}
+	foo := p.Find("foo")
+	if foo.Type != "+" || foo.Block != "a.b" || foo.Content != "lr: 0.1\n" {
+		t.Errorf("foo = type %q, block %q, content %q", foo.Type, foo.Block, foo.Content)
+	}
+	if baz := p.Find("baz"); baz.FolderPath != "/proj/conf/overrides/group/baz" {
+		t.Errorf("baz folder = %s", baz.FolderPath)
+	}
+}
+
+func TestWriteEnvFile(t *testing.T) {
+	p, store := newTestProject(t, testOverrides)
+	for _, name := range []string{"foo", "bar"} {
+		if _, err := p.Apply(p.Find(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := p.WriteEnvFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	written, err := store.ReadFile("/proj/.envrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != string(content) {
+		t.Errorf("env file holds %q, WriteEnvFile returned %q", written, content)
+	}
+
+	// A fresh project reads the same applied set back
+	reloaded := &Project{Config: p.Config, FS: store, Root: p.Root, Env: DefaultEnvironment}
+	applied, err := reloaded.ReadAppliedState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 || !applied["foo"] || !applied["bar"] {
+		t.Errorf("read back %v, want foo and bar", applied)
+	}
+
+	// Lines lazyhydra does not manage survive a save
+	store.WriteFile("/proj/.envrc", append([]byte("export OTHER=1\n"), written...), 0644)
+	p.Remove(p.Find("bar"))
+	if _, err := p.WriteEnvFile(); err != nil {
+		t.Fatal(err)
+	}
+	written, _ = store.ReadFile("/proj/.envrc")
+	if !slices.Contains(strings.Split(string(written), "\n"), "export OTHER=1") {
+		t.Errorf("env file lost an unmanaged line:\n%s", written)
+	}
+	applied, _ = reloaded.ReadAppliedState()
+	if len(applied) != 1 || !applied["foo"] {
+		t.Errorf("read back %v after removing bar, want foo", applied)
+	}
+}
+
+func TestLinkUnlink(t *testing.T) {
+	p, store := newTestProject(t, testOverrides)
+	foo := p.Find("foo")
+
+	if err := p.Link(foo); err != nil {
+		t.Fatal(err)
+	}
+	link := "/proj/conf/a/b/foo_override.yaml"
+	if got := p.SymlinkPath(foo); got != link {
+		t.Errorf("SymlinkPath() = %s, want %s", got, link)
+	}
+	target, err := store.Readlink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/proj/conf/overrides/foo/override.yaml"; target != want {
+		t.Errorf("symlink points to %s, want %s", target, want)
+	}
+
+	// Linking again replaces the symlink
+	if err := p.Link(foo); err != nil {
+		t.Errorf("linking twice: %v", err)
+	}
+
+	if err := p.Unlink(foo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Lstat(link); err == nil {
+		t.Errorf("symlink %s still exists after Unlink", link)
+	}
+
+	// A regular file in the way is not replaced
+	store.WriteFile(link, []byte("mine\n"), 0644)
+	if err := p.Link(foo); err == nil {
+		t.Error("Link replaced a regular file")
+	}
+	if err := p.Unlink(foo); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := store.ReadFile(link); string(data) != "mine\n" {
+		t.Error("Unlink removed a regular file")
+	}
+}
+
+func TestLinkDryRun(t *testing.T) {
+	p, store := newTestProject(t, testOverrides)
+	p.DryRun = true
+	if _, err := p.Apply(p.Find("foo")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Lstat("/proj/conf/a/b/foo_override.yaml"); err == nil {
+		t.Error("a dry run created a symlink")
+	}
+}
+
+func TestRename(t *testing.T) {
+	p, store := newTestProject(t, testOverrides)
+	foo := p.Find("foo")
+	if _, err := p.Apply(foo); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Rename(foo, "qux"); err != nil {
+		t.Fatal(err)
+	}
+	if foo.Name != "qux" || foo.FolderPath != "/proj/conf/overrides/qux" {
+		t.Errorf("renamed override is %s in %s", foo.Name, foo.FolderPath)
+	}
+	if p.Applied["foo"] || !p.Applied["qux"] {
+		t.Errorf("applied set after rename = %v", p.Applied)
+	}
+	if _, err := store.Stat("/proj/conf/overrides/foo"); err == nil {
+		t.Error("old folder still exists")
+	}
+	if _, err := store.Lstat("/proj/conf/a/b/foo_override.yaml"); err == nil {
+		t.Error("old symlink still exists")
+	}
+	target, err := store.Readlink("/proj/conf/a/b/qux_override.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/proj/conf/overrides/qux/override.yaml"; target != want {
+		t.Errorf("symlink points to %s, want %s", target, want)
+	}
+
+	// The renamed folder loads under its new name
+	reloaded := &Project{Config: p.Config, FS: store, Root: p.Root, Applied: make(map[string]bool)}
+	if err := reloaded.LoadOverrides(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := overrideNames(reloaded), []string{"bar", "baz", "qux"}; !slices.Equal(got, want) {
+		t.Errorf("loaded %v after rename, want %v", got, want)
+	}
+
+	// A failed rename leaves the override and its symlink in place
+	if err := p.Rename(foo, "bar"); err == nil {
+		t.Fatal("renamed onto an existing override")
+	}
+	if foo.Name != "qux" || !p.Applied["qux"] {
+		t.Errorf("failed rename changed the override to %s, applied %v", foo.Name, p.Applied)
+	}
+	if _, err := store.Readlink("/proj/conf/a/b/qux_override.yaml"); err != nil {
+		t.Errorf("failed rename lost the symlink: %v", err)
+	}
+}
+
+func TestForget(t *testing.T) {
+	p, store := newTestProject(t, testOverrides)
+	foo := p.Find("foo")
+	if _, err := p.Apply(foo); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.RemoveAll(foo.FolderPath); err != nil {
+		t.Fatal(err)
+	}
+	p.Forget(foo)
+
+	if p.Find("foo") != nil || p.Applied["foo"] {
+		t.Error("deleted override is still listed or applied")
+	}
+	if _, err := store.Lstat("/proj/conf/a/b/foo_override.yaml"); err == nil {
+		t.Error("deleted override's symlink still exists")
+	}
+	if got, want := overrideNames(p), []string{"bar", "baz"}; !slices.Equal(got, want) {
+		t.Errorf("overrides after delete = %v, want %v", got, want)
+	}
+}
+
+func TestWriteEnvFileGroupVars(t *testing.T) {
+	files := map[string]string{"train/apply.md": "---\ntype: \"++\"\nexport_group: train\n---\n"}
+	p, store := newTestProject(t, files)
+	if _, err := p.Apply(p.Find("train")); err != nil {
+		t.Fatal(err)
+	}
+	store.WriteFile("/proj/.envrc", []byte("export HYDRA_X_STR=\"keep me\"\n"), 0644)
+	if _, err := p.WriteEnvFile(); err != nil {
+		t.Fatal(err)
+	}
+	written, _ := store.ReadFile("/proj/.envrc")
+	lines := strings.Split(string(written), "\n")
+	if !slices.Contains(lines, `export HYDRA_X_STR="keep me"`) {
+		t.Errorf("env file lost a variable named like a group's:\n%s", written)
+	}
+	if !slices.ContainsFunc(lines, func(l string) bool { return strings.HasPrefix(l, "export HYDRA_TRAIN_STR=") }) {
+		t.Errorf("env file has no HYDRA_TRAIN_STR:\n%s", written)
+	}
+
+	// The variable of a group no override has any more is removed by the next save
+	p.Find("train").ExportGroup = ""
+	if _, err := p.WriteEnvFile(); err != nil {
+		t.Fatal(err)
+	}
+	written, _ = store.ReadFile("/proj/.envrc")
+	lines = strings.Split(string(written), "\n")
+	if slices.ContainsFunc(lines, func(l string) bool { return strings.HasPrefix(l, "export HYDRA_TRAIN_STR=") }) {
+		t.Errorf("env file kept the variable of a removed group:\n%s", written)
+	}
+	if !slices.Contains(lines, `export HYDRA_X_STR="keep me"`) {
+		t.Errorf("env file lost a variable named like a group's:\n%s", written)
+	}
+}
+
+func TestWriteEnvFileEnvironments(t *testing.T) {
+	p, store := newTestProject(t, testOverrides)
+	if _, err := p.Apply(p.Find("foo")); err != nil {
+		t.Fatal(err)
+	}
+	store.WriteFile("/proj/.envrc", []byte("export HYDRA_OVERRIDES_BACKUP=1\n"), 0644)
+	if _, err := p.WriteEnvFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Switching to prod keeps the default environment's set in its own variable
+	p.Env = "prod"
+	p.Applied = map[string]bool{"bar": true}
+	if _, err := p.WriteEnvFile(); err != nil {
+		t.Fatal(err)
+	}
+	written, _ := store.ReadFile("/proj/.envrc")
+	if !slices.Contains(strings.Split(string(written), "\n"), "export HYDRA_OVERRIDES_BACKUP=1") {
+		t.Errorf("env file lost a variable named like an environment's:\n%s", written)
+	}
+
+	reloaded := &Project{Config: p.Config, FS: store, Root: p.Root}
+	state, err := reloaded.ReadEnvState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Active != "prod" || len(state.Sets) != 2 {
+		t.Fatalf("read back %+v, want prod and default", state)
+	}
+	if !state.Sets["default"]["foo"] || !state.Sets["prod"]["bar"] {
+		t.Errorf("read back %+v, want foo in default and bar in prod", state.Sets)
+	}
+}
+
+func TestBuildOverridesFile(t *testing.T) {
+	files := map[string]string{
+		"gone/apply.md":       "---\ntype: \"--\"\nblock: trainer\n---\n",
+		"wandb/apply.md":      "---\ntype: \"=\"\nblock: logging\npackage: log\n---\n",
+		"wandb/override.yaml": "mode: off\n",
+	}
+	for name, content := range testOverrides {
+		files[name] = content
+	}
+	p, _ := newTestProject(t, files)
+	for _, name := range []string{"foo", "bar", "gone", "wandb"} {
+		p.Applied[name] = true
+	}
+
+	got := string(p.BuildOverridesFile())
+	for _, want := range []string{
+		"# not representable here: --trainer=gone_override\n",
+		"  - /a/b: foo_override\n",
+		"  - override /logging@log: wandb_override\n",
+		"seed: 7\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("overrides file lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "/trainer:") {
+		t.Errorf("a group deletion became a defaults entry:\n%s", got)
+	}
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// availableTags returns the tags of the available overrides, sorted, with the number of
+// overrides carrying each
+func (app *App) availableTags() ([]string, map[string]int) {
+	counts := make(map[string]int)
+	for _, o := range app.getAvailableOverrides() {
+		for _, tag := range o.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, counts
+}
+
+// showApplyTagPicker lists the tags of the available overrides; choosing one applies
+// every available override with that tag
+func (app *App) showApplyTagPicker() {
+	if !app.stateChangesAllowed("Applying") {
+		return
+	}
+	tags, counts := app.availableTags()
+	if len(tags) == 0 {
+		app.showMessage("No available override has a tag")
+		return
+	}
+
+	app.applyTagOpen = true
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+	for _, tag := range tags {
+		list.AddItem(fmt.Sprintf("  %s [darkgray](%d)[-]", tview.Escape(tag), counts[tag]), "", 0, func() {
+			app.closeApplyTagPicker()
+			app.applyTag(tag)
+		})
+	}
+
+	list.SetBorder(true).
+		SetTitle(" Apply Tag ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(tags) + 2
+	if height > 20 {
+		height = 20
+	}
+	app.pages.AddPage("apply-tag", modal(list, 50, height), true, true)
+	app.app.SetFocus(list)
+}
+
+func (app *App) closeApplyTagPicker() {
+	app.applyTagOpen = false
+	app.pages.RemovePage("apply-tag")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// applyTag applies every available override with tag, saving the state once. Like
+// applying marked overrides, incomplete ones are refused and a single parameterized
+// one asks for its values first.
+func (app *App) applyTag(tag string) {
+	var targets []*override.Override
+	for _, o := range app.getAvailableOverrides() {
+		if slices.Contains(o.Tags, tag) && !app.rejectIncomplete(o) {
+			targets = append(targets, o)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	if len(targets) == 1 && targets[0].HasParams() {
+		app.showParamsForm(targets[0])
+		return
+	}
+
+	var linkErr error
+	for _, o := range targets {
+		if err := app.applyOverride(o); err != nil {
+			linkErr = err
+		}
+		delete(app.marked, o.Name)
+	}
+	app.saveUIState()
+
+	saved := app.persistState()
+	app.refreshAll()
+	if linkErr != nil {
+		app.showError(linkErr)
+		return
+	}
+	if saved {
+		app.showMessage("Applied %d overrides tagged %s", len(targets), tag)
+	}
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ramy/lazyhydra/internal/override"
+)
+
+// hiddenArchived returns how many overrides the Available panel hides because they are
+// archived
+func (app *App) hiddenArchived() int {
+	if app.showArchived {
+		return 0
+	}
+	n := 0
+	for _, o := range app.Overrides {
+		if o.Archived && !app.Applied[o.Name] {
+			n++
+		}
+	}
+	return n
+}
+
+// archivedSuffix returns the list suffix shown after archived overrides
+func archivedSuffix(o *override.Override) string {
+	if !o.Archived {
+		return ""
+	}
+	return " [darkgray](archived)[-]"
+}
+
+// toggleShowArchived shows or hides archived overrides in the Available panel
+func (app *App) toggleShowArchived() {
+	app.showArchived = !app.showArchived
+	app.refreshAll()
+	if app.showArchived {
+		app.showMessage("Showing archived overrides")
+	} else {
+		app.showMessage("Hiding archived overrides")
+	}
+}
+
+// toggleArchived archives the marked overrides or the one under the cursor, or
+// unarchives them when the first one is archived. The flag is kept in apply.md.
+func (app *App) toggleArchived() {
+	targets := app.actionTargets()
+	if len(targets) == 0 || !app.writesAllowed("Archiving") {
+		return
+	}
+
+	archive := !targets[0].Archived
+	count := 0
+	for _, o := range targets {
+		if o.Archived == archive {
+			continue
+		}
+		content, err := app.FS.ReadFile(filepath.Join(o.FolderPath, "apply.md"))
+		if err == nil {
+			var updated string
+			if updated, err = override.SetFrontmatterFlag(string(content), "archived", archive); err == nil {
+				err = app.writeOverrideFile(o, "apply.md", []byte(updated))
+			}
+		}
+		if err != nil {
+			app.showError(fmt.Errorf("archiving %s: %w", o.Name, err))
+			continue
+		}
+		app.reloadOverride(o.Name)
+		delete(app.marked, o.Name)
+		count++
+	}
+	app.refreshAll()
+
+	verb := "Unarchived"
+	if archive {
+		verb = "Archived"
+	}
+	switch {
+	case count == 1 && len(targets) == 1:
+		app.showMessage("%s %s", verb, targets[0].Name)
+	case count > 0:
+		app.showMessage("%s %d overrides", verb, count)
+	}
+}
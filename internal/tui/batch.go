@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// batchUsage lists the commands `lazyhydra batch` understands
+const batchUsage = `commands: apply NAME..., remove NAME..., clear, snapshot save|restore NAME,
+profile save|load NAME (same as snapshot), print, status`
+
+// readBatchCommands returns the commands given with -c/--command, or else the lines of
+// r. Blank lines and lines starting with # are skipped.
+func readBatchCommands(args []string, r io.Reader) ([]string, error) {
+	var commands []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-c" || args[i] == "--command":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s needs a command", args[i])
+			}
+			i++
+			commands = append(commands, args[i])
+		case strings.HasPrefix(args[i], "--command="):
+			commands = append(commands, strings.TrimPrefix(args[i], "--command="))
+		default:
+			return nil, fmt.Errorf("unknown batch argument %q", args[i])
+		}
+	}
+	if len(commands) > 0 {
+		return commands, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			commands = append(commands, line)
+		}
+	}
+	return commands, scanner.Err()
+}
+
+// runBatchCommand executes one batch command against the in-memory state. It reports
+// whether the command changed the applied set.
+func (app *App) runBatchCommand(line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, fmt.Errorf("empty command (%s)", batchUsage)
+	}
+	command, args := fields[0], fields[1:]
+
+	switch command {
+	case "apply", "remove", "clear":
+		if app.ReadOnly {
+			return false, errReadOnly
+		}
+	case "snapshot", "profile":
+		if app.DryRun {
+			return false, fmt.Errorf("snapshots write files and are not available in dry-run mode")
+		}
+		if app.ReadOnly && len(args) > 0 && args[0] != "save" {
+			return false, errReadOnly
+		}
+	}
+
+	switch command {
+	case "apply", "remove":
+		if len(args) == 0 {
+			return false, fmt.Errorf("usage: %s NAME...", command)
+		}
+		return app.changeOverrides(command, args)
+
+	case "clear":
+		applied := app.getAppliedOverrides()
+		for _, o := range applied {
+			app.Unlink(o)
+			delete(app.Applied, o.Name)
+		}
+		missing := app.MissingApplied()
+		for _, name := range missing {
+			delete(app.Applied, name)
+		}
+		return len(applied)+len(missing) > 0, nil
+
+	case "snapshot", "profile":
+		if len(args) != 2 {
+			return false, fmt.Errorf("usage: snapshot save|restore NAME or profile save|load NAME")
+		}
+		switch args[0] {
+		case "save":
+			return false, app.saveSnapshot(args[1])
+		case "restore", "load":
+			app.warnSnapshotCommit(args[1])
+			_, err := app.restoreSnapshot(args[1])
+			return true, err
+		}
+		return false, fmt.Errorf("unknown %s command %q", command, args[0])
+
+	case "print":
+		fmt.Println(strings.ReplaceAll(app.BuildString(), "\n", " "))
+		return false, nil
+
+	case "status":
+		return false, app.printStatus(false)
+	}
+	return false, fmt.Errorf("unknown command %q (%s)", command, batchUsage)
+}
+
+// changeOverrides applies (command "apply") or removes (command "remove") the named
+// overrides against the in-memory state, as the batch commands of the same name do. It
+// reports whether the applied set changed, also when a later name fails.
+func (app *App) changeOverrides(command string, names []string) (bool, error) {
+	if app.ReadOnly {
+		return false, errReadOnly
+	}
+	changed := false
+	for _, name := range names {
+		o := app.Find(name)
+		if o == nil && command == "remove" && app.Applied[name] {
+			// A missing override is pruned from the applied state
+			delete(app.Applied, name)
+			changed = true
+			continue
+		}
+		if o == nil {
+			return changed, fmt.Errorf("override %q not found", name)
+		}
+		if command == "remove" {
+			if app.Applied[name] {
+				app.removeOverride(o)
+				changed = true
+			}
+			continue
+		}
+		if app.Applied[name] {
+			continue
+		}
+		if problems := o.LintProblems(); len(problems) > 0 {
+			return changed, fmt.Errorf("%s is incomplete: %s", name, problems[0])
+		}
+		if problems := o.HydraProblems(app.Config); len(problems) > 0 {
+			return changed, fmt.Errorf("%s: %s", name, problems[0])
+		}
+		if err := app.applyOverride(o); err != nil {
+			return true, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// runBatch handles `lazyhydra batch`, executing commands from -c flags or stdin in order
+// and saving the applied state once at the end. The first failing command stops the
+// batch and undoes its changes to the applied set, so a CI job never runs with half of
+// them.
+func (app *App) runBatch(args []string) error {
+	commands, err := readBatchCommands(args, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for i, line := range commands {
+		c, err := app.runBatchCommand(line)
+		changed = changed || c
+		if err != nil {
+			if changed {
+				app.Applied = copyApplied(app.savedApplied)
+				app.ReconcileSymlinks()
+			}
+			return fmt.Errorf("command %d (%s): %w", i+1, line, err)
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	app.saveUIState()
+	return app.savePersistedState()
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/state"
+)
+
+func TestRunBatchCommandUsage(t *testing.T) {
+	app := &App{Project: &state.Project{Applied: make(map[string]bool)}}
+	tests := []struct {
+		name, line, want string
+	}{
+		{"empty", "", "empty command"},
+		{"blank", "  \t ", "empty command"},
+		{"unknown", "frobnicate", `unknown command "frobnicate"`},
+		{"apply without names", "apply", "usage: apply NAME..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, err := app.runBatchCommand(tt.line)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("runBatchCommand(%q) = %v, want an error containing %q", tt.line, err, tt.want)
+			}
+			if changed {
+				t.Errorf("runBatchCommand(%q) reported a change", tt.line)
+			}
+		})
+	}
+}
+
+func TestChangeOverrides(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	store := fsys.NewMemory()
+	store.MkdirAll("/proj/conf/overrides/my exp", 0755)
+	store.WriteFile("/proj/conf/overrides/my exp/apply.md", []byte("---\ntype: \"++\"\n---\n"), 0644)
+	store.WriteFile("/proj/conf/overrides/my exp/override.yaml", []byte("seed: 7\n"), 0644)
+
+	cfg := config.Default()
+	cfg.OverridesDir = "/proj/conf/overrides"
+	app := &App{Project: &state.Project{Config: cfg, FS: store, Root: "/proj", Applied: make(map[string]bool)}, ui: loadUIState()}
+	if err := app.LoadOverrides(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Names are taken as given, so one with a space is a single override
+	if changed, err := app.changeOverrides("apply", []string{"my exp"}); err != nil || !changed {
+		t.Fatalf("applying %q = %v, %v", "my exp", changed, err)
+	}
+	if !app.Applied["my exp"] {
+		t.Errorf("applied set = %v, want my exp", app.Applied)
+	}
+	if changed, err := app.changeOverrides("remove", []string{"my exp"}); err != nil || !changed {
+		t.Fatalf("removing %q = %v, %v", "my exp", changed, err)
+	}
+	if _, err := app.changeOverrides("apply", []string{"my"}); err == nil {
+		t.Error("applied an override that does not exist")
+	}
+
+	app.ReadOnly = true
+	if _, err := app.changeOverrides("apply", []string{"my exp"}); err != errReadOnly {
+		t.Errorf("read-only apply = %v, want errReadOnly", err)
+	}
+}
@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// The changes the bulk editor can make to the frontmatter of each override
+const (
+	bulkSetField      = "set field"
+	bulkClearField    = "clear field"
+	bulkAddTag        = "add tag"
+	bulkRemoveTag     = "remove tag"
+	bulkReplacePrefix = "replace block prefix"
+)
+
+var bulkActions = []string{bulkSetField, bulkClearField, bulkAddTag, bulkRemoveTag, bulkReplacePrefix}
+
+// bulkFields are the frontmatter keys the bulk editor sets or clears
+var bulkFields = []string{"type", "block", "file", "module_path", "module", "package", "description", "export_group"}
+
+// bulkEdit is one change to make to the frontmatter of several overrides
+type bulkEdit struct {
+	action string
+	field  string // for set field and clear field
+	value  string // the value, tag or new block prefix
+	prefix string // the block prefix replaced by replace block prefix
+}
+
+// check reports a bulk edit that cannot be made
+func (e bulkEdit) check() error {
+	switch e.action {
+	case bulkSetField:
+		if e.field == "type" && !slices.Contains(override.Types, e.value) {
+			return fmt.Errorf("type must be one of %s", strings.Join(override.Types, " "))
+		}
+	case bulkAddTag, bulkRemoveTag:
+		if e.value == "" {
+			return fmt.Errorf("%s needs a tag", e.action)
+		}
+	case bulkReplacePrefix:
+		if e.prefix == "" {
+			return fmt.Errorf("replace block prefix needs the old prefix")
+		}
+	}
+	return nil
+}
+
+// apply returns the apply.md content of o with the edit made. changed is false when the
+// edit does not apply to o, e.g. a tag it already has or a block with another prefix.
+func (e bulkEdit) apply(o *override.Override, content string) (updated string, changed bool, err error) {
+	switch e.action {
+	case bulkSetField:
+		updated, err = override.SetFrontmatterFields(content, [][2]string{{e.field, e.value}})
+	case bulkClearField:
+		updated, err = override.RemoveFrontmatterField(content, e.field)
+	case bulkAddTag:
+		if slices.Contains(o.Tags, e.value) {
+			return content, false, nil
+		}
+		updated, err = override.SetFrontmatterTags(content, append(slices.Clone(o.Tags), e.value))
+	case bulkRemoveTag:
+		if !slices.Contains(o.Tags, e.value) {
+			return content, false, nil
+		}
+		tags := slices.DeleteFunc(slices.Clone(o.Tags), func(t string) bool { return t == e.value })
+		updated, err = override.SetFrontmatterTags(content, tags)
+	case bulkReplacePrefix:
+		if !strings.HasPrefix(o.Block, e.prefix) {
+			return content, false, nil
+		}
+		block := e.value + strings.TrimPrefix(o.Block, e.prefix)
+		updated, err = override.SetFrontmatterFields(content, [][2]string{{"block", block}})
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return updated, updated != content, nil
+}
+
+// showBulkEdit opens a form that changes one frontmatter field of the marked overrides,
+// or of the one under the cursor, instead of editing each apply.md in turn.
+func (app *App) showBulkEdit() {
+	targets := app.actionTargets()
+	if len(targets) == 0 || !app.writesAllowed("Editing frontmatter") {
+		return
+	}
+
+	app.bulkEditOpen = true
+
+	form := tview.NewForm().
+		AddDropDown("Action", bulkActions, 0, nil).
+		AddDropDown("Field", bulkFields, 0, nil).
+		AddInputField("Value", "", 40, nil, nil).
+		AddInputField("Old prefix", "", 40, nil, nil)
+
+	form.AddButton("Apply", func() {
+		_, action := form.GetFormItemByLabel("Action").(*tview.DropDown).GetCurrentOption()
+		_, field := form.GetFormItemByLabel("Field").(*tview.DropDown).GetCurrentOption()
+		edit := bulkEdit{
+			action: action,
+			field:  field,
+			value:  strings.TrimSpace(form.GetFormItemByLabel("Value").(*tview.InputField).GetText()),
+			prefix: strings.TrimSpace(form.GetFormItemByLabel("Old prefix").(*tview.InputField).GetText()),
+		}
+		if err := edit.check(); err != nil {
+			app.showError(err)
+			return
+		}
+		app.closeBulkEdit()
+		app.applyBulkEdit(targets, edit)
+	})
+	form.AddButton("Cancel", func() {
+		app.closeBulkEdit()
+	})
+
+	title := fmt.Sprintf(" Edit Frontmatter: %s ", targets[0].Name)
+	if len(targets) > 1 {
+		title = fmt.Sprintf(" Edit Frontmatter: %d overrides ", len(targets))
+	}
+
+	form.SetFieldBackgroundColor(tcell.ColorDefault).
+		SetButtonBackgroundColor(tcell.NewRGBColor(106, 159, 181))
+	form.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("bulkedit", modal(form, 60, 13), true, true)
+	app.app.SetFocus(form)
+}
+
+func (app *App) closeBulkEdit() {
+	app.bulkEditOpen = false
+	app.pages.RemovePage("bulkedit")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// applyBulkEdit writes the edit to the apply.md of each target it changes and saves the
+// applied state once if any of them is applied
+func (app *App) applyBulkEdit(targets []*override.Override, edit bulkEdit) {
+	count, reapply := 0, false
+	for _, o := range targets {
+		content, err := app.FS.ReadFile(filepath.Join(o.FolderPath, "apply.md"))
+		if err != nil {
+			app.showError(fmt.Errorf("editing %s: %w", o.Name, err))
+			continue
+		}
+		updated, changed, err := edit.apply(o, string(content))
+		if err == nil && changed {
+			err = app.writeOverrideFile(o, "apply.md", []byte(updated))
+		}
+		if err != nil {
+			app.showError(fmt.Errorf("editing %s: %w", o.Name, err))
+			continue
+		}
+		if !changed {
+			continue
+		}
+		app.reloadOverride(o.Name)
+		delete(app.marked, o.Name)
+		count++
+		reapply = reapply || app.Applied[o.Name]
+	}
+
+	saved := true
+	if reapply {
+		saved = app.persistState()
+	}
+	app.refreshAll()
+	if !saved {
+		return
+	}
+	switch {
+	case count == 0:
+		app.showMessage("No override needed the change (%s)", edit.action)
+	case count == 1 && len(targets) == 1:
+		app.showMessage("Updated the frontmatter of %s", targets[0].Name)
+	default:
+		app.showMessage("Updated the frontmatter of %d of %d overrides", count, len(targets))
+	}
+}
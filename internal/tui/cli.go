@@ -0,0 +1,362 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/ramy/lazyhydra/internal/state"
+)
+
+// hasFlag reports whether flag appears among args
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// overrideJSON is the machine-readable form of an override
+type overrideJSON struct {
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Block          string   `json:"block"`
+	File           string   `json:"file,omitempty"`
+	ModulePath     string   `json:"module_path,omitempty"`
+	Module         string   `json:"module,omitempty"`
+	Package        string   `json:"package,omitempty"`
+	Description    string   `json:"description,omitempty"`
+	Folder         string   `json:"folder"`
+	Group          string   `json:"group,omitempty"`
+	Includes       []string `json:"includes,omitempty"`
+	Archived       bool     `json:"archived,omitempty"`
+	ExportGroup    string   `json:"export_group,omitempty"`
+	Applied        bool     `json:"applied"`
+	OverrideString string   `json:"override_string"`
+}
+
+func (app *App) overrideToJSON(o *override.Override) overrideJSON {
+	return overrideJSON{
+		Name:           o.Name,
+		Type:           o.Type,
+		Block:          o.Block,
+		File:           o.File,
+		ModulePath:     o.ModulePath,
+		Module:         o.Module,
+		Package:        o.Package,
+		Description:    o.Description,
+		Folder:         o.FolderPath,
+		Group:          o.Dir,
+		Includes:       o.Includes,
+		Archived:       o.Archived,
+		ExportGroup:    o.ExportGroup,
+		Applied:        app.Applied[o.Name],
+		OverrideString: app.buildOverrideStringForOne(o),
+	}
+}
+
+// statusJSON is the machine-readable form of the applied state
+type statusJSON struct {
+	ProjectRoot    string            `json:"project_root"`
+	EnvFile        string            `json:"env_file"`
+	Environment    string            `json:"environment"`
+	Applied        []overrideJSON    `json:"applied"`
+	Missing        []string          `json:"missing,omitempty"` // applied names that match no override
+	OverrideString string            `json:"override_string"`
+	GroupStrings   map[string]string `json:"group_strings,omitempty"` // override string of each export group
+}
+
+func (app *App) buildStatusJSON() statusJSON {
+	status := statusJSON{
+		ProjectRoot:    app.Root,
+		EnvFile:        app.EnvFilePath(),
+		Environment:    app.Env,
+		Applied:        []overrideJSON{},
+		Missing:        app.MissingApplied(),
+		OverrideString: strings.ReplaceAll(app.BuildString(), "\n", " "),
+	}
+	for _, o := range app.getAppliedOverrides() {
+		status.Applied = append(status.Applied, app.overrideToJSON(o))
+	}
+	for _, group := range app.ExportGroups() {
+		if status.GroupStrings == nil {
+			status.GroupStrings = make(map[string]string)
+		}
+		status.GroupStrings[group] = strings.ReplaceAll(app.BuildGroupString(group), "\n", " ")
+	}
+	return status
+}
+
+// listJSON is the machine-readable form of every override
+type listJSON struct {
+	Overrides      []overrideJSON `json:"overrides"`
+	OverrideString string         `json:"override_string"`
+}
+
+func (app *App) buildListJSON() listJSON {
+	list := listJSON{
+		Overrides:      []overrideJSON{},
+		OverrideString: strings.ReplaceAll(app.BuildString(), "\n", " "),
+	}
+	for _, o := range app.Overrides {
+		list.Overrides = append(list.Overrides, app.overrideToJSON(o))
+	}
+	return list
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printList prints every override and whether it is applied
+func (app *App) printList(asJSON bool) error {
+	if asJSON {
+		return printJSON(app.buildListJSON())
+	}
+
+	fmt.Println("Available overrides:")
+	for _, o := range app.Overrides {
+		status := "[ ]"
+		if app.Applied[o.Name] {
+			status = "[x]"
+		}
+		archived := ""
+		if o.Archived {
+			archived = " [archived]"
+		}
+		fmt.Printf("  %s %s (type: %s, block: %s)%s\n", status, o.Name, o.Type, o.Block, archived)
+		if o.Description != "" {
+			fmt.Printf("      %s\n", o.Description)
+		}
+	}
+	if len(app.getAppliedOverrides()) > 0 {
+		fmt.Printf("\nOverride string:\n  %s\n", app.BuildString())
+	}
+	return nil
+}
+
+// printNames prints the name of every override, one per line, for shell completion
+func (app *App) printNames() {
+	for _, o := range app.Overrides {
+		fmt.Println(o.Name)
+	}
+}
+
+// printStatus prints the applied overrides and the resulting override string
+func (app *App) printStatus(asJSON bool) error {
+	if asJSON {
+		return printJSON(app.buildStatusJSON())
+	}
+
+	applied := app.getAppliedOverrides()
+	fmt.Printf("Project: %s\n", app.Root)
+	fmt.Printf("Env file: %s\n", app.EnvFilePath())
+	if missing := app.MissingApplied(); len(missing) > 0 {
+		fmt.Printf("\nMissing overrides (%d), prune with `lazyhydra batch -c \"remove NAME\"`:\n", len(missing))
+		for _, name := range missing {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+	if len(applied) == 0 {
+		fmt.Println("\nNo overrides applied")
+		return nil
+	}
+	fmt.Printf("\nApplied overrides (%d):\n", len(applied))
+	for _, o := range applied {
+		fmt.Printf("  %s\n", o.Name)
+	}
+	fmt.Printf("\nOverride string:\n  %s\n", app.BuildString())
+	return nil
+}
+
+// printPrompt prints a one-line summary of the applied state for shell prompts and status
+// bars, e.g. "⚡3 overrides" or "⚡3 overrides (dev)" outside the default environment, and
+// nothing when no override is applied so the segment disappears. Missing overrides are
+// counted with a "!". With color the summary is wrapped in ANSI escape codes.
+func (app *App) printPrompt(color bool) {
+	applied := len(app.getAppliedOverrides())
+	missing := len(app.MissingApplied())
+	if applied == 0 && missing == 0 {
+		return
+	}
+
+	noun := "overrides"
+	if applied == 1 {
+		noun = "override"
+	}
+	segment := fmt.Sprintf("⚡%d %s", applied, noun)
+	if missing > 0 {
+		segment += fmt.Sprintf(" !%d missing", missing)
+	}
+	if app.Env != "" && app.Env != state.DefaultEnvironment {
+		segment += fmt.Sprintf(" (%s)", app.Env)
+	}
+
+	if color {
+		code := "33" // yellow
+		if missing > 0 {
+			code = "31" // red
+		}
+		segment = "\x1b[" + code + "m" + segment + "\x1b[0m"
+	}
+	fmt.Println(segment)
+}
+
+// printPath prints the absolute folder of the named override, or the overrides
+// directory without a name, for `lazyhydra path`
+func (app *App) printPath(args []string) error {
+	if len(args) == 0 {
+		fmt.Println(app.Config.ExpandPath(app.Config.OverridesDir))
+		return nil
+	}
+	o := app.Find(args[0])
+	if o == nil {
+		return fmt.Errorf("override %q not found", args[0])
+	}
+	fmt.Println(o.FolderPath)
+	return nil
+}
+
+// flagValue returns the value of a --name=value or --name value flag among args
+func flagValue(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, name+"=") {
+			return strings.TrimPrefix(arg, name+"="), true
+		}
+		if arg == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// printOverrideString prints the override string for scripts. Without options it
+// keeps one override per line; --sep joins individual arguments with a separator
+// and --argv prints them shell-quoted. --group limits it to an export group.
+func (app *App) printOverrideString(args []string) error {
+	group, _ := flagValue(args, "--group")
+	if group != "" && !slices.Contains(app.ExportGroups(), group) {
+		return fmt.Errorf("no override has export_group %q", group)
+	}
+
+	if hasFlag(args, "--argv") {
+		fmt.Print(quotedArgs(app.BuildGroupArgs(group)))
+		return nil
+	}
+
+	sep, ok := flagValue(args, "--sep")
+	if !ok {
+		fmt.Print(app.BuildGroupString(group))
+		return nil
+	}
+
+	separators := map[string]string{"space": " ", "newline": "\n", "null": "\x00"}
+	separator, ok := separators[sep]
+	if !ok {
+		return fmt.Errorf("unknown separator %q (use space, newline or null)", sep)
+	}
+	fmt.Print(strings.Join(app.BuildGroupArgs(group), separator))
+	return nil
+}
+
+// shellQuote quotes s for POSIX shells, leaving it bare when it is safe as-is
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("+-=./_:@,%^", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runWithOverrides runs a command with the applied overrides injected as environment
+// variables and/or appended arguments, according to the run_inject config option.
+// It returns the command's exit code.
+func (app *App) runWithOverrides(command []string) int {
+	if len(command) > 0 && command[0] == "--" {
+		command = command[1:]
+	}
+	if len(command) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: lazyhydra run -- <command> [args...]")
+		return 2
+	}
+
+	inject := app.Config.RunInject
+	if inject != "env" && inject != "args" && inject != "both" {
+		fmt.Fprintf(os.Stderr, "Error: invalid run_inject %q (use env, args or both)\n", inject)
+		return 2
+	}
+
+	if inject == "args" || inject == "both" {
+		command = append(command, app.BuildArgs()...)
+	}
+
+	var extra []string
+	if inject == "env" || inject == "both" {
+		extra = app.overrideEnv()
+	}
+	var cmd *exec.Cmd
+	if remote != nil {
+		// A remote project's commands run in the project root on the host
+		cmd = rootCommand(app.Root, quotedArgs(command), extra, true)
+	} else {
+		cmd = exec.Command(command[0], command[1:]...)
+		cmd.Env = append(os.Environ(), extra...)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// The child receives Ctrl+C from the terminal itself; don't die before it does
+	signal.Ignore(os.Interrupt)
+
+	logging.Logger.Debug("running command", "command", command, "inject", inject)
+	if err := runLogged(cmd); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// overrideEnv returns the environment variables lazyhydra would export via the env file
+func (app *App) overrideEnv() []string {
+	var appliedNames []string
+	for _, o := range app.getAppliedOverrides() {
+		appliedNames = append(appliedNames, o.Name)
+	}
+	env := []string{
+		app.Config.EnvVarName + "=" + state.EncodeAppliedNames(appliedNames),
+		app.Config.OverrideStrVarName + "=" + strings.ReplaceAll(app.BuildString(), "\n", " "),
+	}
+	for _, v := range append(app.DerivedVars(appliedNames), app.GroupVars()...) {
+		env = append(env, v[0]+"="+v[1])
+	}
+	if app.Env != state.DefaultEnvironment {
+		env = append(env, state.ActiveEnvVar+"="+app.Env)
+	}
+	return env
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// withColumns lines up the type and block of each override after the names of a list,
+// when the columns view is on, so similar overrides can be told apart at a glance.
+// overrides holds the override of each text, nil for folder and missing rows.
+func (app *App) withColumns(texts []string, overrides []*override.Override) []string {
+	if !app.ui.Columns {
+		return texts
+	}
+	nameWidth, typeWidth := 0, 0
+	types := make([]string, len(overrides))
+	for i, o := range overrides {
+		if o == nil {
+			continue
+		}
+		if !o.IsComposite() {
+			types[i] = app.typeMarker(o.Type)
+		}
+		nameWidth = max(nameWidth, tview.TaggedStringWidth(texts[i]))
+		typeWidth = max(typeWidth, tview.TaggedStringWidth(types[i]))
+	}
+
+	for i, o := range overrides {
+		if o == nil {
+			continue
+		}
+		block := "[darkgray]" + tview.Escape(o.Block) + "[-]"
+		switch {
+		case o.IsComposite():
+			block = "[darkgray](composite)[-]"
+		case o.Block == "":
+			block = "[darkgray](value)[-]"
+		}
+		texts[i] += strings.Repeat(" ", nameWidth-tview.TaggedStringWidth(texts[i])+2) +
+			types[i] + strings.Repeat(" ", typeWidth-tview.TaggedStringWidth(types[i])+2) + block
+	}
+	return texts
+}
+
+// toggleColumns switches the type and block columns of the lists and remembers it for
+// the next session
+func (app *App) toggleColumns() {
+	app.ui.Columns = !app.ui.Columns
+	app.saveUIState()
+	app.refreshAll()
+}
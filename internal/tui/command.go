@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/rivo/tview"
+)
+
+// commandPreviewWidth is the width of the command preview, borders included
+const commandPreviewWidth = 100
+
+// quotedArgs joins Hydra arguments shell-quoted
+func quotedArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// commandData returns what command templates are rendered with for the applied overrides
+func (app *App) commandData() config.CommandData {
+	data := config.CommandData{
+		Overrides: quotedArgs(app.BuildArgs()),
+		Groups:    make(map[string]string),
+		Root:      app.Root,
+	}
+	for _, group := range app.ExportGroups() {
+		data.Groups[group] = quotedArgs(app.BuildGroupArgs(group))
+	}
+	return data
+}
+
+// shellWords splits a command into words at spaces outside quotes, keeping the quotes,
+// so it can be wrapped without breaking a quoted argument
+func shellWords(command string) []string {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ' ' || r == '\t' || r == '\n':
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			continue
+		}
+		word.WriteRune(r)
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// wrapCommand breaks a command into lines of at most width columns, continuing each
+// line with a backslash, so the wrapped command still runs when pasted into a shell.
+// A word longer than width gets a line of its own.
+func wrapCommand(command string, width int) string {
+	const indent = "    "
+	var lines []string
+	line := ""
+	for _, word := range shellWords(command) {
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word)+2 > width:
+			lines = append(lines, line+" \\")
+			line = indent + word
+		default:
+			line += " " + word
+		}
+	}
+	return strings.Join(append(lines, line), "\n")
+}
+
+// showCommandPreview shows the full command command_template renders for the applied
+// overrides, wrapped to fit, for copying into a terminal or a job script
+func (app *App) showCommandPreview() {
+	command, err := app.Config.RenderCommand(app.commandData())
+	if err != nil {
+		app.showError(fmt.Errorf("rendering command_template: %w", err))
+		return
+	}
+	app.commandOpen = true
+	app.previewCommand = command
+
+	text := fmt.Sprintf("[yellow]%s[-]\n\n[darkgray]From command_template: %s[-]",
+		tview.Escape(wrapCommand(command, commandPreviewWidth-4)), tview.Escape(app.Config.CommandTemplate))
+	commandText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true).
+		SetText(text)
+
+	commandText.SetBorder(true).
+		SetTitle(" Command ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := min(strings.Count(text, "\n")+3, 30)
+	app.pages.AddPage("command", modal(commandText, commandPreviewWidth, height), true, true)
+	app.app.SetFocus(commandText)
+}
+
+func (app *App) closeCommandPreview() {
+	app.commandOpen = false
+	app.pages.RemovePage("command")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// copyCommand copies the previewed command, on one line
+func (app *App) copyCommand() {
+	if err := copyToClipboard(app.previewCommand); err != nil {
+		app.showError(err)
+		return
+	}
+	app.closeCommandPreview()
+	app.showMessage("Copied the command (%d characters)", len(app.previewCommand))
+}
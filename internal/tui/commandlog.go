@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// maxCommandLog is the number of external commands kept in the command log
+const maxCommandLog = 200
+
+// commandEntry records an external command lazyhydra executed
+type commandEntry struct {
+	Time     time.Time
+	Args     []string
+	ExitCode int // -1 when the command could not be started or was killed
+	Duration time.Duration
+	Err      error
+}
+
+// commandLog collects every external command lazyhydra runs, for transparency
+type commandLog struct {
+	mu      sync.Mutex
+	entries []commandEntry
+	changed chan struct{} // signaled when a command is recorded, without waiting for a reader
+}
+
+// commands is the process-wide command log
+var commands = &commandLog{changed: make(chan struct{}, 1)}
+
+// record adds a finished command to the log
+func (l *commandLog) record(cmd *exec.Cmd, started time.Time, err error) {
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+
+	l.mu.Lock()
+	l.entries = append(l.entries, commandEntry{
+		Time:     started,
+		Args:     cmd.Args,
+		ExitCode: exitCode,
+		Duration: time.Since(started),
+		Err:      err,
+	})
+	if len(l.entries) > maxCommandLog {
+		l.entries = l.entries[len(l.entries)-maxCommandLog:]
+	}
+	l.mu.Unlock()
+
+	// A signal still pending covers this command too
+	select {
+	case l.changed <- struct{}{}:
+	default:
+	}
+}
+
+// snapshot returns a copy of the logged commands, oldest first
+func (l *commandLog) snapshot() []commandEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]commandEntry(nil), l.entries...)
+}
+
+// runLogged runs cmd and records it in the command log
+func runLogged(cmd *exec.Cmd) error {
+	started := time.Now()
+	err := cmd.Run()
+	commands.record(cmd, started, err)
+	return err
+}
+
+// outputLogged runs cmd, returning its stdout, and records it in the command log
+func outputLogged(cmd *exec.Cmd) ([]byte, error) {
+	started := time.Now()
+	output, err := cmd.Output()
+	commands.record(cmd, started, err)
+	return output, err
+}
+
+// combinedOutputLogged runs cmd, returning stdout and stderr, and records it in the command log
+func combinedOutputLogged(cmd *exec.Cmd) ([]byte, error) {
+	started := time.Now()
+	output, err := cmd.CombinedOutput()
+	commands.record(cmd, started, err)
+	return output, err
+}
+
+// formatCommandLog renders the command log with tview color tags
+func formatCommandLog(entries []commandEntry) string {
+	if len(entries) == 0 {
+		return "[darkgray]No commands run yet[-]"
+	}
+
+	var b strings.Builder
+	for _, entry := range entries {
+		status := "[green]✓[-]"
+		if entry.ExitCode != 0 {
+			status = "[red]✗[-]"
+		}
+		fmt.Fprintf(&b, "[darkgray]%s[-] %s %s [darkgray](exit %d, %s)[-]\n",
+			entry.Time.Format("15:04:05"), status,
+			tview.Escape(strings.Join(entry.Args, " ")),
+			entry.ExitCode, entry.Duration.Round(time.Millisecond))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// toggleCommandLog shows or hides the command log panel below the override string
+func (app *App) toggleCommandLog() {
+	app.commandLogShown = !app.commandLogShown
+	if app.commandLogShown {
+		app.rightFlex.AddItem(app.commandLogView, 0, 1, false)
+		app.refreshCommandLog()
+	} else {
+		app.rightFlex.RemoveItem(app.commandLogView)
+	}
+}
+
+func (app *App) refreshCommandLog() {
+	if !app.commandLogShown {
+		return
+	}
+	app.commandLogView.SetText(formatCommandLog(commands.snapshot()))
+	app.commandLogView.ScrollToEnd()
+}
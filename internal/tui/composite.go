@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// appliedRow is one line of the Applied panel: an override, nested under its composite
+// when it was applied as part of one, the name of a missing override, or the header of
+// a block when the panel is grouped by block
+type appliedRow struct {
+	override *override.Override
+	depth    int
+	missing  string // applied name that matches no override; override is nil
+	group    string // block of a header row; override is nil
+}
+
+// buildAppliedRows lays out the applied overrides, listing the members of each applied
+// composite under it unless the composite is collapsed.
+func (app *App) buildAppliedRows() []appliedRow {
+	applied := app.getAppliedOverrides()
+	if app.ui.GroupApplied {
+		return append(app.groupAppliedRows(applied), app.missingRows()...)
+	}
+
+	// Each member is shown under the first applied composite that includes it
+	parent := make(map[string]*override.Override)
+	for _, o := range applied {
+		for _, m := range o.Members() {
+			if app.Applied[m.Name] && parent[m.Name] == nil {
+				parent[m.Name] = o
+			}
+		}
+	}
+
+	var rows []appliedRow
+	for _, o := range applied {
+		if parent[o.Name] != nil {
+			continue
+		}
+		rows = append(rows, appliedRow{override: o})
+		if !o.IsComposite() || app.collapsedComposites[o.Name] {
+			continue
+		}
+		for _, m := range applied {
+			if parent[m.Name] == o {
+				rows = append(rows, appliedRow{override: m, depth: 1})
+			}
+		}
+	}
+	return append(rows, app.missingRows()...)
+}
+
+// formatAppliedRow returns the list text for a row of the Applied panel
+func (app *App) formatAppliedRow(row appliedRow) string {
+	if row.missing != "" {
+		return formatMissingRow(row)
+	}
+	if row.group != "" {
+		return app.formatGroupRow(row.group)
+	}
+	o := row.override
+	marker := app.typeMarker(o.Type) + " "
+	if o.IsComposite() {
+		marker = "[blue]▾[-] "
+		if app.collapsedComposites[o.Name] {
+			marker = "[blue]▸[-] "
+		}
+	}
+	name := o.Name
+	if o.Dir != "" {
+		name = "[darkgray]" + o.Dir + "/[-]" + o.Name
+	}
+	return strings.Repeat("  ", row.depth) + app.markPrefix(o) + incompletePrefix(o) + app.conflictPrefix(o) +
+		marker + app.pinPrefix(o) + app.iconPrefix(o) + name + compositeSuffix(o)
+}
+
+// compositeSuffix returns the list suffix shown after composite overrides
+func compositeSuffix(o *override.Override) string {
+	if !o.IsComposite() {
+		return ""
+	}
+	return fmt.Sprintf(" [darkgray](%d overrides)[-]", len(o.Includes))
+}
+
+// formatComposite lists the overrides a composite includes with their override strings
+func (app *App) formatComposite(o *override.Override) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[cyan::b]# %s includes[-:-:-]\n\n", o.Name)
+	for _, m := range o.Members() {
+		fmt.Fprintf(&b, "  %s%s [darkgray]%s[-]\n", incompletePrefix(m), m.Name, tview.Escape(app.buildOverrideStringForOne(m)))
+	}
+	for _, problem := range o.BadIncludes() {
+		fmt.Fprintf(&b, "  [red]✗ %s[-]\n", tview.Escape(problem))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// toggleFold collapses or expands the folder or composite under the cursor
+func (app *App) toggleFold() {
+	if dir, ok := app.selectedFolder(); ok {
+		app.toggleFolder(dir)
+		return
+	}
+	if app.currentPanelIdx != 1 {
+		return
+	}
+	o := app.getSelectedOverride()
+	if o == nil || !o.IsComposite() {
+		return
+	}
+	if app.collapsedComposites[o.Name] {
+		delete(app.collapsedComposites, o.Name)
+	} else {
+		app.collapsedComposites[o.Name] = true
+	}
+	app.refreshAll()
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// blockConflicts returns the blocks targeted by more than one applied override, each
+// with its overrides in the order they appear in the override string.
+func (app *App) blockConflicts() map[string][]*override.Override {
+	byBlock := make(map[string][]*override.Override)
+	for _, o := range app.Overrides {
+		if app.Applied[o.Name] && o.Block != "" {
+			byBlock[o.Block] = append(byBlock[o.Block], o)
+		}
+	}
+	for block, list := range byBlock {
+		if len(list) < 2 {
+			delete(byBlock, block)
+		}
+	}
+	return byBlock
+}
+
+// hasConflict reports whether an applied override shares its block with another one
+func (app *App) hasConflict(o *override.Override) bool {
+	return len(app.blockConflicts()[o.Block]) > 1
+}
+
+// conflictPrefix returns the list prefix shown before conflicting applied overrides
+func (app *App) conflictPrefix(o *override.Override) string {
+	if app.Applied[o.Name] && app.hasConflict(o) {
+		return "[red]![-] "
+	}
+	return ""
+}
+
+// explainConflict describes how Hydra handles several overrides of the same block
+func (app *App) explainConflict(list []*override.Override) string {
+	appends := 0
+	for _, o := range list {
+		if o.Type == "+" {
+			appends++
+		}
+	}
+	last := list[len(list)-1]
+
+	switch {
+	case appends > 1:
+		return "Each " + app.typeMarker("+") + " override adds the config group to the defaults list. Hydra rejects the same group being added more than once, so the command will fail. Remove all but one of them."
+	case appends == 1:
+		return fmt.Sprintf("The %s override adds the config group, and %s overrides replace its value. Hydra applies overrides left to right, so [::b]%s[::-] wins if the group can be both added and overridden; otherwise the command fails.",
+			app.typeMarker("+"), app.typeMarker("="), last.Name)
+	default:
+		return fmt.Sprintf("Hydra applies overrides left to right, so the last one, [::b]%s[::-], wins and the others have no effect.", last.Name)
+	}
+}
+
+// showConflicts shows which applied overrides target the same block and which one wins
+func (app *App) showConflicts() {
+	conflicts := app.blockConflicts()
+	if len(conflicts) == 0 {
+		app.showMessage("No conflicting overrides")
+		return
+	}
+
+	app.blockConflictsOpen = true
+
+	var blocks []string
+	for block := range conflicts {
+		blocks = append(blocks, block)
+	}
+	sort.Strings(blocks)
+
+	var b strings.Builder
+	for _, block := range blocks {
+		list := conflicts[block]
+		fmt.Fprintf(&b, "[yellow::b]%s[-:-:-]\n", block)
+		for i, o := range list {
+			note := ""
+			if i == len(list)-1 {
+				note = "  [darkgray](last in the override string)[-]"
+			}
+			fmt.Fprintf(&b, "  %d. %s %s%s\n", i+1, app.typeMarker(o.Type), o.Name, note)
+			fmt.Fprintf(&b, "     [darkgray]%s[-]\n", tview.Escape(app.buildOverrideStringForOne(o)))
+		}
+		fmt.Fprintf(&b, "\n%s\n\n", app.explainConflict(list))
+	}
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true).
+		SetText(strings.TrimRight(b.String(), "\n"))
+	view.SetBorder(true).
+		SetTitle(" Conflicting Overrides ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorRed)
+
+	app.pages.AddPage("conflicts", modal(view, 80, 22), true, true)
+	app.app.SetFocus(view)
+}
+
+func (app *App) closeConflicts() {
+	app.blockConflictsOpen = false
+	app.pages.RemovePage("conflicts")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// typeNames describe the override types in the details header
+var typeNames = map[string]string{"+": "merge", "=": "replace", "++": "set", "--": "delete"}
+
+// detailTimeFormat is how times are shown in the details header
+const detailTimeFormat = "2006-01-02 15:04"
+
+// formatDetails returns the header shown above an override's content: its metadata from
+// apply.md, where it lives and when it was changed and applied. Empty fields are left out.
+func (app *App) formatDetails(o *override.Override) string {
+	var rows [][2]string
+	add := func(label, value string) {
+		if value != "" {
+			rows = append(rows, [2]string{label, value})
+		}
+	}
+
+	if o.Type != "" {
+		typ := app.typeMarker(o.Type)
+		if name := typeNames[o.Type]; name != "" && app.Config.Markers.Style != config.MarkerStyleBadges {
+			typ += " " + name
+		}
+		add("Type", typ)
+	}
+	add("Block", tview.Escape(o.Block))
+	add("File", tview.Escape(o.File))
+	add("Module", tview.Escape(strings.Trim(o.ModulePath+"/"+o.Module, "/")))
+	add("Package", tview.Escape(o.Package))
+	add("Folder", tview.Escape(homeRelative(o.FolderPath)))
+	if !o.Modified.IsZero() {
+		add("Modified", o.Modified.Format(detailTimeFormat))
+	}
+	if app.Applied[o.Name] {
+		applied := "yes"
+		if t, ok := app.ui.LastApplied[o.FolderPath]; ok {
+			applied = "since " + t.Format(detailTimeFormat)
+		}
+		add("Applied", applied)
+	}
+	add("Tags", tview.Escape(strings.Join(o.Tags, ", ")))
+	if o.ExportGroup != "" {
+		add("Exported", tview.Escape(app.Config.GroupVar(o.ExportGroup)))
+	}
+	if o.Archived {
+		add("Archived", "yes")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[::b]%s[::-]", tview.Escape(o.Name))
+	if o.Description != "" {
+		fmt.Fprintf(&b, " [darkgray]%s[-]", tview.Escape(o.Description))
+	}
+	for _, row := range rows {
+		fmt.Fprintf(&b, "\n[darkgray]%-9s[-] %s", row[0], row[1])
+	}
+	return b.String()
+}
+
+// homeRelative abbreviates a path inside the home directory with ~
+func homeRelative(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// diffLine is one line of a line-based diff. Op is '+', '-' or ' '.
+type diffLine struct {
+	Op   byte
+	Text string
+}
+
+// lineDiff computes a line-based diff between two texts using the longest common subsequence.
+func lineDiff(oldText, newText string) []diffLine {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	// lcs[i][j] is the LCS length of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var result []diffLine
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			result = append(result, diffLine{' ', oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, diffLine{'-', oldLines[i]})
+			i++
+		default:
+			result = append(result, diffLine{'+', newLines[j]})
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		result = append(result, diffLine{'-', oldLines[i]})
+	}
+	for ; j < len(newLines); j++ {
+		result = append(result, diffLine{'+', newLines[j]})
+	}
+	return result
+}
+
+// splitLines splits text into lines, ignoring a single trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffChanged reports whether a diff contains any additions or removals
+func diffChanged(diff []diffLine) bool {
+	for _, line := range diff {
+		if line.Op != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDiff renders a diff as plain text with +/- prefixes
+func formatDiff(diff []diffLine) string {
+	var b strings.Builder
+	for _, line := range diff {
+		fmt.Fprintf(&b, "%c %s\n", line.Op, line.Text)
+	}
+	return b.String()
+}
+
+// formatDiffColored renders a diff with tview color tags
+func formatDiffColored(diff []diffLine) string {
+	var b strings.Builder
+	for _, line := range diff {
+		text := tview.Escape(line.Text)
+		switch line.Op {
+		case '+':
+			fmt.Fprintf(&b, "[green]+ %s[-]\n", text)
+		case '-':
+			fmt.Fprintf(&b, "[red]- %s[-]\n", text)
+		default:
+			fmt.Fprintf(&b, "[darkgray]  %s[-]\n", text)
+		}
+	}
+	return b.String()
+}
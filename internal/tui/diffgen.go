@@ -0,0 +1,394 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAMLMapping parses a YAML document whose root must be a mapping. An empty
+// document is treated as an empty mapping.
+func parseYAMLMapping(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("root is not a YAML mapping")
+	}
+	return root, nil
+}
+
+// yamlDelta returns the parts of modified that differ from base: keys that were added or
+// whose value changed, descending into mappings present in both. The modified file's key
+// order and comments are kept. It returns nil when nothing changed.
+func yamlDelta(base, modified *yaml.Node) *yaml.Node {
+	if base.Kind == yaml.MappingNode && modified.Kind == yaml.MappingNode {
+		baseValues := make(map[string]*yaml.Node)
+		for i := 0; i+1 < len(base.Content); i += 2 {
+			baseValues[base.Content[i].Value] = base.Content[i+1]
+		}
+
+		delta := &yaml.Node{Kind: yaml.MappingNode, Tag: modified.Tag}
+		for i := 0; i+1 < len(modified.Content); i += 2 {
+			key, value := modified.Content[i], modified.Content[i+1]
+			baseValue, ok := baseValues[key.Value]
+			if !ok {
+				delta.Content = append(delta.Content, key, value)
+				continue
+			}
+			if d := yamlDelta(baseValue, value); d != nil {
+				delta.Content = append(delta.Content, key, d)
+			}
+		}
+		if len(delta.Content) == 0 {
+			return nil
+		}
+		return delta
+	}
+
+	var baseValue, modifiedValue interface{}
+	base.Decode(&baseValue)
+	modified.Decode(&modifiedValue)
+	if reflect.DeepEqual(baseValue, modifiedValue) {
+		return nil
+	}
+	return modified
+}
+
+// removedKeys lists the dotted paths of keys in base that modified no longer has. A merge
+// override cannot delete keys, so these are reported rather than written.
+func removedKeys(prefix string, base, modified *yaml.Node) []string {
+	if base.Kind != yaml.MappingNode || modified.Kind != yaml.MappingNode {
+		return nil
+	}
+	modifiedValues := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(modified.Content); i += 2 {
+		modifiedValues[modified.Content[i].Value] = modified.Content[i+1]
+	}
+
+	var removed []string
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		key := base.Content[i].Value
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		value, ok := modifiedValues[key]
+		if !ok {
+			removed = append(removed, path)
+			continue
+		}
+		removed = append(removed, removedKeys(path, base.Content[i+1], value)...)
+	}
+	return removed
+}
+
+// diffYAML returns the override.yaml content holding only what modified changes relative
+// to base, or "" when they are equal, and the keys modified removes.
+func diffYAML(base, modified []byte) (string, []string, error) {
+	baseRoot, err := parseYAMLMapping(base)
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing base: %w", err)
+	}
+	modifiedRoot, err := parseYAMLMapping(modified)
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing modified: %w", err)
+	}
+
+	removed := removedKeys("", baseRoot, modifiedRoot)
+	delta := yamlDelta(baseRoot, modifiedRoot)
+	if delta == nil {
+		return "", removed, nil
+	}
+
+	content, err := encodeYAML(delta)
+	if err != nil {
+		return "", nil, err
+	}
+	return content, removed, nil
+}
+
+// blockBaseConfig returns the config file an override with a block is merged into: the
+// override's file when set, else the option the primary config's defaults list selects
+// for the block's config group.
+func (app *App) blockBaseConfig(o *override.Override) (string, error) {
+	if o.Block == "" {
+		return "", fmt.Errorf("%s is a value override and has no base config", o.Name)
+	}
+	hydraDir := app.Config.ExpandPath(app.Config.HydraConfigsDir)
+	group := strings.ReplaceAll(o.Block, ".", "/")
+	blockDir := filepath.Join(hydraDir, filepath.FromSlash(group))
+
+	if o.File != "" {
+		file := o.File
+		if filepath.Ext(file) == "" {
+			file += ".yaml"
+		}
+		return filepath.Join(blockDir, file), nil
+	}
+
+	primary := app.loadYAMLMap(filepath.Join(hydraDir, app.Config.PrimaryConfig+".yaml"))
+	defaults, _ := primary["defaults"].([]interface{})
+	for _, entry := range defaults {
+		d, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if option, ok := d[group].(string); ok {
+			return filepath.Join(blockDir, option+".yaml"), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no file and %s is not in the defaults list of %s.yaml", o.Name, group, app.Config.PrimaryConfig)
+}
+
+// writeDiffOverride creates an override at path (see newOverridePath) with the given
+// frontmatter and override.yaml content, which gets a # @package header when meta sets
+// a package.
+func (app *App) writeDiffOverride(path string, meta override.Meta, content string) (string, error) {
+	overridePath, name, _, err := app.newOverridePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	fields := [][2]string{
+		{"type", meta.Type},
+		{"block", meta.Block},
+		{"file", meta.File},
+		{"module_path", meta.ModulePath},
+		{"module", meta.Module},
+		{"description", meta.Description},
+	}
+	if meta.Package != "" {
+		fields = append(fields, [2]string{"package", meta.Package})
+		content = override.WithPackageHeader(content, meta.Package)
+	}
+	applyContent, err := override.SetFrontmatterFields("---\n---\n", fields)
+	if err != nil {
+		return "", err
+	}
+
+	if err := app.FS.MkdirAll(overridePath, 0755); err != nil {
+		return "", err
+	}
+	if err := app.FS.WriteFile(filepath.Join(overridePath, "override.yaml"), []byte(content), 0644); err != nil {
+		return "", err
+	}
+	if err := app.FS.WriteFile(filepath.Join(overridePath, "apply.md"), []byte(applyContent), 0644); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// runDiffgenCommand handles `lazyhydra diffgen base.yaml modified.yaml --name NAME`,
+// creating a merge override with the keys modified.yaml changes. The block is taken from
+// --block, or from where base.yaml sits in hydra_configs_dir, and the package from
+// --package or base.yaml's # @package header.
+func (app *App) runDiffgenCommand(args []string) error {
+	usage := fmt.Errorf("usage: lazyhydra diffgen base.yaml modified.yaml --name NAME [--block BLOCK] [--package PACKAGE]")
+
+	var files []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--name" || args[i] == "--block" || args[i] == "--package":
+			i++
+		case strings.HasPrefix(args[i], "--"):
+		default:
+			files = append(files, args[i])
+		}
+	}
+	name, _ := flagValue(args, "--name")
+	if len(files) != 2 || name == "" {
+		return usage
+	}
+	if app.DryRun || app.ReadOnly {
+		return fmt.Errorf("diffgen creates an override and is not available in dry-run or read-only mode")
+	}
+
+	basePath, modifiedPath := files[0], files[1]
+	base, err := app.FS.ReadFile(basePath)
+	if err != nil {
+		return err
+	}
+	modified, err := app.FS.ReadFile(modifiedPath)
+	if err != nil {
+		return err
+	}
+	content, removed, err := diffYAML(base, modified)
+	if err != nil {
+		return err
+	}
+	for _, key := range removed {
+		fmt.Fprintf(os.Stderr, "Warning: %s is removed in %s; a merge override cannot remove keys\n", key, modifiedPath)
+	}
+	if content == "" {
+		return fmt.Errorf("%s has no changes relative to %s", modifiedPath, basePath)
+	}
+
+	block, ok := flagValue(args, "--block")
+	if !ok {
+		absBase, _ := filepath.Abs(basePath)
+		rel, err := filepath.Rel(app.Config.ExpandPath(app.Config.HydraConfigsDir), filepath.Dir(absBase))
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("%s is not in a config group of %s; pass --block", basePath, app.Config.HydraConfigsDir)
+		}
+		block = strings.ReplaceAll(filepath.ToSlash(rel), "/", ".")
+	}
+
+	pkg, ok := flagValue(args, "--package")
+	if !ok {
+		pkg = override.ContentPackage(string(base))
+	}
+
+	meta := override.Meta{
+		Type:        "+",
+		Block:       block,
+		File:        filepath.Base(basePath),
+		Package:     pkg,
+		Description: "Changes from " + filepath.Base(modifiedPath),
+	}
+	created, err := app.writeDiffOverride(name, meta, content)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created %s with %d changed keys\n", created, len(override.FlattenYAML(content)))
+	return nil
+}
+
+// showDiffgen opens a copy of the selected override's base config in the editor and
+// offers to save the changes made to it as a new merge override for the same block.
+func (app *App) showDiffgen() {
+	o := app.getSelectedOverride()
+	if o == nil || !app.writesAllowed("Generating overrides") {
+		return
+	}
+	basePath, err := app.blockBaseConfig(o)
+	if err != nil {
+		app.showError(err)
+		return
+	}
+	base, err := app.FS.ReadFile(basePath)
+	if err != nil {
+		app.showError(err)
+		return
+	}
+
+	tmp, err := os.CreateTemp("", "lazyhydra-*-"+filepath.Base(basePath))
+	if err != nil {
+		app.showError(err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(base)
+	tmp.Close()
+	if err != nil {
+		app.showError(err)
+		return
+	}
+
+	if err := app.runEditor(tmp.Name()); err != nil {
+		app.showError(err)
+		return
+	}
+	modified, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		app.showError(err)
+		return
+	}
+	content, removed, err := diffYAML(base, modified)
+	if err != nil {
+		app.showError(err)
+		return
+	}
+
+	rel, _ := filepath.Rel(app.Config.ExpandPath(app.Config.HydraConfigsDir), basePath)
+	rel = filepath.ToSlash(rel)
+	if content == "" {
+		app.showMessage("No changes to %s", rel)
+		return
+	}
+
+	meta := override.Meta{
+		Type:        "+",
+		Block:       o.Block,
+		File:        filepath.Base(basePath),
+		ModulePath:  o.ModulePath,
+		Module:      o.Module,
+		Package:     o.Package,
+		Description: "Changes to " + rel,
+	}
+	if meta.Package == "" {
+		meta.Package = override.ContentPackage(string(base))
+	}
+	app.showDiffgenNameInput(o, meta, content, removed)
+}
+
+// showDiffgenNameInput asks for the name of the override generated by showDiffgen
+func (app *App) showDiffgenNameInput(o *override.Override, meta override.Meta, content string, removed []string) {
+	app.diffgenOpen = true
+
+	prefill := ""
+	if o.Dir != "" {
+		prefill = o.Dir + "/"
+	}
+	inputField := tview.NewInputField().
+		SetLabel("Name: ").
+		SetText(prefill).
+		SetFieldWidth(40).
+		SetFieldBackgroundColor(tcell.ColorDefault)
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		path := strings.TrimSpace(inputField.GetText())
+		app.closeDiffgenInput()
+		if key != tcell.KeyEnter || path == "" || strings.HasSuffix(path, "/") {
+			return
+		}
+
+		name, err := app.writeDiffOverride(path, meta, content)
+		if err != nil {
+			app.showError(err)
+			return
+		}
+		app.Overrides = nil
+		if err := app.LoadOverrides(); err != nil {
+			app.showError(err)
+			return
+		}
+		if app.watcher != nil {
+			app.watchOverrideDirs()
+		}
+		app.refreshAll()
+
+		if len(removed) > 0 {
+			app.showMessage("Created %s; a merge override cannot remove %s", name, strings.Join(removed, ", "))
+			return
+		}
+		app.showMessage("Created %s with %d changed keys", name, len(override.FlattenYAML(content)))
+	})
+
+	inputField.SetBorder(true).
+		SetTitle(fmt.Sprintf(" New override from changes to %s ", meta.File)).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("diffgen", modal(inputField, 60, 3), true, true)
+	app.app.SetFocus(inputField)
+}
+
+func (app *App) closeDiffgenInput() {
+	app.diffgenOpen = false
+	app.pages.RemovePage("diffgen")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
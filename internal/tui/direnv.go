@@ -0,0 +1,218 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/rivo/tview"
+)
+
+// errDirenvMissing means the program direnv_command starts is not installed. Saving
+// still succeeded, so it is reported as a warning.
+var errDirenvMissing = errors.New("is not installed")
+
+// runDirenvCommand runs direnv_command in the project root after the env file was
+// written, so the new values load right away. It does nothing unless env_format is
+// direnv and the command is set.
+func runDirenvCommand(cfg *config.Config, root string) error {
+	command := strings.TrimSpace(cfg.DirenvCommand)
+	if cfg.EnvFormat != config.EnvFormatDirenv || command == "" {
+		return nil
+	}
+
+	program := strings.Fields(command)[0]
+	if _, err := exec.LookPath(program); err != nil && remote == nil {
+		return fmt.Errorf("%s %w; install it, change direnv_command or pass --no-direnv", program, errDirenvMissing)
+	}
+	cmd := rootCommand(root, command, nil, false)
+	output, err := combinedOutputLogged(cmd)
+	logging.Logger.Debug("ran direnv_command", "dir", root, "command", command, "output", string(output), "error", err)
+	if err != nil {
+		if out := strings.TrimSpace(string(output)); out != "" {
+			err = fmt.Errorf("%w: %s", err, out)
+		}
+		return fmt.Errorf("running %s: %w", command, err)
+	}
+	return nil
+}
+
+// direnvProblem is what keeps direnv from loading the env file into the shell
+type direnvProblem int
+
+const (
+	direnvOK           direnvProblem = iota
+	direnvNotInstalled               // direnv_command runs direnv, which is not installed
+	direnvBlocked                    // the env file is not allowed: changed since the last allow, or denied
+)
+
+// direnvAllowed asks `direnv status` in root whether the env file there is allowed.
+// found is false when direnv did not report an env file.
+func direnvAllowed(root string) (allowed, found bool, err error) {
+	cmd := rootCommand(root, "direnv status", nil, false)
+	output, err := outputLogged(cmd)
+	if err != nil {
+		return false, false, err
+	}
+	// Older direnv prints "allowed true", newer prints "allowed 0" for an allowed file
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.HasPrefix(line, "Found RC allowed ") {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(line, "Found RC allowed "))
+		return value == "true" || value == "0", true, nil
+	}
+	return false, false, nil
+}
+
+// checkDirenv reports whether direnv will load the env file in root. Only the direnv
+// env format is checked, and only when direnv_command runs direnv or is empty.
+func checkDirenv(cfg *config.Config, root string) direnvProblem {
+	if cfg.EnvFormat != config.EnvFormatDirenv {
+		return direnvOK
+	}
+	command := strings.Fields(cfg.DirenvCommand)
+	if len(command) > 0 && command[0] != "direnv" {
+		return direnvOK
+	}
+	if _, err := exec.LookPath("direnv"); err != nil && remote == nil {
+		// With an empty direnv_command the env file may be loaded some other way
+		if len(command) == 0 {
+			return direnvOK
+		}
+		return direnvNotInstalled
+	}
+	if _, err := projectStore(root).Stat(cfg.EnvFilePath(root)); err != nil {
+		return direnvOK
+	}
+	allowed, found, err := direnvAllowed(root)
+	if err != nil || !found {
+		logging.Logger.Debug("could not check direnv status", "dir", root, "error", err)
+		return direnvOK
+	}
+	if !allowed {
+		return direnvBlocked
+	}
+	return direnvOK
+}
+
+// updateDirenvHealth checks direnv again for the status bar indicator. After a save,
+// a problem that was not there before is also reported in the status bar.
+func (app *App) updateDirenvHealth(afterSave bool) {
+	if app.DryRun {
+		return
+	}
+	previous := app.direnvProblem
+	app.direnvProblem = checkDirenv(app.Config, app.Root)
+	if app.direnvOpen {
+		app.direnvText.SetText(app.formatDirenvHealth())
+	}
+	if !afterSave || app.direnvProblem == direnvOK || app.direnvProblem == previous {
+		return
+	}
+	// Shown after the message of the action that saved
+	message := "Warning: direnv is not installed, so the overrides do not reach your shell (Ctrl+E for details)"
+	if app.direnvProblem == direnvBlocked {
+		message = fmt.Sprintf("Warning: direnv has not allowed %s, so your shell keeps the old overrides (Ctrl+E for details)", app.Config.ProjectEnvFile)
+	}
+	go app.app.QueueUpdateDraw(func() {
+		app.setStatusMessage(message, true)
+	})
+}
+
+// direnvBadge returns the status bar indicator for a direnv problem
+func (app *App) direnvBadge() string {
+	switch app.direnvProblem {
+	case direnvNotInstalled:
+		return "[black:yellow] NO DIRENV [-:-] "
+	case direnvBlocked:
+		return "[black:yellow] DIRENV BLOCKED [-:-] "
+	}
+	return ""
+}
+
+// formatDirenvHealth explains the direnv problem and how to fix it
+func (app *App) formatDirenvHealth() string {
+	envPath := tview.Escape(app.EnvFilePath())
+	root := tview.Escape(app.Root)
+
+	var b strings.Builder
+	switch app.direnvProblem {
+	case direnvOK:
+		fmt.Fprintf(&b, "[green]direnv loads %s; no problem found.[-]\n\n", envPath)
+		b.WriteString("If your shell still does not see the overrides, check that the direnv hook is in your shell's startup file, e.g. eval \"$(direnv hook zsh)\".\n")
+	case direnvNotInstalled:
+		b.WriteString("[yellow::b]direnv is not installed[-:-:-]\n\n")
+		fmt.Fprintf(&b, "The overrides are saved to %s, but without direnv nothing exports them to your shell, so commands there still run with the old overrides.\n\n", envPath)
+		b.WriteString("[green]To fix it, either:[-]\n")
+		b.WriteString("  - install direnv (https://direnv.net) and add its hook to your shell, e.g. eval \"$(direnv hook zsh)\"\n")
+		b.WriteString("  - set env_format to dotenv or powershell in config.yaml and load the file yourself\n")
+		b.WriteString("  - set direnv_command to \"\" in config.yaml if the file is loaded some other way\n")
+	case direnvBlocked:
+		fmt.Fprintf(&b, "[yellow::b]direnv has not allowed %s[-:-:-]\n\n", envPath)
+		b.WriteString("direnv refuses to load an env file that changed since it was last allowed, or that was denied, so your shell keeps the overrides from before. ")
+		switch {
+		case app.noDirenvFlag:
+			b.WriteString("lazyhydra was started with --no-direnv, so it does not allow the file after saving.\n\n")
+		case strings.TrimSpace(app.Config.DirenvCommand) == "":
+			b.WriteString("direnv_command is empty in config.yaml, so lazyhydra does not allow the file after saving.\n\n")
+		default:
+			b.WriteString("Running direnv_command after the last save did not allow it; see the error log (!).\n\n")
+		}
+		b.WriteString("[green]To fix it:[-]\n")
+		b.WriteString("  - press a to run direnv allow now\n")
+		fmt.Fprintf(&b, "  - or run direnv allow %s in a shell\n", root)
+		fmt.Fprintf(&b, "  - set direnv_command to %q in config.yaml to allow it after every save\n", config.DefaultDirenvCommand)
+	}
+	return b.String()
+}
+
+// showDirenvHealth opens the explanation of the direnv indicator
+func (app *App) showDirenvHealth() {
+	app.updateDirenvHealth(false)
+	app.direnvOpen = true
+
+	app.direnvText = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true).
+		SetText(app.formatDirenvHealth())
+
+	app.direnvText.SetBorder(true).
+		SetTitle(" direnv ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("direnv", modal(app.direnvText, 90, 16), true, true)
+	app.app.SetFocus(app.direnvText)
+}
+
+func (app *App) closeDirenvHealth() {
+	app.direnvOpen = false
+	app.pages.RemovePage("direnv")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// allowEnvFile runs direnv allow in the project root from the direnv view
+func (app *App) allowEnvFile() {
+	if app.direnvProblem != direnvBlocked {
+		return
+	}
+	cmd := rootCommand(app.Root, "direnv allow", nil, false)
+	if output, err := combinedOutputLogged(cmd); err != nil {
+		if out := strings.TrimSpace(string(output)); out != "" {
+			err = fmt.Errorf("%w: %s", err, out)
+		}
+		app.showError(fmt.Errorf("running direnv allow: %w", err))
+		return
+	}
+	app.updateDirenvHealth(false)
+	if app.direnvProblem == direnvOK {
+		app.showMessage("Allowed %s", app.EnvFilePath())
+	}
+}
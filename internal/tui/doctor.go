@@ -0,0 +1,291 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/override"
+	"gopkg.in/yaml.v3"
+)
+
+// doctorStatus is the outcome of a single doctor check
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorFinding is a single line of the doctor report
+type doctorFinding struct {
+	Status  doctorStatus
+	Message string
+	Fix     string // actionable hint, empty when nothing needs doing
+}
+
+// doctorReport collects findings while checks run
+type doctorReport struct {
+	findings []doctorFinding
+}
+
+func (r *doctorReport) ok(format string, args ...interface{}) {
+	r.findings = append(r.findings, doctorFinding{Status: doctorOK, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *doctorReport) warn(fix, format string, args ...interface{}) {
+	r.findings = append(r.findings, doctorFinding{Status: doctorWarn, Message: fmt.Sprintf(format, args...), Fix: fix})
+}
+
+func (r *doctorReport) fail(fix, format string, args ...interface{}) {
+	r.findings = append(r.findings, doctorFinding{Status: doctorFail, Message: fmt.Sprintf(format, args...), Fix: fix})
+}
+
+// runDoctor checks the environment lazyhydra depends on and prints actionable findings,
+// reading the project's files through store. It returns the process exit code: 1 if any
+// check failed.
+func runDoctor(store fsys.Store) int {
+	report := &doctorReport{}
+
+	// The root comes first, since the config takes settings from its .lazyhydra.yaml
+	projectRoot, source := setProjectRoot()
+	cfg := doctorCheckConfig(report, store)
+	doctorCheckProjectRoot(report, store, projectRoot, source)
+	doctorCheckOverridesDir(report, store, cfg)
+	doctorCheckHydraConfigsDir(report, store, cfg)
+	doctorCheckHydraVersion(report, cfg)
+	doctorCheckDirenv(report, store, cfg, projectRoot)
+	doctorCheckTemplates(report, store)
+
+	failed := false
+	for _, f := range report.findings {
+		symbol := "✓"
+		switch f.Status {
+		case doctorWarn:
+			symbol = "!"
+		case doctorFail:
+			symbol = "✗"
+			failed = true
+		}
+		fmt.Printf("%s %s\n", symbol, f.Message)
+		if f.Fix != "" {
+			fmt.Printf("    → %s\n", f.Fix)
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func doctorCheckConfig(report *doctorReport, store fsys.Store) *config.Config {
+	configPath := config.Path()
+	cfg, err := config.Load(store)
+	if err != nil {
+		report.fail("Fix the YAML syntax in "+configPath, "Config: %v", err)
+		return config.Default()
+	}
+	if _, err := store.Stat(configPath); os.IsNotExist(err) {
+		report.ok("Config: %s not found, using defaults", configPath)
+	} else {
+		report.ok("Config: %s parses", configPath)
+	}
+	return cfg
+}
+
+func doctorCheckProjectRoot(report *doctorReport, store fsys.Store, projectRoot, source string) {
+	info, err := store.Stat(projectRoot)
+	if err != nil || !info.IsDir() {
+		report.fail("Set PROJECT_ROOT to an existing directory", "Project root: %s is not a directory", projectRoot)
+		return
+	}
+
+	switch source {
+	case "":
+		report.warn("Run from inside the project, run `lazyhydra init` at its root, or set PROJECT_ROOT",
+			"Project root: no .git, .envrc or .lazyhydra.yaml found above, using current directory %s", projectRoot)
+	case "PROJECT_ROOT":
+		report.ok("Project root: %s (from PROJECT_ROOT)", projectRoot)
+	default:
+		report.ok("Project root: %s (found %s)", projectRoot, source)
+	}
+}
+
+func doctorCheckOverridesDir(report *doctorReport, store fsys.Store, cfg *config.Config) {
+	dir := cfg.ExpandPath(cfg.OverridesDir)
+	folders, err := override.ScanFolders(store, dir)
+	if err != nil {
+		report.fail(fmt.Sprintf("Run `lazyhydra init`, create %s or set overrides_dir in config.yaml", dir),
+			"Overrides dir: %v", err)
+		return
+	}
+	report.ok("Overrides dir: %s is readable", dir)
+
+	seen := make(map[string]string)
+	for _, folder := range folders {
+		if other, ok := seen[folder.Name]; ok {
+			report.fail("Rename one of the folders; override names must be unique across groups",
+				"Override %s: duplicate name (%s and %s)", folder.Name, other, folder.Path)
+			continue
+		}
+		seen[folder.Name] = folder.Path
+		doctorCheckOverride(report, store, cfg, folder.Name, folder.Path)
+	}
+	if len(folders) == 0 {
+		report.warn("Press n in the TUI to create one", "Overrides dir: no override folders found")
+	}
+}
+
+// doctorCheckOverride validates one override folder's apply.md frontmatter and override.yaml
+func doctorCheckOverride(report *doctorReport, store fsys.Store, cfg *config.Config, name, path string) {
+	applyPath := filepath.Join(path, "apply.md")
+	content, err := store.ReadFile(applyPath)
+	if err != nil {
+		report.fail("Add an apply.md with type/block frontmatter", "Override %s: missing apply.md", name)
+		return
+	}
+
+	frontmatter, _, ok := override.SplitFrontmatter(string(content))
+	if !ok {
+		report.fail("Start apply.md with a --- delimited YAML frontmatter block", "Override %s: apply.md has no frontmatter", name)
+		return
+	}
+
+	var meta override.Meta
+	if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+		report.fail("Fix the YAML in "+applyPath, "Override %s: invalid frontmatter: %v", name, err)
+		return
+	}
+
+	// Composites only bundle other overrides; their includes are checked by lint (L)
+	if len(meta.Includes) > 0 {
+		report.ok("Override %s (composite of %s)", name, strings.Join(meta.Includes, ", "))
+		return
+	}
+
+	validType := false
+	for _, t := range override.Types {
+		if meta.Type == t {
+			validType = true
+		}
+	}
+	if !validType {
+		report.fail(fmt.Sprintf("Set type to one of %s in %s", strings.Join(override.Types, ", "), applyPath),
+			"Override %s: invalid type %q", name, meta.Type)
+		return
+	}
+
+	overridePath := filepath.Join(path, "override.yaml")
+	data, err := store.ReadFile(overridePath)
+	if err != nil {
+		report.fail("Add an override.yaml", "Override %s: missing override.yaml", name)
+		return
+	}
+	// Parameterized overrides are checked with their default values filled in
+	o := &override.Override{Type: meta.Type, Block: meta.Block, Content: string(data), Params: meta.Params}
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(o.RenderedContent()), &parsed); err != nil {
+		report.fail("Fix the YAML in "+overridePath, "Override %s: invalid override.yaml: %v", name, err)
+		return
+	}
+	if problems := o.HydraProblems(cfg); len(problems) > 0 {
+		report.warn("Change the override or set hydra_version to the Hydra you run",
+			"Override %s: %s", name, strings.Join(problems, "; "))
+		return
+	}
+
+	report.ok("Override %s", name)
+}
+
+func doctorCheckHydraConfigsDir(report *doctorReport, store fsys.Store, cfg *config.Config) {
+	dir := cfg.ExpandPath(cfg.HydraConfigsDir)
+	info, err := store.Stat(dir)
+	if err != nil || !info.IsDir() {
+		report.warn(fmt.Sprintf("Create %s or set hydra_configs_dir in config.yaml", dir),
+			"Hydra configs dir: %s is not a directory; symlinks cannot be created", dir)
+		return
+	}
+	report.ok("Hydra configs dir: %s", dir)
+}
+
+func doctorCheckHydraVersion(report *doctorReport, cfg *config.Config) {
+	version, source := cfg.Hydra()
+	switch {
+	case version == "":
+		report.warn("Set hydra_version in config.yaml or .lazyhydra.yaml, or activate the project's environment",
+			"Hydra version: hydra-core not found in the project's environment; override strings follow the latest Hydra")
+	case source == "hydra_version":
+		report.ok("Hydra version: %s (from hydra_version)", version)
+	default:
+		report.ok("Hydra version: %s (installed in %s)", version, source)
+	}
+}
+
+func doctorCheckDirenv(report *doctorReport, store fsys.Store, cfg *config.Config, projectRoot string) {
+	envPath := cfg.EnvFilePath(projectRoot)
+	if cfg.EnvFormat != config.EnvFormatDirenv {
+		report.ok("Env file: %s is written in %s format; direnv is not used", envPath, cfg.EnvFormat)
+		return
+	}
+
+	command := strings.Fields(cfg.DirenvCommand)
+	if len(command) == 0 {
+		report.ok("Env file: direnv_command is empty; allow %s yourself after saves", envPath)
+		return
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		hint := fmt.Sprintf("Install %s or change direnv_command in config.yaml", command[0])
+		if command[0] == "direnv" {
+			hint = "Install direnv (https://direnv.net) or change direnv_command in config.yaml"
+		}
+		report.warn(hint, "direnv_command: %s is not installed", command[0])
+		return
+	}
+	if command[0] != "direnv" {
+		report.ok("direnv_command: %s is installed; direnv is not checked", command[0])
+		return
+	}
+	report.ok("direnv: installed")
+
+	if _, err := store.Stat(envPath); os.IsNotExist(err) {
+		report.ok("Env file: %s does not exist yet; it is created on first save", envPath)
+		return
+	}
+
+	allowed, found, err := direnvAllowed(projectRoot)
+	switch {
+	case err != nil:
+		report.warn("Run `direnv status` to investigate", "direnv: status failed: %v", err)
+	case !found:
+		report.warn("Check that direnv loads "+envPath, "Env file: direnv did not report %s", envPath)
+	case allowed:
+		report.ok("Env file: %s is allowed", envPath)
+	default:
+		report.fail("Run `direnv allow "+projectRoot+"`", "Env file: %s is not allowed by direnv", envPath)
+	}
+}
+
+func doctorCheckTemplates(report *doctorReport, store fsys.Store) {
+	dir := config.TemplatesDir()
+	legacy := filepath.Join(config.Dir(), "templates")
+	_, err := store.Stat(dir)
+	if _, legacyErr := store.Stat(legacy); legacyErr == nil {
+		if err != nil {
+			report.warn("Run lazyhydra once to move them, or move the folder yourself",
+				"Templates: %s is no longer read; templates now live in %s", legacy, dir)
+		} else {
+			report.warn("Move the templates you still need into "+dir+" and delete "+legacy,
+				"Templates: %s is ignored since %s exists", legacy, dir)
+		}
+		return
+	}
+	if err == nil {
+		report.ok("Templates: %s", dir)
+	}
+}
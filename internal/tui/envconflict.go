@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func (app *App) showEnvrcConflict() {
+	app.conflictOpen = true
+
+	conflictText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText(fmt.Sprintf(`[yellow::b]Env File Changed[-:-:-]
+
+"[red]%s[-]" was modified outside lazyhydra
+since it was loaded. Your change has not been saved.
+
+[green]r[-] reload from disk (discard your change)
+[green]o[-] overwrite with your state
+[green]m[-] merge your change into the file
+
+[yellow]Esc/q[-] to cancel`, app.Config.ProjectEnvFile))
+
+	conflictText.SetBorder(true).
+		SetTitle(" Conflict ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorRed)
+
+	app.pages.AddPage("conflict", modal(conflictText, 60, 13), true, true)
+	app.app.SetFocus(conflictText)
+}
+
+func (app *App) closeEnvrcConflict() {
+	app.conflictOpen = false
+	app.pages.RemovePage("conflict")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// mergePersistedState applies the overrides added or removed since the last save on top
+// of the applied set currently on disk, then saves the result.
+func (app *App) mergePersistedState() {
+	merged, err := app.ReadAppliedState()
+	if err != nil {
+		app.showError(err)
+		return
+	}
+
+	for name := range app.Applied {
+		if !app.savedApplied[name] {
+			merged[name] = true
+		}
+	}
+	for name := range app.savedApplied {
+		if !app.Applied[name] {
+			delete(merged, name)
+		}
+	}
+
+	app.Applied = merged
+	app.ReconcileSymlinks()
+	if err := app.writePersistedState(); err != nil {
+		app.showError(err)
+	}
+	app.refreshAll()
+}
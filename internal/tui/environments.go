@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/state"
+	"github.com/rivo/tview"
+)
+
+// listEnvironments returns the known environments: the default one, those recorded in
+// the env file, and the current one, with the default first.
+func (app *App) listEnvironments() []string {
+	recorded, _ := app.ReadEnvState()
+	seen := map[string]bool{state.DefaultEnvironment: true, app.Env: true}
+	for env := range recorded.Sets {
+		seen[env] = true
+	}
+
+	var envs []string
+	for env := range seen {
+		if env != state.DefaultEnvironment {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+	return append([]string{state.DefaultEnvironment}, envs...)
+}
+
+// switchEnvironment saves the current applied set under its environment and applies
+// the set of another one.
+func (app *App) switchEnvironment(env string) {
+	if env == app.Env || !app.stateChangesAllowed("Switching environments") {
+		return
+	}
+
+	recorded, err := app.ReadEnvState()
+	if err != nil {
+		app.showError(err)
+		return
+	}
+
+	previous := app.Env
+	app.Env = env
+	app.Applied = make(map[string]bool)
+	for name := range recorded.Sets[env] {
+		app.Applied[name] = true
+	}
+	app.marked = make(map[string]bool)
+	app.ReconcileSymlinks()
+
+	saved := app.persistState()
+	app.refreshAll()
+	if saved {
+		app.showMessage("Switched from %s to %s environment", previous, env)
+	}
+}
+
+// showEnvironmentPicker lists the environments to switch to, plus an entry to create one
+func (app *App) showEnvironmentPicker() {
+	if !app.stateChangesAllowed("Switching environments") {
+		return
+	}
+
+	app.environmentOpen = true
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+
+	recorded, _ := app.ReadEnvState()
+	envs := app.listEnvironments()
+	current := 0
+	for i, env := range envs {
+		name := env
+		label := fmt.Sprintf("  %s [darkgray](%d applied)[-]", name, len(recorded.Sets[name]))
+		if name == app.Env {
+			label = "[green]●[-] " + name
+			current = i
+		}
+		list.AddItem(label, "", 0, func() {
+			app.closeEnvironmentPicker()
+			app.switchEnvironment(name)
+		})
+	}
+	list.AddItem("  [darkgray]+ new environment...[-]", "", 0, func() {
+		app.closeEnvironmentPicker()
+		app.showNewEnvironmentInput()
+	})
+	list.SetCurrentItem(current)
+
+	list.SetBorder(true).
+		SetTitle(" Environment ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(envs) + 3
+	if height > 20 {
+		height = 20
+	}
+	app.pages.AddPage("environments", modal(list, 50, height), true, true)
+	app.app.SetFocus(list)
+}
+
+func (app *App) closeEnvironmentPicker() {
+	app.environmentOpen = false
+	app.pages.RemovePage("environments")
+	app.pages.RemovePage("new-environment")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// showNewEnvironmentInput asks for the name of a new environment and switches to it.
+// A new environment starts with nothing applied.
+func (app *App) showNewEnvironmentInput() {
+	app.environmentOpen = true
+
+	inputField := tview.NewInputField().
+		SetLabel("Name: ").
+		SetFieldWidth(40).
+		SetFieldBackgroundColor(tcell.ColorDefault)
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		name := strings.TrimSpace(inputField.GetText())
+		app.closeEnvironmentPicker()
+		if key != tcell.KeyEnter || name == "" {
+			return
+		}
+		if err := state.ValidateEnvironmentName(name); err != nil {
+			app.showError(err)
+			return
+		}
+		app.switchEnvironment(name)
+	})
+
+	inputField.SetBorder(true).
+		SetTitle(" New Environment ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("new-environment", modal(inputField, 60, 3), true, true)
+	app.app.SetFocus(inputField)
+}
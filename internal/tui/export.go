@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/state"
+	"gopkg.in/yaml.v3"
+)
+
+// exportState is the applied state `lazyhydra export` hands to experiment trackers, so
+// every run can be traced back to the overrides it ran with
+type exportState struct {
+	Overrides      []string          `yaml:"overrides"`
+	OverrideString string            `yaml:"override_string"`
+	Args           []string          `yaml:"args"`
+	Groups         map[string]string `yaml:"groups,omitempty"` // export group -> override string
+	Environment    string            `yaml:"environment"`
+	Hash           string            `yaml:"hash"` // the derived_vars hash of the applied set
+}
+
+// buildExportState collects the applied state for export
+func (app *App) buildExportState() exportState {
+	names := []string{}
+	for _, o := range app.getAppliedOverrides() {
+		names = append(names, o.Name)
+	}
+	env := app.Env
+	if env == "" {
+		env = state.DefaultEnvironment
+	}
+	export := exportState{
+		Overrides:      names,
+		OverrideString: app.BuildString(),
+		Args:           append([]string{}, app.BuildArgs()...),
+		Environment:    env,
+		Hash:           state.AppliedHash(names),
+	}
+	for _, group := range app.ExportGroups() {
+		if export.Groups == nil {
+			export.Groups = make(map[string]string)
+		}
+		export.Groups[group] = app.BuildGroupString(group)
+	}
+	return export
+}
+
+// mlflowTags returns the applied state as MLflow run tags, all under lazyhydra.: the
+// applied names, the override string on one line, the hash, the environment, and the
+// arguments of each applied override and export group
+func (app *App) mlflowTags() map[string]string {
+	export := app.buildExportState()
+	tags := map[string]string{
+		"lazyhydra.overrides":       strings.Join(export.Overrides, ","),
+		"lazyhydra.override_string": strings.ReplaceAll(export.OverrideString, "\n", " "),
+		"lazyhydra.hash":            export.Hash,
+		"lazyhydra.environment":     export.Environment,
+	}
+	for _, o := range app.getAppliedOverrides() {
+		tags["lazyhydra.override."+o.Name] = strings.Join(o.Args(app.Config), " ")
+	}
+	for group, overrideString := range export.Groups {
+		tags["lazyhydra.group."+group] = strings.ReplaceAll(overrideString, "\n", " ")
+	}
+	return tags
+}
+
+// runExport writes the applied state for an experiment tracker, to stdout or with
+// --output to a file relative to the project root:
+//   - --wandb writes YAML for wandb.init(config=...), under a lazyhydra key so it does
+//     not clash with the run's own config
+//   - --mlflow writes a JSON object of run tags for mlflow.set_tags
+func (app *App) runExport(args []string) error {
+	var data []byte
+	var err error
+	switch {
+	case hasFlag(args, "--wandb"):
+		data, err = yaml.Marshal(map[string]exportState{"lazyhydra": app.buildExportState()})
+	case hasFlag(args, "--mlflow"):
+		data, err = json.MarshalIndent(app.mlflowTags(), "", "  ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("usage: lazyhydra export --wandb|--mlflow [--output FILE]")
+	}
+	if err != nil {
+		return err
+	}
+
+	path, ok := flagValue(args, "--output")
+	if !ok {
+		path, ok = flagValue(args, "-o")
+	}
+	if !ok || path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(app.Root, path)
+	}
+	if app.DryRun {
+		fmt.Printf("Would write %s\n", path)
+		return nil
+	}
+	if err := app.FS.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fsys.WriteFileAtomic(app.FS, path, data, 0644)
+}
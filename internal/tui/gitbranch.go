@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+)
+
+// findGitDir returns the git directory of the repository containing dir, following the
+// "gitdir:" file that worktrees and submodules use instead of a .git folder.
+func findGitDir(store fsys.Store, dir string) (string, bool) {
+	for {
+		path := filepath.Join(dir, ".git")
+		if info, err := store.Stat(path); err == nil {
+			if info.IsDir() {
+				return path, true
+			}
+			data, err := store.ReadFile(path)
+			if err != nil {
+				return "", false
+			}
+			gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+			if !ok {
+				return "", false
+			}
+			gitDir = strings.TrimSpace(gitDir)
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+			return gitDir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// currentGitBranch returns the branch checked out in the repository containing dir, or
+// "" when it is not a git repository or HEAD is detached.
+func currentGitBranch(store fsys.Store, dir string) string {
+	gitDir, ok := findGitDir(store, dir)
+	if !ok {
+		return ""
+	}
+	data, err := store.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	// A detached HEAD holds a commit hash instead of a ref
+	branch, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: refs/heads/")
+	if !ok {
+		return ""
+	}
+	return branch
+}
+
+// nonEnvironmentChars matches the characters a branch name may have but an environment
+// name may not
+var nonEnvironmentChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// branchEnvironment returns the environment that keeps the applied set of a branch,
+// e.g. feature/new-model -> feature_new_model.
+func branchEnvironment(branch string) string {
+	env := nonEnvironmentChars.ReplaceAllString(strings.ToLower(branch), "_")
+	env = strings.Trim(env, "_")
+	if env == "" || env[0] < 'a' || env[0] > 'z' {
+		env = "b_" + env
+	}
+	return env
+}
+
+// followGitBranch notices branch checkouts. With branch_environments enabled it switches
+// to the environment of the new branch, restoring the overrides last used on it.
+func (app *App) followGitBranch() {
+	branch := currentGitBranch(app.FS, app.Root)
+	if branch == app.branch {
+		return
+	}
+	logging.Logger.Debug("git branch changed", "from", app.branch, "to", branch)
+	app.branch = branch
+
+	if app.Config.BranchEnvironments && branch != "" {
+		app.switchEnvironment(branchEnvironment(branch))
+	}
+	app.updateStatusBar()
+}
+
+// gitCommit returns the commit checked out in the repository at root and whether its
+// tracked files have uncommitted changes. ok is false outside a git repository.
+func gitCommit(root string) (commit string, dirty, ok bool) {
+	output, err := outputLogged(rootCommand(root, "git rev-parse HEAD", nil, false))
+	if err != nil {
+		return "", false, false
+	}
+	status, err := outputLogged(rootCommand(root, "git status --porcelain --untracked-files=no", nil, false))
+	return strings.TrimSpace(string(output)), err == nil && len(strings.TrimSpace(string(status))) > 0, true
+}
+
+// describeCommit formats a commit for messages: its short hash, marked when the working
+// tree had uncommitted changes
+func describeCommit(commit string, dirty bool) string {
+	s := commit[:min(len(commit), 7)]
+	if dirty {
+		s += " (dirty)"
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// Headers of the Applied groups of overrides that target no block
+const (
+	groupValues     = "(values)"
+	groupComposites = "(composites)"
+)
+
+// appliedGroup returns the header an applied override is listed under when Applied is
+// grouped by block
+func appliedGroup(o *override.Override) string {
+	switch {
+	case o.IsComposite():
+		return groupComposites
+	case o.Block == "":
+		return groupValues
+	}
+	return o.Block
+}
+
+// groupAppliedRows lays out the applied overrides under a header for each block they
+// target, blocks in name order and overrides in the order of the override string.
+// Members of composites are listed under their blocks, so composites are not expanded.
+func (app *App) groupAppliedRows(applied []*override.Override) []appliedRow {
+	byGroup := make(map[string][]*override.Override)
+	var blocks []string
+	for _, o := range applied {
+		group := appliedGroup(o)
+		if byGroup[group] == nil && group != groupValues && group != groupComposites {
+			blocks = append(blocks, group)
+		}
+		byGroup[group] = append(byGroup[group], o)
+	}
+	sort.Strings(blocks)
+
+	var rows []appliedRow
+	for _, group := range append(blocks, groupValues, groupComposites) {
+		if len(byGroup[group]) == 0 {
+			continue
+		}
+		rows = append(rows, appliedRow{group: group})
+		for _, o := range byGroup[group] {
+			rows = append(rows, appliedRow{override: o, depth: 1})
+		}
+	}
+	return rows
+}
+
+// groupOverrides returns the applied overrides listed under a group header
+func (app *App) groupOverrides(group string) []*override.Override {
+	var list []*override.Override
+	for _, o := range app.getAppliedOverrides() {
+		if appliedGroup(o) == group {
+			list = append(list, o)
+		}
+	}
+	return list
+}
+
+// formatGroupRow returns the list text of a group header: the block and how many
+// applied overrides target it, in red when they conflict
+func (app *App) formatGroupRow(group string) string {
+	count := len(app.groupOverrides(group))
+	if group == groupValues || group == groupComposites {
+		return fmt.Sprintf("[darkgray::b]%s[-:-:-] [darkgray](%d)[-]", group, count)
+	}
+	if len(app.blockConflicts()[group]) > 1 {
+		return fmt.Sprintf("[blue::b]%s[-:-:-] [red](%d, conflicting)[-]", tview.Escape(group), count)
+	}
+	return fmt.Sprintf("[blue::b]%s[-:-:-] [darkgray](%d)[-]", tview.Escape(group), count)
+}
+
+// selectedGroup returns the group whose header is under the cursor in Applied
+func (app *App) selectedGroup() (string, bool) {
+	if app.currentPanelIdx != 1 {
+		return "", false
+	}
+	idx := app.appliedList.GetCurrentItem()
+	if idx < 0 || idx >= len(app.appliedRows) || app.appliedRows[idx].group == "" {
+		return "", false
+	}
+	return app.appliedRows[idx].group, true
+}
+
+// formatGroup lists the overrides of a group with their override strings for the
+// content view, explaining how Hydra resolves them when they conflict
+func (app *App) formatGroup(group string) string {
+	list := app.groupOverrides(group)
+	var b strings.Builder
+	fmt.Fprintf(&b, "[cyan::b]# %s[-:-:-]\n\n", tview.Escape(group))
+	for _, o := range list {
+		fmt.Fprintf(&b, "  %s %s [darkgray]%s[-]\n", app.typeMarker(o.Type), o.Name, tview.Escape(app.buildOverrideStringForOne(o)))
+	}
+	if conflicting := app.blockConflicts()[group]; len(conflicting) > 1 {
+		fmt.Fprintf(&b, "\n%s\n", app.explainConflict(conflicting))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// toggleGroupApplied groups the Applied panel under block headers or lists it as
+// applied, and remembers it for the next session
+func (app *App) toggleGroupApplied() {
+	app.ui.GroupApplied = !app.ui.GroupApplied
+	app.saveUIState()
+	app.refreshAll()
+}
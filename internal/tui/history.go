@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// writeOverrideFile replaces one of an override's files, keeping its previous content in
+// the override's history
+func (app *App) writeOverrideFile(o *override.Override, file string, data []byte) error {
+	path := filepath.Join(o.FolderPath, file)
+	if previous, err := app.FS.ReadFile(path); err == nil && !bytes.Equal(previous, data) {
+		app.recordVersion(o, file, previous)
+	}
+	return app.FS.WriteFile(path, data, 0644)
+}
+
+// recordVersion keeps previous as an earlier version of one of an override's files.
+// Failing to keep it does not stop the edit.
+func (app *App) recordVersion(o *override.Override, file string, previous []byte) {
+	if err := override.SaveVersion(app.FS, o.FolderPath, file, previous); err != nil {
+		app.logError(fmt.Errorf("keeping history of %s/%s: %w", o.Name, file, err))
+	}
+}
+
+// showVersions lists the earlier versions of the selected override's files with a diff
+// of what reverting to each would change
+func (app *App) showVersions() {
+	selected := app.getSelectedOverride()
+	if selected == nil {
+		return
+	}
+	versions := override.Versions(app.FS, selected.FolderPath, "")
+	if len(versions) == 0 {
+		app.showMessage("No earlier versions of %s", selected.Name)
+		return
+	}
+
+	app.versionsOpen = true
+	app.versionsTarget = selected
+	app.versions = versions
+
+	diffView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	app.versionsDiff = diffView
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+	for _, v := range versions {
+		list.AddItem(fmt.Sprintf("%s  %s", v.Time.Format("2006-01-02 15:04:05"), v.File), "", 0, nil)
+	}
+	list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		app.showVersionDiff(index)
+	})
+	list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		app.revertToVersion(index)
+	})
+	app.showVersionDiff(0)
+
+	listHeight := len(versions)
+	if listHeight > 8 {
+		listHeight = 8
+	}
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(list, listHeight, 0, true).
+		AddItem(diffView, 0, 1, false)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" History: %s ", selected.Name)).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("versions", modal(layout, 90, 30), true, true)
+	app.app.SetFocus(list)
+}
+
+// showVersionDiff shows what reverting to the version at index would change
+func (app *App) showVersionDiff(index int) {
+	if index < 0 || index >= len(app.versions) {
+		return
+	}
+	v := app.versions[index]
+	old, _ := app.FS.ReadFile(v.Path)
+	current, _ := app.FS.ReadFile(filepath.Join(app.versionsTarget.FolderPath, v.File))
+
+	diff := lineDiff(string(current), string(old))
+	text := fmt.Sprintf("[yellow::b]Reverting %s to this version changes:[-:-:-]\n\n", v.File)
+	if !diffChanged(diff) {
+		text = fmt.Sprintf("[darkgray]%s has the same content as this version[-]\n\n", v.File)
+	}
+	app.versionsDiff.SetText(text + formatDiffColored(diff))
+	app.versionsDiff.ScrollToBeginning()
+}
+
+func (app *App) closeVersions() {
+	app.versionsOpen = false
+	app.versionsTarget = nil
+	app.versionsDiff = nil
+	app.versions = nil
+	app.pages.RemovePage("versions")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// revertToVersion writes the version at index back. The content it replaces becomes a
+// version itself, so a revert can be undone the same way.
+func (app *App) revertToVersion(index int) {
+	if index < 0 || index >= len(app.versions) || !app.writesAllowed("Reverting") {
+		return
+	}
+	o, v := app.versionsTarget, app.versions[index]
+	app.closeVersions()
+
+	data, err := app.FS.ReadFile(v.Path)
+	if err == nil {
+		err = app.writeOverrideFile(o, v.File, data)
+	}
+	if err != nil {
+		app.showError(fmt.Errorf("reverting %s/%s: %w", o.Name, v.File, err))
+		return
+	}
+
+	app.reloadOverride(o.Name)
+	saved := true
+	if app.Applied[o.Name] {
+		saved = app.persistState()
+	}
+	app.refreshAll()
+	if saved {
+		app.showMessage("Reverted %s/%s to %s", o.Name, v.File, v.Time.Format("2006-01-02 15:04:05"))
+	}
+}
+
+// showEditDiff shows what an edit in $EDITOR changed in one of an override's files, so
+// an accidental edit is noticed before the next run. u undoes the edit.
+func (app *App) showEditDiff(o *override.Override, file string, before, after []byte) {
+	app.editDiffOpen = true
+	app.editDiffTarget = o
+	app.editDiffFile = file
+	app.editDiffBefore = before
+
+	diffText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(formatDiffColored(lineDiff(string(before), string(after))))
+
+	diffText.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Edited %s/%s ", o.Name, file)).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorYellow)
+
+	app.pages.AddPage("editdiff", modal(diffText, 100, 20), true, true)
+	app.app.SetFocus(diffText)
+}
+
+func (app *App) closeEditDiff() {
+	app.editDiffOpen = false
+	app.editDiffTarget = nil
+	app.editDiffBefore = nil
+	app.pages.RemovePage("editdiff")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// undoEdit writes back the content a file had before the edit shown in the edit diff.
+// The edited content is kept in the history, so V can bring it back.
+func (app *App) undoEdit() {
+	o, file, before := app.editDiffTarget, app.editDiffFile, app.editDiffBefore
+	app.closeEditDiff()
+
+	if err := app.writeOverrideFile(o, file, before); err != nil {
+		app.showError(fmt.Errorf("undoing edit of %s/%s: %w", o.Name, file, err))
+		return
+	}
+	app.reloadOverride(o.Name)
+	saved := true
+	if app.Applied[o.Name] {
+		saved = app.persistState()
+	}
+	app.refreshAll()
+	if saved {
+		app.showMessage("Undid edit of %s/%s", o.Name, file)
+	}
+}
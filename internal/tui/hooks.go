@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/ramy/lazyhydra/internal/override"
+)
+
+// hookRun is one hook command with the action and override it runs for
+type hookRun struct {
+	command  string
+	action   string
+	override string
+}
+
+// pendingHooks returns the hooks a save triggers, given the applied set before it: apply
+// and remove hooks for every override that was applied or removed since the last save,
+// then the save hooks. Hooks from the config run before those from the override itself.
+func (app *App) pendingHooks(previous map[string]bool) []hookRun {
+	byName := make(map[string]*override.Override)
+	for _, o := range app.Overrides {
+		byName[o.Name] = o
+	}
+
+	var runs []hookRun
+	add := func(commands []string, action, name string) {
+		for _, command := range commands {
+			runs = append(runs, hookRun{command: command, action: action, override: name})
+		}
+	}
+
+	for _, o := range app.Overrides {
+		if app.Applied[o.Name] && !previous[o.Name] {
+			add(app.Config.Hooks.Apply, "apply", o.Name)
+			add(o.Hooks.Apply, "apply", o.Name)
+		}
+	}
+	// Removed overrides may have been deleted already; only the config hooks run for those
+	var removed []string
+	for name := range previous {
+		if !app.Applied[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		add(app.Config.Hooks.Remove, "remove", name)
+		if o := byName[name]; o != nil {
+			add(o.Hooks.Remove, "remove", name)
+		}
+	}
+
+	add(app.Config.Hooks.Save, "save", "")
+	for _, o := range app.getAppliedOverrides() {
+		add(o.Hooks.Save, "save", o.Name)
+	}
+	return runs
+}
+
+// runHooks runs the hooks triggered by a save in the project root, with the action in
+// LAZYHYDRA_HOOK_ACTION, the override in LAZYHYDRA_HOOK_OVERRIDE and the applied
+// overrides as `lazyhydra run` exports them. In the TUI they run in the background and
+// failures are reported as errors; from the command line they run before it exits.
+func (app *App) runHooks(previous map[string]bool) {
+	runs := app.pendingHooks(previous)
+	if len(runs) == 0 {
+		return
+	}
+	env := slices.Clip(app.overrideEnv())
+
+	run := func(report func(error)) {
+		for _, h := range runs {
+			cmd := rootCommand(app.Root, h.command,
+				append(env, "LAZYHYDRA_HOOK_ACTION="+h.action, "LAZYHYDRA_HOOK_OVERRIDE="+h.override), false)
+			output, err := combinedOutputLogged(cmd)
+			logging.Logger.Debug("ran hook", "action", h.action, "override", h.override, "command", h.command, "error", err)
+			if err != nil {
+				if out := strings.TrimSpace(string(output)); out != "" {
+					err = fmt.Errorf("%w: %s", err, out)
+				}
+				report(fmt.Errorf("%s hook %q: %w", h.action, h.command, err))
+			}
+		}
+	}
+
+	if app.app == nil {
+		run(func(err error) { fmt.Fprintf(os.Stderr, "Warning: %v\n", err) })
+		return
+	}
+	go run(func(err error) {
+		app.app.QueueUpdateDraw(func() { app.showError(err) })
+	})
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// Kinds of override an icon is chosen for
+const (
+	iconMerge     = "merge"     // type +
+	iconReplace   = "replace"   // type =
+	iconValue     = "value"     // no block, type ++ or --
+	iconSweep     = "sweep"     // tagged sweep
+	iconComposite = "composite" // bundles other overrides
+)
+
+// iconGlyphs are the icons of each icons.style, by kind
+var iconGlyphs = map[string]map[string]string{
+	config.IconStyleASCII:   {iconMerge: "M", iconReplace: "R", iconValue: "V", iconSweep: "S", iconComposite: "C"},
+	config.IconStyleUnicode: {iconMerge: "⊕", iconReplace: "⇄", iconValue: "≔", iconSweep: "↻", iconComposite: "❖"},
+	// Font Awesome glyphs of Nerd Fonts: plus-circle, exchange, sliders, refresh, cubes
+	config.IconStyleNerdFont: {iconMerge: "\uf055", iconReplace: "\uf0ec", iconValue: "\uf1de", iconSweep: "\uf021", iconComposite: "\uf1b3"},
+}
+
+// iconColors are the colors of the icons, by kind
+var iconColors = map[string]string{
+	iconMerge: "green", iconReplace: "yellow", iconValue: "aqua", iconSweep: "fuchsia", iconComposite: "blue",
+}
+
+// iconKind returns the kind of override o is for its icon
+func iconKind(o *override.Override) string {
+	switch {
+	case o.IsComposite():
+		return iconComposite
+	case slices.Contains(o.Tags, iconSweep):
+		return iconSweep
+	case o.Block == "":
+		return iconValue
+	case o.Type == "=":
+		return iconReplace
+	}
+	return iconMerge
+}
+
+// iconStyle returns the icons.style in effect. Unicode and Nerd Font glyphs fall back to
+// ASCII when the locale says the terminal cannot show them.
+func (app *App) iconStyle() string {
+	style := app.Config.Icons.Style
+	if style == config.IconStyleUnicode || style == config.IconStyleNerdFont {
+		if !utf8Locale() {
+			return config.IconStyleASCII
+		}
+	}
+	return style
+}
+
+// utf8Locale reports whether the locale is a UTF-8 one. An unset locale and Windows
+// count as UTF-8, as they do for the terminal library.
+func utf8Locale() bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := strings.ToLower(os.Getenv(name)); value != "" {
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return true
+}
+
+// iconPrefix returns the icon shown before an override in the lists, followed by a
+// space, or "" without icons. A tag in icons.tags wins over the override's kind.
+func (app *App) iconPrefix(o *override.Override) string {
+	style := app.iconStyle()
+	if style == config.IconStyleNone {
+		return ""
+	}
+	icons := app.Config.Icons
+	kind := iconKind(o)
+	color := iconColors[kind]
+	glyph, ok := icons.Kinds[kind]
+	if !ok {
+		glyph = iconGlyphs[style][kind]
+	}
+	for _, tag := range o.Tags {
+		if tagGlyph, ok := icons.Tags[tag]; ok {
+			glyph, color = tagGlyph, "white"
+			break
+		}
+	}
+	if glyph == "" {
+		return ""
+	}
+	return "[" + color + "]" + tview.Escape(glyph) + "[-] "
+}
@@ -0,0 +1,258 @@
+package tui
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// importCandidate is a config group option in hydra_configs_dir that can be imported
+// as an override
+type importCandidate struct {
+	Group  string // config group path, e.g. "experiment/config/logging"
+	Option string // option name, e.g. "detailed"
+	Path   string // full path to the option's yaml file
+	Name   string // name of the override it becomes
+}
+
+// findImportCandidates scans hydra_configs_dir for config group options that no override
+// was imported from yet. Files at the top level are primary configs rather than group
+// options, and the symlinks lazyhydra creates are skipped.
+func (app *App) findImportCandidates() []importCandidate {
+	hydraDir := app.Config.ExpandPath(app.Config.HydraConfigsDir)
+	overridesDir := app.Config.ExpandPath(app.Config.OverridesDir)
+
+	taken := make(map[string]bool)
+	imported := make(map[string]bool)
+	for _, o := range app.Overrides {
+		taken[o.Name] = true
+		imported[o.Block+"/"+o.File] = true
+	}
+
+	var candidates []importCandidate
+	fsys.WalkDir(app.FS, hydraDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != hydraDir && (strings.HasPrefix(d.Name(), ".") || path == overridesDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 || filepath.Ext(path) != ".yaml" ||
+			strings.HasSuffix(d.Name(), "_override.yaml") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(hydraDir, filepath.Dir(path))
+		if err != nil || rel == "." {
+			return nil
+		}
+		group := filepath.ToSlash(rel)
+		option := strings.TrimSuffix(d.Name(), ".yaml")
+		leaf := group[strings.LastIndex(group, "/")+1:]
+
+		// Imported overrides remember their origin as block plus file
+		if imported[strings.ReplaceAll(group, "/", ".")+"/"+d.Name()] {
+			return nil
+		}
+
+		// Prefer the option name, qualified by the group when it is taken
+		name := option
+		if taken[name] {
+			name = leaf + "_" + option
+		}
+		if taken[name] {
+			return nil
+		}
+		taken[name] = true
+
+		candidates = append(candidates, importCandidate{Group: group, Option: option, Path: path, Name: name})
+		return nil
+	})
+	return candidates
+}
+
+// importOverride creates an override folder from a config group option. The option's
+// content becomes override.yaml and the block and module metadata are guessed from its
+// group path, e.g. experiment/config/logging/detailed.yaml gets block
+// experiment.config.logging, module_path experiment/config, module logging and file
+// detailed.yaml. An option with a # @package header keeps its package. The override
+// lands in a group folder named after the config group.
+func (app *App) importOverride(c importCandidate) error {
+	content, err := app.FS.ReadFile(c.Path)
+	if err != nil {
+		return err
+	}
+
+	leaf := c.Group[strings.LastIndex(c.Group, "/")+1:]
+	overridePath := filepath.Join(app.Config.ExpandPath(app.Config.OverridesDir), leaf, c.Name)
+	if _, err := app.FS.Stat(overridePath); err == nil {
+		return fmt.Errorf("override folder %s already exists", overridePath)
+	}
+
+	modulePath := ""
+	if i := strings.LastIndex(c.Group, "/"); i >= 0 {
+		modulePath = c.Group[:i]
+	}
+	hydraDir := app.Config.ExpandPath(app.Config.HydraConfigsDir)
+	source, _ := filepath.Rel(hydraDir, c.Path)
+	fields := [][2]string{
+		{"type", "+"},
+		{"block", strings.ReplaceAll(c.Group, "/", ".")},
+		{"file", c.Option + ".yaml"},
+		{"module_path", modulePath},
+		{"module", leaf},
+		{"description", "Imported from " + filepath.ToSlash(source)},
+	}
+	if pkg := override.ContentPackage(string(content)); pkg != "" {
+		fields = append(fields, [2]string{"package", pkg})
+	}
+	applyContent, err := override.SetFrontmatterFields("---\n---\n", fields)
+	if err != nil {
+		return err
+	}
+
+	if err := app.FS.MkdirAll(overridePath, 0755); err != nil {
+		return err
+	}
+	if err := app.FS.WriteFile(filepath.Join(overridePath, "override.yaml"), content, 0644); err != nil {
+		return err
+	}
+	return app.FS.WriteFile(filepath.Join(overridePath, "apply.md"), []byte(applyContent), 0644)
+}
+
+// showImportWizard lists the config group options that can be imported as overrides
+func (app *App) showImportWizard() {
+	if !app.writesAllowed("Importing") {
+		return
+	}
+
+	candidates := app.findImportCandidates()
+	if len(candidates) == 0 {
+		app.showMessage("No config group options left to import from %s", app.Config.HydraConfigsDir)
+		return
+	}
+
+	app.importOpen = true
+	app.importCandidates = candidates
+	app.importSelected = make(map[int]bool)
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+	for i := range candidates {
+		list.AddItem(app.formatImportCandidate(i), "", 0, nil)
+	}
+	app.importList = list
+
+	list.SetBorder(true).
+		SetTitle(" Import Config Groups (space select, a all, enter import) ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(candidates) + 2
+	if height > 24 {
+		height = 24
+	}
+	app.pages.AddPage("import", modal(list, 90, height), true, true)
+	app.app.SetFocus(list)
+}
+
+// formatImportCandidate returns the wizard line of a candidate with its selection box
+func (app *App) formatImportCandidate(i int) string {
+	c := app.importCandidates[i]
+	box := tview.Escape("[ ]")
+	if app.importSelected[i] {
+		box = "[green]" + tview.Escape("[x]") + "[-]"
+	}
+	return fmt.Sprintf("%s %s/[yellow]%s[-] [darkgray]→ %s[-]", box, tview.Escape(c.Group), tview.Escape(c.Option), tview.Escape(c.Name))
+}
+
+// toggleImportSelection selects or deselects the candidate under the cursor, or all of them
+func (app *App) toggleImportSelection(all bool) {
+	if all {
+		selectAll := len(app.importSelected) < len(app.importCandidates)
+		for i := range app.importCandidates {
+			if selectAll {
+				app.importSelected[i] = true
+			} else {
+				delete(app.importSelected, i)
+			}
+			app.importList.SetItemText(i, app.formatImportCandidate(i), "")
+		}
+		return
+	}
+
+	i := app.importList.GetCurrentItem()
+	if app.importSelected[i] {
+		delete(app.importSelected, i)
+	} else {
+		app.importSelected[i] = true
+	}
+	app.importList.SetItemText(i, app.formatImportCandidate(i), "")
+	if i+1 < app.importList.GetItemCount() {
+		app.importList.SetCurrentItem(i + 1)
+	}
+}
+
+// confirmImport imports the selected candidates, or the one under the cursor when none
+// is selected, and reloads the overrides.
+func (app *App) confirmImport() {
+	selected := app.importSelected
+	if len(selected) == 0 {
+		selected = map[int]bool{app.importList.GetCurrentItem(): true}
+	}
+
+	count := 0
+	var firstErr error
+	for i, c := range app.importCandidates {
+		if !selected[i] {
+			continue
+		}
+		if err := app.importOverride(c); err != nil {
+			app.logError(fmt.Errorf("importing %s/%s: %w", c.Group, c.Option, err))
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		count++
+	}
+	app.closeImportWizard()
+
+	app.Overrides = nil
+	if err := app.LoadOverrides(); err != nil {
+		app.showError(err)
+		return
+	}
+	if app.watcher != nil {
+		app.watchOverrideDirs()
+	}
+	app.refreshAll()
+
+	if firstErr != nil {
+		app.showError(fmt.Errorf("imported %d, some failed: %w", count, firstErr))
+		return
+	}
+	app.showMessage("Imported %d overrides", count)
+}
+
+func (app *App) closeImportWizard() {
+	app.importOpen = false
+	app.importCandidates = nil
+	app.importSelected = nil
+	app.importList = nil
+	app.pages.RemovePage("import")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
@@ -0,0 +1,217 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/state"
+)
+
+// initMarkerContent is the starter .lazyhydra.yaml written by `lazyhydra init`
+const initMarkerContent = `# Marks the root of a LazyHydra project. lazyhydra finds this directory from any
+# subdirectory, writes the env file here and expands $PROJECT_ROOT to it.
+# Settings such as overrides_dir live in config.yaml; run "lazyhydra doctor" to see
+# where it is read from. The env var settings (env_var_name, override_str_var_name,
+# export_group_var and derived_vars) may be set here for this project.
+`
+
+// initEnvHeader starts an env file created by `lazyhydra init`. It is a comment in
+// every env_format.
+const initEnvHeader = "# Applied Hydra overrides, written by lazyhydra\n"
+
+// initExampleApply and initExampleValues make up the example override seeded by
+// `lazyhydra init --example`
+const initExampleApply = `---
+type: "++"
+description: "Example value override; edit or delete it"
+---
+# example
+
+A value override: every key in override.yaml becomes a ++key=value Hydra argument.
+Add a block to the frontmatter to turn it into a config group override instead.
+`
+
+const initExampleValues = "seed: 42\n"
+
+// runInit handles `lazyhydra init [DIR] [--example]`, setting up a project: the
+// overrides directory, a .lazyhydra.yaml marking the root, optionally an example
+// override, and the env file with direnv_command run on it. Files that exist are left
+// alone, so it is safe to run again.
+func runInit(args []string, flags globalFlags) error {
+	dryRun := flags.dryRun
+	usage := fmt.Errorf("usage: lazyhydra init [DIR] [--example]")
+
+	var dirs []string
+	for _, arg := range args {
+		switch {
+		case arg == "--example":
+		case strings.HasPrefix(arg, "-"):
+			return usage
+		default:
+			dirs = append(dirs, arg)
+		}
+	}
+	if len(dirs) > 1 {
+		return usage
+	}
+
+	// Without a directory, initialize the detected project root
+	var root string
+	if len(dirs) == 1 {
+		abs, err := filepath.Abs(dirs[0])
+		if err != nil {
+			return err
+		}
+		root = abs
+		os.Setenv("PROJECT_ROOT", root)
+	} else {
+		root, _ = setProjectRoot()
+	}
+
+	cfg, err := config.Load(fsys.OS)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	p := &state.Project{
+		Config:  cfg,
+		FS:      fsys.OS,
+		Root:    root,
+		DryRun:  dryRun,
+		Applied: make(map[string]bool),
+	}
+	in := &initializer{store: p.FS, root: root, dryRun: dryRun}
+	fmt.Printf("Initializing %s\n", root)
+	if !dryRun {
+		if err := p.FS.MkdirAll(root, 0755); err != nil {
+			return err
+		}
+	}
+
+	overridesDir := cfg.ExpandPath(cfg.OverridesDir)
+	in.mkdir(overridesDir)
+	in.writeFile(filepath.Join(root, ".lazyhydra.yaml"), initMarkerContent)
+	if hasFlag(args, "--example") {
+		exampleDir := filepath.Join(overridesDir, "example")
+		in.mkdir(exampleDir)
+		in.writeFile(filepath.Join(exampleDir, "apply.md"), initExampleApply)
+		in.writeFile(filepath.Join(exampleDir, "override.yaml"), initExampleValues)
+	}
+	if in.err != nil {
+		return in.err
+	}
+
+	if err := in.envFile(p); err != nil {
+		return err
+	}
+	if !dryRun && !flags.noDirenv {
+		if err := runDirenvCommand(cfg, root); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if !dryRun {
+		fmt.Println("\nDone. Run lazyhydra to apply overrides.")
+	}
+	return nil
+}
+
+// initializer creates files for `lazyhydra init`, reporting each one. The first error
+// stops further changes.
+type initializer struct {
+	store  fsys.Store
+	root   string
+	dryRun bool
+	err    error
+}
+
+// report prints an action on a path, relative to the project root when inside it
+func (in *initializer) report(action, path string) {
+	if rel, err := filepath.Rel(in.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	if in.dryRun && action != "exists" {
+		action = "would " + strings.TrimSuffix(action, "d")
+	}
+	fmt.Printf("  %-14s %s\n", action, path)
+}
+
+func (in *initializer) mkdir(dir string) {
+	if in.err != nil {
+		return
+	}
+	if _, err := in.store.Stat(dir); err == nil {
+		in.report("exists", dir)
+		return
+	}
+	in.report("created", dir)
+	if !in.dryRun {
+		in.err = in.store.MkdirAll(dir, 0755)
+	}
+}
+
+func (in *initializer) writeFile(path, content string) {
+	if in.err != nil {
+		return
+	}
+	if _, err := in.store.Stat(path); err == nil {
+		in.report("exists", path)
+		return
+	}
+	in.report("created", path)
+	if !in.dryRun {
+		in.err = in.store.WriteFile(path, []byte(content), 0644)
+	}
+}
+
+// envFile writes the project's lazyhydra lines to the env file, after any lines it
+// already has, so the override variables are defined from the start
+func (in *initializer) envFile(p *state.Project) error {
+	path := p.EnvFilePath()
+	existing, err := in.store.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	found := err == nil
+	if in.dryRun {
+		if found {
+			in.report("updated", path)
+		} else {
+			in.report("created", path)
+		}
+		return nil
+	}
+
+	if err := p.LoadOverrides(); err != nil {
+		return fmt.Errorf("loading overrides: %w", err)
+	}
+	if _, err := p.LoadApplied(); err != nil {
+		return fmt.Errorf("loading persisted state: %w", err)
+	}
+	if !found {
+		if err := in.store.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := in.store.WriteFile(path, []byte(initEnvHeader), 0644); err != nil {
+			return err
+		}
+	}
+	content, err := p.WriteEnvFile()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !found:
+		in.report("created", path)
+	case bytes.Equal(existing, content):
+		in.report("exists", path)
+	default:
+		in.report("updated", path)
+	}
+	return nil
+}
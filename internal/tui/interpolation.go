@@ -0,0 +1,262 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// interpolationPattern matches OmegaConf interpolations such as ${model.lr} or ${oc.env:HOME}
+var interpolationPattern = regexp.MustCompile(`\$\{([^${}]+)\}`)
+
+// maxInterpolationDepth bounds how many interpolations are followed through other values
+const maxInterpolationDepth = 10
+
+// hasInterpolations reports whether content contains OmegaConf interpolations
+func hasInterpolations(content string) bool {
+	return interpolationPattern.MatchString(content)
+}
+
+// composeConfig builds a best-effort approximation of the composed Hydra config: the
+// primary config, the config group options selected in its defaults list, the applied
+// value overrides, and finally the given override's own content. It does not follow
+// nested defaults lists or apply Hydra's package directives beyond _global_.
+func (app *App) composeConfig(o *override.Override) map[string]interface{} {
+	hydraDir := app.Config.ExpandPath(app.Config.HydraConfigsDir)
+	root := make(map[string]interface{})
+
+	primary := app.loadYAMLMap(filepath.Join(hydraDir, app.Config.PrimaryConfig+".yaml"))
+	defaults, _ := primary["defaults"].([]interface{})
+	delete(primary, "defaults")
+
+	for _, entry := range defaults {
+		switch d := entry.(type) {
+		case string:
+			if d != "_self_" {
+				mergeMaps(root, app.loadYAMLMap(filepath.Join(hydraDir, d+".yaml")))
+			}
+		case map[string]interface{}:
+			for group, option := range d {
+				name, ok := option.(string)
+				if !ok || strings.HasPrefix(group, "override ") {
+					continue
+				}
+				path := filepath.Join(hydraDir, filepath.FromSlash(group), name+".yaml")
+				content := app.loadYAMLMap(path)
+				if app.isGlobalPackage(path) {
+					mergeMaps(root, content)
+				} else {
+					setPath(root, strings.ReplaceAll(group, "/", "."), content)
+				}
+			}
+		}
+	}
+	mergeMaps(root, primary)
+
+	// Applied value overrides win over the config files
+	for _, applied := range app.getAppliedOverrides() {
+		if applied.Block == "" && applied != o {
+			var values map[string]interface{}
+			if yaml.Unmarshal([]byte(applied.RenderedContent()), &values) == nil {
+				mergeMaps(root, values)
+			}
+		}
+	}
+
+	// The previewed override itself is merged at its block, or the root for value overrides
+	var own map[string]interface{}
+	if yaml.Unmarshal([]byte(o.RenderedContent()), &own) == nil && own != nil {
+		if o.Block == "" {
+			mergeMaps(root, own)
+		} else {
+			setPath(root, o.Block, own)
+		}
+	}
+
+	return root
+}
+
+// loadYAMLMap reads a YAML mapping from a file, returning an empty map on any error
+func (app *App) loadYAMLMap(path string) map[string]interface{} {
+	result := make(map[string]interface{})
+	if data, err := app.FS.ReadFile(path); err == nil {
+		yaml.Unmarshal(data, &result)
+	}
+	if result == nil {
+		result = make(map[string]interface{})
+	}
+	return result
+}
+
+// isGlobalPackage reports whether a config file declares # @package _global_
+func (app *App) isGlobalPackage(path string) bool {
+	data, err := app.FS.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "# @package") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "# @package")) == "_global_"
+		}
+	}
+	return false
+}
+
+// mergeMaps recursively merges src into dst, with src winning on conflicts
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// setPath sets a dotted key in a nested map, merging when both sides are maps
+func setPath(root map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	node := root
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := node[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			node[key] = child
+		}
+		node = child
+	}
+
+	last := keys[len(keys)-1]
+	if valueMap, ok := value.(map[string]interface{}); ok {
+		if existing, ok := node[last].(map[string]interface{}); ok {
+			mergeMaps(existing, valueMap)
+			return
+		}
+	}
+	node[last] = value
+}
+
+// lookupPath finds a dotted key (list items by index, as in a.0 or a[0]) in a nested map
+func lookupPath(root map[string]interface{}, path string) (interface{}, bool) {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	var node interface{} = root
+	for _, key := range strings.Split(path, ".") {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			value, ok := n[key]
+			if !ok {
+				return nil, false
+			}
+			node = value
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, false
+			}
+			node = n[i]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// resolveInterpolation resolves the expression inside ${...} against the composed config.
+// Supported: absolute keys and the oc.env resolver. Relative keys and other resolvers
+// are reported as unresolved.
+func resolveInterpolation(root map[string]interface{}, expr string, depth int) (string, error) {
+	if depth > maxInterpolationDepth {
+		return "", fmt.Errorf("interpolation cycle")
+	}
+	expr = strings.TrimSpace(expr)
+
+	if name, ok := strings.CutPrefix(expr, "oc.env:"); ok {
+		name, fallback, hasFallback := strings.Cut(name, ",")
+		if value, ok := os.LookupEnv(strings.TrimSpace(name)); ok {
+			return value, nil
+		}
+		if hasFallback {
+			return strings.Trim(strings.TrimSpace(fallback), `'"`), nil
+		}
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	if strings.Contains(expr, ":") {
+		return "", fmt.Errorf("resolver %s is not supported", strings.SplitN(expr, ":", 2)[0])
+	}
+	if strings.HasPrefix(expr, ".") {
+		return "", fmt.Errorf("relative interpolation is not supported")
+	}
+
+	value, ok := lookupPath(root, expr)
+	if !ok {
+		return "", fmt.Errorf("%s not found", expr)
+	}
+
+	var text string
+	switch v := value.(type) {
+	case map[string]interface{}, []interface{}:
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		text = strings.TrimSpace(string(out))
+	case nil:
+		text = "null"
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	// Values can themselves be interpolations
+	var resolveErr error
+	text = interpolationPattern.ReplaceAllStringFunc(text, func(match string) string {
+		resolved, err := resolveInterpolation(root, match[2:len(match)-1], depth+1)
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return resolved
+	})
+	return text, resolveErr
+}
+
+// formatResolvedContent returns override.yaml with its interpolations replaced by their
+// resolved values, highlighted green, or marked red when they cannot be resolved.
+func (app *App) formatResolvedContent(o *override.Override) string {
+	root := app.composeConfig(o)
+
+	var b strings.Builder
+	content := o.RenderedContent()
+	last := 0
+	for _, loc := range interpolationPattern.FindAllStringSubmatchIndex(content, -1) {
+		b.WriteString(tview.Escape(content[last:loc[0]]))
+		expr := content[loc[2]:loc[3]]
+		if value, err := resolveInterpolation(root, expr, 0); err == nil {
+			b.WriteString("[green]" + tview.Escape(value) + "[-]")
+		} else {
+			fmt.Fprintf(&b, "[red]%s[-] [darkgray](%s)[-]", tview.Escape(content[loc[0]:loc[1]]), tview.Escape(err.Error()))
+		}
+		last = loc[1]
+	}
+	b.WriteString(tview.Escape(content[last:]))
+	return b.String()
+}
+
+// toggleResolvePreview switches the content view between raw and resolved interpolations
+func (app *App) toggleResolvePreview() {
+	app.resolvePreview = !app.resolvePreview
+	app.updateContentAndInfo()
+	if app.resolvePreview {
+		app.showMessage("Showing resolved interpolations")
+	} else {
+		app.showMessage("Showing raw interpolations")
+	}
+}
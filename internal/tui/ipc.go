@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ramy/lazyhydra/internal/logging"
+)
+
+// ipcVersion is the version of the `lazyhydra ipc` message schema. It is bumped only
+// for changes that break existing clients.
+const ipcVersion = 1
+
+// ipcRequest is a line a client writes to stdin
+type ipcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		Name  string   `json:"name"`
+		Names []string `json:"names"`
+	} `json:"params"`
+}
+
+// ipcMessage is a line lazyhydra writes to stdout: a response carrying the request's id,
+// or an event carrying its name
+type ipcMessage struct {
+	ID      json.RawMessage `json:"id,omitempty"`
+	Event   string          `json:"event,omitempty"`
+	Version int             `json:"version,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// overrideFilesJSON is the result of the open method
+type overrideFilesJSON struct {
+	Name         string `json:"name"`
+	Folder       string `json:"folder"`
+	ApplyMD      string `json:"apply_md"`
+	OverrideYAML string `json:"override_yaml"`
+}
+
+// ipcSession serves one client over newline-delimited JSON
+type ipcSession struct {
+	server
+	out     io.Writer
+	writeMu sync.Mutex
+	last    []byte // last state sent in a changed event
+}
+
+// send writes one message as a single line
+func (s *ipcSession) send(msg ipcMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		data, _ = json.Marshal(ipcMessage{ID: msg.ID, Error: err.Error()})
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
+
+// call runs a request against freshly loaded state
+func (s *ipcSession) call(req ipcRequest) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	app := s.app
+	switch req.Method {
+	case "list":
+		return app.buildListJSON(), nil
+	case "status":
+		return app.buildStatusJSON(), nil
+	case "print":
+		return map[string]string{"override_string": strings.ReplaceAll(app.BuildString(), "\n", " ")}, nil
+	case "apply", "remove":
+		if _, err := s.applyChange(req.Method, req.Params.Names); err != nil {
+			return nil, err
+		}
+		return app.buildStatusJSON(), nil
+	case "open":
+		o := app.Find(req.Params.Name)
+		if o == nil {
+			return nil, fmt.Errorf("override %q not found", req.Params.Name)
+		}
+		return overrideFilesJSON{
+			Name:         o.Name,
+			Folder:       o.FolderPath,
+			ApplyMD:      filepath.Join(o.FolderPath, "apply.md"),
+			OverrideYAML: filepath.Join(o.FolderPath, "override.yaml"),
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown method %q", req.Method)
+}
+
+// notifyChanges sends a changed event with the full list when the overrides or the
+// applied state differ from what the client last saw
+func (s *ipcSession) notifyChanges() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		logging.Logger.Debug("ipc reload failed", "error", err)
+		return
+	}
+	list := s.app.buildListJSON()
+	data, _ := json.Marshal(list)
+	if bytes.Equal(data, s.last) {
+		return
+	}
+	s.last = data
+	s.send(ipcMessage{Event: "changed", Result: list})
+}
+
+// watch sends changed events for edits to the overrides and the env file, whether they
+// come from this session, the TUI or anything else
+func (s *ipcSession) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	overridesDir := s.app.Config.ExpandPath(s.app.Config.OverridesDir)
+	envPath := s.app.EnvFilePath()
+	addDirs := func() {
+		filepath.WalkDir(overridesDir, func(path string, d os.DirEntry, err error) error {
+			if err == nil && d.IsDir() {
+				watcher.Add(path)
+			}
+			return nil
+		})
+	}
+	addDirs()
+	watcher.Add(filepath.Dir(envPath))
+
+	go func() {
+		defer watcher.Close()
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Dir(event.Name) == filepath.Dir(envPath) && event.Name != envPath &&
+					event.Name != overridesDir {
+					continue
+				}
+				timer.Reset(watchDebounce)
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-timer.C:
+				addDirs()
+				s.notifyChanges()
+			}
+		}
+	}()
+	return nil
+}
+
+// runIPC handles `lazyhydra ipc`, a protocol for editor plugins over stdin and stdout.
+// Each line is one JSON message. It starts with a ready event, answers requests until
+// stdin closes, and sends a changed event whenever the state changes.
+func (app *App) runIPC(env string) error {
+	s := &ipcSession{server: server{app: app, env: env}, out: os.Stdout}
+	s.last, _ = json.Marshal(app.buildListJSON())
+
+	s.send(ipcMessage{Event: "ready", Version: ipcVersion})
+	if err := s.watch(); err != nil {
+		logging.Logger.Debug("ipc cannot watch for changes", "error", err)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req ipcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.send(ipcMessage{Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+		result, err := s.call(req)
+		if err != nil {
+			s.send(ipcMessage{ID: req.ID, Error: err.Error()})
+			continue
+		}
+		s.send(ipcMessage{ID: req.ID, Result: result})
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// jobTailLines is how many lines of the selected job's output the jobs panel shows
+const jobTailLines = 12
+
+// addJob records a started job, dropping the oldest finished jobs beyond maxJobs
+func (app *App) addJob(j *job) {
+	app.jobs = append(app.jobs, j)
+	for len(app.jobs) > maxJobs {
+		i := slices.IndexFunc(app.jobs, func(j *job) bool { return j.done })
+		if i < 0 {
+			break
+		}
+		app.jobs = slices.Delete(app.jobs, i, i+1)
+	}
+}
+
+// runningJobs returns how many jobs are still running
+func (app *App) runningJobs() int {
+	n := 0
+	for _, j := range app.jobs {
+		if !j.done {
+			n++
+		}
+	}
+	return n
+}
+
+// reportJob tells how a job that finished in the background went
+func (app *App) reportJob(j *job) {
+	switch {
+	case j.killed:
+		app.showMessage("%s stopped", j.name)
+	case j.err != nil:
+		app.setStatusMessage(fmt.Sprintf("%s failed after %s: %v (Ctrl+T for jobs)", j.name, j.runtime(), j.err), true)
+	default:
+		app.showMessage("%s finished in %s", j.name, j.runtime())
+	}
+}
+
+// jobsNewestFirst returns the jobs in the order the jobs panel lists them
+func (app *App) jobsNewestFirst() []*job {
+	jobs := slices.Clone(app.jobs)
+	slices.Reverse(jobs)
+	return jobs
+}
+
+// selectedJob returns the job under the cursor of the jobs panel
+func (app *App) selectedJob() *job {
+	jobs := app.jobsNewestFirst()
+	i := app.jobsList.GetCurrentItem()
+	if i < 0 || i >= len(jobs) {
+		return nil
+	}
+	return jobs[i]
+}
+
+// showJobs opens the jobs panel: every job started from the TUI with its state and
+// runtime, and the tail of the selected job's output
+func (app *App) showJobs() {
+	if len(app.jobs) == 0 {
+		app.showMessage("No jobs yet; x runs one")
+		return
+	}
+	app.jobsOpen = true
+
+	app.jobsList = tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+	app.jobsList.SetBorder(true).
+		SetTitle(" Jobs ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.jobsTail = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	app.jobsTail.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.refreshJobs()
+	app.jobsList.SetChangedFunc(func(int, string, string, rune) {
+		app.refreshJobTail()
+	})
+	app.jobsList.SetSelectedFunc(func(int, string, string, rune) {
+		if j := app.selectedJob(); j != nil {
+			app.closeJobs()
+			app.currentJob = j
+			app.showRunner()
+		}
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(app.jobsList, min(len(app.jobs), 8)+2, 0, true).
+		AddItem(app.jobsTail, jobTailLines+2, 0, false)
+	app.pages.AddPage("jobs", modal(layout, 110, min(len(app.jobs), 8)+jobTailLines+4), true, true)
+	app.app.SetFocus(app.jobsList)
+}
+
+func (app *App) closeJobs() {
+	app.jobsOpen = false
+	app.pages.RemovePage("jobs")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// refreshJobs updates the states and runtimes in the jobs panel when it is open
+func (app *App) refreshJobs() {
+	if !app.jobsOpen {
+		return
+	}
+	current := app.jobsList.GetCurrentItem()
+	app.jobsList.Clear()
+	for _, j := range app.jobsNewestFirst() {
+		app.jobsList.AddItem(fmt.Sprintf("%s %-12s %-8s %6s  [darkgray]%s[-]",
+			j.status(), tview.Escape(j.name), j.label(), j.runtime(), tview.Escape(j.command)), "", 0, nil)
+	}
+	app.jobsList.SetCurrentItem(max(min(current, app.jobsList.GetItemCount()-1), 0))
+	app.refreshJobTail()
+}
+
+// refreshJobTail shows the last lines of the selected job's output
+func (app *App) refreshJobTail() {
+	j := app.selectedJob()
+	if j == nil {
+		app.jobsTail.SetText("")
+		return
+	}
+	lines := strings.Split(strings.TrimRight(j.output.GetText(true), "\n"), "\n")
+	lines = lines[max(len(lines)-jobTailLines, 0):]
+	app.jobsTail.SetTitle(fmt.Sprintf(" %s: %s ", j.name, j.state()))
+	app.jobsTail.SetText(tview.Escape(strings.Join(lines, "\n")))
+}
+
+// stopSelectedJob stops the job under the cursor of the jobs panel
+func (app *App) stopSelectedJob() {
+	if j := app.selectedJob(); j != nil && !j.done {
+		app.killJob(j)
+	}
+}
+
+// rerunSelectedJob starts the job under the cursor again, with the command and
+// overrides it was started with
+func (app *App) rerunSelectedJob() {
+	j := app.selectedJob()
+	if j == nil {
+		return
+	}
+	app.closeJobs()
+	app.launchJob(j.name, j.command, j.env)
+}
+
+// removeSelectedJob drops a finished job from the jobs panel
+func (app *App) removeSelectedJob() {
+	j := app.selectedJob()
+	if j == nil || !j.done {
+		return
+	}
+	app.jobs = slices.DeleteFunc(app.jobs, func(other *job) bool { return other == j })
+	if app.currentJob == j {
+		app.currentJob = nil
+	}
+	if len(app.jobs) == 0 {
+		app.closeJobs()
+		return
+	}
+	app.refreshJobs()
+}
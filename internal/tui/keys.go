@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// keyHelp is a key of the main view and what it does
+type keyHelp struct {
+	keys string
+	desc string
+}
+
+// keySections lists the keys of the main view for the help overlay and --help. Keep it
+// in step with setupKeybindings.
+var keySections = []struct {
+	title string
+	keys  []keyHelp
+}{
+	{"Navigation", []keyHelp{
+		{"1, 2", "Jump to panel"},
+		{"Tab / Shift+Tab", "Cycle panels"},
+		{"h / l", "Previous / Next panel"},
+		{"j / k / arrows", "Move cursor up / down (5j moves five rows)"},
+		{"gg / G", "Jump to the top / bottom of the list (4G to the 4th row)"},
+		{"PgDn / PgUp", "Page down / up the list (also Ctrl+F / Ctrl+B)"},
+		{"J / K", "Scroll the content view"},
+		{"Ctrl+D / Ctrl+U", "Scroll the content view half a page down / up"},
+		{"( / )", "Scroll the content view left / right (wrapping off)"},
+		{"{ / }", "Previous / next paragraph of the content view"},
+		{"+ / _", "Zoom: focused list full screen, then content, then back"},
+		{"< / >", "Shrink / Grow the lists next to the content"},
+		{"|", "Put the lists above the content or back beside it"},
+	}},
+	{"Actions", []keyHelp{
+		{"Space / Enter", "Apply or remove override (collapse/expand on a folder, prune a missing one)"},
+		{"n", "Create new override (recreate a missing one as a stub)"},
+		{"d", "Duplicate override"},
+		{"D", "Delete override (moves it to the trash)"},
+		{"r", "Rename override (optionally updating composites that include it)"},
+		{"m", "Mark override for batch apply/remove/delete"},
+		{"a", "Archive or unarchive override"},
+		{"B", "Set or clear a frontmatter field, tag or block prefix of the marked overrides"},
+		{"A", "Apply all available overrides with a tag"},
+		{"' / Ctrl+O", "Recently applied/removed overrides; 1-9 toggles one back"},
+		{"C", "Clear all applied overrides"},
+		{"e", "Edit apply.md in $EDITOR"},
+		{"E", "Edit override.yaml in $EDITOR"},
+		{"V", "Browse earlier versions of the override's files, diff and revert"},
+		{"i", "Edit top-level values inline (or parameters)"},
+		{"F", "Search and replace across all override.yaml files, previewing each file"},
+		{"f", "Create an override from edits to the block's base config"},
+		{"b", "Import config group options from hydra_configs_dir"},
+		{"y", "Copy selected override string"},
+		{"Y", "Copy all override strings"},
+		{"Ctrl+Y", "Copy override.yaml (or the merged/resolved preview shown)"},
+		{"Ctrl+P", "Copy the override folder's path"},
+		{"o", "Open the override folder in the file manager"},
+		{"t", "Preview the full command from command_template (y copies it)"},
+		{"x", "Run a run target (or run_command) with the applied overrides"},
+		{"Ctrl+T", "Jobs: state, runtime and output of runs; stop, rerun or remove them"},
+		{"X", "Run a plugin from ~/.config/lazyhydra/plugins/"},
+	}},
+	{"View", []keyHelp{
+		{"/", "Search override.yaml and apply.md contents"},
+		{"s", "Cycle sort: name, applied, modified, priority"},
+		{"p", "Pin/unpin override to the top of Available"},
+		{"z", "Collapse/expand the folder or composite override"},
+		{"H", "Show/hide archived overrides in Available"},
+		{"M", "Toggle rendered / raw apply.md"},
+		{"w", "Toggle word wrap in the content view"},
+		{"#", "Toggle line numbers for override.yaml"},
+		{"=", "Toggle type and block columns in the lists"},
+		{"Ctrl+G", "Group applied overrides under a header per block"},
+		{"I", "Toggle resolved ${...} interpolation preview"},
+		{"P", "Toggle the merged config of the selected block"},
+		{"c", "Explain applied overrides that share a block"},
+		{"L", "List overrides with incomplete metadata"},
+		{"U", "Usage statistics: most used, never used, last applied"},
+		{"v", "View the env file (e to edit it)"},
+		{"Ctrl+E", "Check that direnv loads the env file and how to fix it (a runs direnv allow)"},
+		{"@", "Toggle the command log panel"},
+		{"!", "Show recent errors"},
+	}},
+	{"State", []keyHelp{
+		{"S", "Switch environment (independent applied sets)"},
+		{"W", "Switch to another bookmarked project without restarting"},
+		{"O", "Save or restore a snapshot of the applied state"},
+		{"T", "Browse the trash to restore or purge deleted overrides"},
+		{"R", "Reload config.yaml (also done when it changes)"},
+	}},
+	{"General", []keyHelp{
+		{"Q<reg> ... Q", "Record keys into macro register a-z or 0-9"},
+		{"&<reg> / &&", "Replay a macro register / the last one replayed"},
+		{"?", "Show help"},
+		{"Esc", "Clear marks, or quit when nothing is marked"},
+		{"q", "Quit"},
+	}},
+}
+
+// keyHelpWidth is the width of the key column in help listings
+const keyHelpWidth = 18
+
+// usageKeys returns the key listing for --help
+func usageKeys() string {
+	var b strings.Builder
+	for _, section := range keySections {
+		for _, k := range section.keys {
+			fmt.Fprintf(&b, "  %-*s  %s\n", keyHelpWidth, k.keys, k.desc)
+		}
+	}
+	return b.String()
+}
+
+// helpText returns the help overlay's text, limited to the keys whose key or
+// description contains query when it is not empty
+func (app *App) helpText(query string) string {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var b strings.Builder
+	if query == "" {
+		b.WriteString("[yellow::b]LazyHydra - Hydra Override Manager[-:-:-]\n\n")
+	}
+	found := false
+	for _, section := range keySections {
+		var lines []string
+		for _, k := range section.keys {
+			if query != "" && !strings.Contains(strings.ToLower(k.keys+" "+k.desc), query) {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %-*s  %s", keyHelpWidth, tview.Escape(k.keys), tview.Escape(k.desc)))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		found = true
+		fmt.Fprintf(&b, "[green]%s:[-]\n%s\n\n", section.title, strings.Join(lines, "\n"))
+	}
+	if query != "" {
+		if !found {
+			fmt.Fprintf(&b, "[darkgray]No keys match %q[-]", query)
+		}
+		return strings.TrimRight(b.String(), "\n")
+	}
+
+	fmt.Fprintf(&b, "[green]Persistence:[-]\n  Applied overrides are saved to:\n  %s\n\n", tview.Escape(app.EnvFilePath()))
+	fmt.Fprintf(&b, "[green]Environment Variables:[-]\n  %-*s  Encoded applied overrides\n  %-*s  Override string for CLI",
+		keyHelpWidth, tview.Escape(app.Config.EnvVarName), keyHelpWidth, tview.Escape(app.Config.OverrideStrVarName))
+	for _, name := range app.Config.DerivedVarNames() {
+		fmt.Fprintf(&b, "\n  %-*s  Applied override %s", keyHelpWidth, tview.Escape(name), app.Config.DerivedVars[name])
+	}
+	for _, group := range app.ExportGroups() {
+		fmt.Fprintf(&b, "\n  %-*s  Override string for export group %s", keyHelpWidth, tview.Escape(app.Config.GroupVar(group)), tview.Escape(group))
+	}
+	return b.String()
+}
+
+// showHelp opens the help overlay. It scrolls with j/k, and / filters it to the keys
+// matching a query.
+func (app *App) showHelp() {
+	app.helpOpen = true
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(app.helpText(""))
+
+	app.helpSearch = tview.NewInputField().
+		SetLabel("/").
+		SetPlaceholder("filter keys").
+		SetPlaceholderTextColor(tcell.ColorGray).
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetChangedFunc(func(query string) {
+			view.SetText(app.helpText(query))
+			view.ScrollToBeginning()
+		})
+	app.helpSearch.SetDoneFunc(func(key tcell.Key) {
+		// Esc drops the query; Enter keeps it and goes back to scrolling
+		if key == tcell.KeyEscape {
+			app.helpSearch.SetText("")
+		}
+		app.app.SetFocus(view)
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(view, 0, 1, true).
+		AddItem(app.helpSearch, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitle(" Help ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("help", modal(layout, 84, 40), true, true)
+	app.app.SetFocus(view)
+}
+
+func (app *App) closeHelp() {
+	app.helpOpen = false
+	app.helpSearch = nil
+	app.pages.RemovePage("help")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
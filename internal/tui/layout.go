@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/rivo/tview"
+)
+
+// Zoom levels, cycled with + and _
+const (
+	zoomNone    = iota
+	zoomPanel   // the focused list fills the screen
+	zoomContent // Override Content fills the screen, e.g. to read a long override.yaml
+	zoomLevels
+)
+
+// splitSteps is the number of steps the screen width (or height when vertical) is
+// divided into for the split between the lists and the content; the lists start with
+// layout.split of them.
+const splitSteps = 10
+
+// split returns how many splitSteps the lists take up
+func (app *App) split() int {
+	if app.ui.Split <= 0 || app.ui.Split >= splitSteps {
+		return app.Config.Layout.Split
+	}
+	return app.ui.Split
+}
+
+// layoutFromConfig arranges the panels as the config's layout says: the direction, the
+// weights of the lists and of the content side, and whether the override string shows
+func (app *App) layoutFromConfig() {
+	layout := app.Config.Layout
+	app.vertical = layout.Direction == config.LayoutVertical
+	app.leftFlex.Clear().
+		AddItem(app.availableList, 0, layout.Lists[0], true).
+		AddItem(app.appliedList, 0, layout.Lists[1], false)
+	app.rightFlex.Clear().
+		AddItem(app.contentView, 0, layout.Content[0], true)
+	if layout.OverrideString {
+		app.rightFlex.AddItem(app.overrideStringView, 0, layout.Content[1], false)
+	}
+	if app.commandLogShown {
+		app.rightFlex.AddItem(app.commandLogView, 0, 1, false)
+	}
+	app.applyLayout()
+}
+
+// applyLayout arranges the main area for the zoom level, direction and split
+func (app *App) applyLayout() {
+	app.mainFlex.Clear()
+	switch app.zoom {
+	case zoomPanel:
+		app.mainFlex.AddItem(app.panels[app.currentPanelIdx], 0, 1, true)
+	case zoomContent:
+		app.mainFlex.AddItem(app.contentView, 0, 1, false)
+	default:
+		// Lists above the content sit side by side
+		direction, lists := tview.FlexColumn, tview.FlexRow
+		if app.vertical {
+			direction, lists = tview.FlexRow, tview.FlexColumn
+		}
+		app.mainFlex.SetDirection(direction)
+		app.leftFlex.SetDirection(lists)
+		app.mainFlex.
+			AddItem(app.leftFlex, 0, app.split(), true).
+			AddItem(app.rightFlex, 0, splitSteps-app.split(), false)
+	}
+}
+
+// toggleDirection puts the lists above the content, or back beside it, until quit or
+// the config is reloaded
+func (app *App) toggleDirection() {
+	app.vertical = !app.vertical
+	if app.zoom != zoomNone {
+		app.zoom = zoomNone
+		app.app.SetFocus(app.panels[app.currentPanelIdx])
+	}
+	app.applyLayout()
+	if app.vertical {
+		app.showMessage("Lists above the content")
+	} else {
+		app.showMessage("Lists beside the content")
+	}
+}
+
+// cycleZoom moves to the next zoom level, or the previous one when step is -1
+func (app *App) cycleZoom(step int) {
+	app.zoom = (app.zoom + step + zoomLevels) % zoomLevels
+	app.applyLayout()
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+}
+
+// resizeSplit widens the lists by delta steps, keeping both sides visible, and
+// remembers the split for the next session
+func (app *App) resizeSplit(delta int) {
+	split := app.split() + delta
+	if split < 1 || split > splitSteps-1 {
+		return
+	}
+	app.ui.Split = split
+	app.saveUIState()
+	if app.zoom != zoomNone {
+		app.zoom = zoomNone
+		app.app.SetFocus(app.panels[app.currentPanelIdx])
+	}
+	app.applyLayout()
+}
+
+// centered draws its content in the middle of the screen at up to width x height
+// cells. Nothing else is drawn, so the page below shows around it.
+type centered struct {
+	*tview.Box
+	content       tview.Primitive
+	width, height int
+}
+
+func (c *centered) Draw(screen tcell.Screen) {
+	x, y, w, h := c.GetRect()
+	width, height := min(c.width, w), min(c.height, h)
+	c.content.SetRect(x+(w-width)/2, y+(h-height)/2, width, height)
+	c.content.Draw(screen)
+}
+
+func (c *centered) Focus(delegate func(p tview.Primitive)) {
+	delegate(c.content)
+}
+
+func (c *centered) HasFocus() bool {
+	return c.content.HasFocus()
+}
+
+func (c *centered) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return c.content.InputHandler()
+}
+
+func (c *centered) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive) {
+	return c.content.MouseHandler()
+}
+
+func (c *centered) PasteHandler() func(text string, setFocus func(p tview.Primitive)) {
+	return c.content.PasteHandler()
+}
+
+// toggleWrap switches word wrapping in the content view and remembers it for the next
+// session. Without wrapping, long lines scroll sideways with ( and ).
+func (app *App) toggleWrap() {
+	app.ui.NoWrap = !app.ui.NoWrap
+	app.saveUIState()
+	app.contentView.SetWrap(!app.ui.NoWrap)
+	if app.ui.NoWrap {
+		app.showMessage("Wrapping off; scroll long lines with ( and )")
+	} else {
+		row, _ := app.contentView.GetScrollOffset()
+		app.contentView.ScrollTo(row, 0)
+		app.showMessage("Wrapping on")
+	}
+}
+
+// toggleLineNumbers shows or hides line numbers next to override.yaml in the content
+// view and remembers it for the next session
+func (app *App) toggleLineNumbers() {
+	app.ui.LineNumbers = !app.ui.LineNumbers
+	app.saveUIState()
+	app.updateContentAndInfo()
+}
+
+// numberLines prefixes each line of a highlighted file with its line number when line
+// numbers are shown
+func (app *App) numberLines(text string) string {
+	if !app.ui.LineNumbers {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	width := len(strconv.Itoa(len(lines)))
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf("[darkgray]%*d[-] %s", width, i+1, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scrollContentSideways scrolls the content view by cols columns, right when positive.
+// It only has an effect while wrapping is off.
+func (app *App) scrollContentSideways(cols int) {
+	row, col := app.contentView.GetScrollOffset()
+	app.contentView.ScrollTo(row, max(col+cols, 0))
+}
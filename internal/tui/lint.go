@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// incompletePrefix returns the list prefix shown before incomplete overrides
+func incompletePrefix(o *override.Override) string {
+	if o.IsIncomplete() {
+		return "[red]✗[-] "
+	}
+	return ""
+}
+
+// rejectIncomplete reports an error and returns true when an override cannot be applied:
+// it is incomplete, or uses what the project's Hydra version does not support
+func (app *App) rejectIncomplete(o *override.Override) bool {
+	if problems := o.LintProblems(); len(problems) > 0 {
+		app.showError(fmt.Errorf("%s is incomplete: %s (L lists all problems)", o.Name, problems[0]))
+		return true
+	}
+	if problems := o.HydraProblems(app.Config); len(problems) > 0 {
+		app.showError(fmt.Errorf("%s: %s (L lists all problems)", o.Name, problems[0]))
+		return true
+	}
+	return false
+}
+
+// showLint lists the metadata problems of every incomplete override
+func (app *App) showLint() {
+	app.lintOpen = true
+
+	var b strings.Builder
+	if version, source := app.Config.Hydra(); version != "" {
+		fmt.Fprintf(&b, "[darkgray]Checked against Hydra %s (%s)[-]\n\n", version, tview.Escape(source))
+	}
+	count, unsupported := 0, 0
+	for _, o := range app.Overrides {
+		problems := o.LintProblems()
+		compat := o.HydraProblems(app.Config)
+		if len(problems) == 0 && len(compat) == 0 {
+			continue
+		}
+		if len(problems) > 0 {
+			count++
+		} else {
+			unsupported++
+		}
+		fmt.Fprintf(&b, "[yellow::b]%s[-:-:-] [darkgray]%s[-]\n", o.Name, tview.Escape(o.FolderPath))
+		for _, p := range append(problems, compat...) {
+			fmt.Fprintf(&b, "  [red]✗[-] %s\n", tview.Escape(p))
+		}
+		b.WriteString("\n")
+	}
+	if count+unsupported == 0 {
+		b.WriteString("[green]All overrides have complete metadata[-]")
+	}
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true).
+		SetText(strings.TrimRight(b.String(), "\n"))
+	view.SetBorder(true).
+		SetTitle(lintTitle(count, unsupported)).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorYellow)
+
+	app.pages.AddPage("lint", modal(view, 80, 22), true, true)
+	app.app.SetFocus(view)
+}
+
+func (app *App) closeLint() {
+	app.lintOpen = false
+	app.pages.RemovePage("lint")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// lintTitle returns the title of the lint list
+func lintTitle(incomplete, unsupported int) string {
+	if unsupported == 0 {
+		return fmt.Sprintf(" Lint: %d incomplete ", incomplete)
+	}
+	return fmt.Sprintf(" Lint: %d incomplete, %d unsupported by Hydra ", incomplete, unsupported)
+}
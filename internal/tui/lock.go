@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// projectLock is the advisory lock a lazyhydra instance holds while it writes the
+// project's state, so a TUI and a batch command, or two TUIs, do not overwrite each
+// other's changes. The TUI holds it while it runs; commands only while they save.
+type projectLock struct {
+	PID     int       `yaml:"pid"`
+	Host    string    `yaml:"host"`
+	Command string    `yaml:"command"` // "TUI" or the subcommand, e.g. batch
+	Started time.Time `yaml:"started"`
+}
+
+func (l projectLock) String() string {
+	return fmt.Sprintf("%s, pid %d on %s, since %s", l.Command, l.PID, l.Host, l.Started.Format("2006-01-02 15:04"))
+}
+
+// mine reports whether this process holds the lock
+func (l projectLock) mine() bool {
+	host, _ := os.Hostname()
+	return l.PID == os.Getpid() && l.Host == host
+}
+
+// lockHeldError is returned when another running instance holds the project's lock
+type lockHeldError struct {
+	lock projectLock
+}
+
+func (e *lockHeldError) Error() string {
+	return fmt.Sprintf("another lazyhydra instance holds the lock on this project (%s); close it or pass --force to write anyway", e.lock)
+}
+
+// lockPath returns the project's lock file
+func (app *App) lockPath() string {
+	return filepath.Join(app.Root, ".lazyhydra", "lock")
+}
+
+// lockedByOther returns the lock of another running instance holding the project's
+// lock. A lock left by a process that is gone from this host is stale and ignored; one
+// from another host, on a shared or remote project, cannot be checked and counts as held.
+func (app *App) lockedByOther() (projectLock, bool) {
+	var lock projectLock
+	data, err := app.FS.ReadFile(app.lockPath())
+	if err != nil || yaml.Unmarshal(data, &lock) != nil || lock.PID == 0 || lock.mine() {
+		return lock, false
+	}
+	if host, _ := os.Hostname(); lock.Host == host && !processAlive(lock.PID) {
+		logging.Logger.Debug("ignoring stale lock", "pid", lock.PID)
+		return lock, false
+	}
+	return lock, true
+}
+
+// lockAttempts bounds how often acquireLock removes a stale lock and tries again
+const lockAttempts = 3
+
+// acquireLock takes the project's lock for command, unless another running instance
+// holds it and force is not set. The lock file is created exclusively, so of two
+// instances starting at once only one gets it; an existing lock is only replaced once
+// that fails, and only when it is stale, this instance's own or force is set.
+func (app *App) acquireLock(command string, force bool) error {
+	host, _ := os.Hostname()
+	data, err := yaml.Marshal(projectLock{PID: os.Getpid(), Host: host, Command: command, Started: time.Now()})
+	if err != nil {
+		return err
+	}
+	if err := app.FS.MkdirAll(filepath.Dir(app.lockPath()), 0755); err != nil {
+		return err
+	}
+
+	for i := 0; i < lockAttempts; i++ {
+		err := app.FS.CreateFile(app.lockPath(), data, 0644)
+		if !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+
+		lock, held := app.lockedByOther()
+		switch {
+		case held && !force:
+			return &lockHeldError{lock: lock}
+		case held:
+			logging.Logger.Info("taking over lock", "from", lock.String())
+			return fsys.WriteFileAtomic(app.FS, app.lockPath(), data, 0644)
+		case lock.mine():
+			return fsys.WriteFileAtomic(app.FS, app.lockPath(), data, 0644)
+		}
+
+		// A stale lock is removed and the lock created again, rather than overwritten,
+		// so that only one of the instances finding it stale gets the lock
+		if err := app.FS.Remove(app.lockPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("could not take the lock %s: it keeps being recreated", app.lockPath())
+}
+
+// writeLock writes lock as the project's lock file, replacing the one there
+func (app *App) writeLock(lock projectLock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	if err := app.FS.MkdirAll(filepath.Dir(app.lockPath()), 0755); err != nil {
+		return err
+	}
+	return fsys.WriteFileAtomic(app.FS, app.lockPath(), data, 0644)
+}
+
+// releaseLock removes the project's lock if this instance holds it, leaving a lock that
+// was taken over alone
+func (app *App) releaseLock() {
+	var lock projectLock
+	data, err := app.FS.ReadFile(app.lockPath())
+	if err != nil || yaml.Unmarshal(data, &lock) != nil || !lock.mine() {
+		return
+	}
+	if err := app.FS.Remove(app.lockPath()); err != nil {
+		logging.Logger.Warn("could not release lock", "error", err)
+	}
+}
+
+// lockForSave makes sure this instance holds the lock before the state is written. The
+// TUI keeps it; a command releases it with the returned function once it has saved,
+// handing it back to the instance it was forced from.
+func (app *App) lockForSave() (func(), error) {
+	if app.app != nil {
+		return func() {}, app.acquireLock("TUI", app.forceLock)
+	}
+	previous, held := app.lockedByOther()
+	if err := app.acquireLock(app.lockCommand, app.forceLock); err != nil {
+		return nil, err
+	}
+	if held {
+		return func() { app.writeLock(previous) }, nil
+	}
+	return app.releaseLock, nil
+}
+
+// lockTUI takes the project's lock for the TUI. When another instance holds it, it asks
+// on the terminal whether to open read-only, take the lock over or quit, and returns
+// false to quit.
+func (app *App) lockTUI() bool {
+	err := app.acquireLock("TUI", app.forceLock)
+	held, ok := err.(*lockHeldError)
+	if !ok {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not lock the project: %v\n", err)
+		}
+		return true
+	}
+
+	fmt.Fprintf(os.Stderr, "Another lazyhydra instance holds the lock on %s:\n  %s\n", app.Root, held.lock)
+	fmt.Fprint(os.Stderr, "Open read-only (r), take over the lock (f) or quit (q)? [r] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "f", "force":
+		if err := app.acquireLock("TUI", true); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+	case "q", "quit":
+		return false
+	default:
+		app.ReadOnly = true
+		app.lockReadOnly = true
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/state"
+	"gopkg.in/yaml.v3"
+)
+
+// readLock returns the lock file of app's project
+func readLock(t *testing.T, app *App) projectLock {
+	t.Helper()
+	data, err := app.FS.ReadFile(app.lockPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lock projectLock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		t.Fatal(err)
+	}
+	return lock
+}
+
+func TestAcquireLock(t *testing.T) {
+	store := fsys.NewMemory()
+	app := &App{Project: &state.Project{FS: store, Root: "/proj"}}
+	host, _ := os.Hostname()
+
+	if err := app.acquireLock("TUI", false); err != nil {
+		t.Fatal(err)
+	}
+	if lock := readLock(t, app); !lock.mine() || lock.Command != "TUI" {
+		t.Errorf("lock = %s, want this TUI", lock)
+	}
+
+	// Taking it again replaces this instance's own lock
+	if err := app.acquireLock("batch", false); err != nil {
+		t.Fatal(err)
+	}
+	if lock := readLock(t, app); lock.Command != "batch" {
+		t.Errorf("lock = %s, want batch", lock)
+	}
+
+	// A lock of another host cannot be checked and counts as held
+	other := projectLock{PID: 1, Host: host + "-other", Command: "TUI", Started: time.Now()}
+	if err := app.writeLock(other); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := app.acquireLock("TUI", false).(*lockHeldError); !ok {
+		t.Error("took a lock held by another instance")
+	}
+	if lock := readLock(t, app); lock.Host != other.Host {
+		t.Errorf("refused lock was overwritten with %s", lock)
+	}
+	if err := app.acquireLock("TUI", true); err != nil {
+		t.Fatal(err)
+	}
+	if lock := readLock(t, app); !lock.mine() {
+		t.Errorf("forced lock = %s, want this instance", lock)
+	}
+
+	// The lock of a process that is gone is stale and replaced
+	stale := projectLock{PID: 1 << 30, Host: host, Command: "TUI", Started: time.Now()}
+	if err := app.writeLock(stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.acquireLock("TUI", false); err != nil {
+		t.Fatalf("stale lock was not replaced: %v", err)
+	}
+	if lock := readLock(t, app); !lock.mine() {
+		t.Errorf("lock after stale = %s, want this instance", lock)
+	}
+
+	app.releaseLock()
+	if _, err := store.Stat(app.lockPath()); err == nil {
+		t.Error("released lock still exists")
+	}
+}
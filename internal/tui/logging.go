@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/logging"
+)
+
+// debugLogPath returns the file debug logs are appended to
+func debugLogPath() string {
+	return filepath.Join(config.StateDir(), "lazyhydra.log")
+}
+
+// debugLogRequested reports whether debug logging was asked for via the environment
+func debugLogRequested() bool {
+	return os.Getenv("LAZYHYDRA_LOG") == "debug"
+}
+
+// setupDebugLog points the logger at the debug log file. The returned function closes it.
+func setupDebugLog() (func(), error) {
+	path := debugLogPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening log file: %w", err)
+	}
+
+	logging.Logger = slog.New(slog.NewTextHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logging.Logger.Info("debug logging started", "pid", os.Getpid(), "args", os.Args[1:])
+
+	return func() { file.Close() }, nil
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// macroKey is one key press recorded into a macro register
+type macroKey struct {
+	key  tcell.Key
+	ch   rune
+	mods tcell.ModMask
+}
+
+// isMacroRegister reports whether r names a macro register, a-z or 0-9
+func isMacroRegister(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
+
+// handleMacroKey sees every key before the key bindings do. Q<reg> starts recording the
+// following keys into a register and Q stops it; &<reg> replays a register and && the
+// last one replayed. It returns nil for the keys it consumes.
+func (app *App) handleMacroKey(event *tcell.EventKey) *tcell.EventKey {
+	if pending := app.macroPending; pending != 0 {
+		app.macroPending = 0
+		r := event.Rune()
+		switch {
+		case event.Key() != tcell.KeyRune:
+			app.updateStatusBar()
+		case pending == 'Q' && isMacroRegister(r):
+			app.macroRecording = r
+			app.macroBuffer = nil
+			app.updateStatusBar()
+		case pending == '&' && r == '&' && app.macroLast != 0:
+			app.replayMacro(app.macroLast)
+		case pending == '&' && isMacroRegister(r):
+			app.replayMacro(r)
+		default:
+			app.updateStatusBar()
+		}
+		return nil
+	}
+
+	// Q and & only start a macro command where they are not typed into a field
+	if event.Key() == tcell.KeyRune && !app.modalOpen() {
+		switch event.Rune() {
+		case 'Q':
+			if app.macroRecording != 0 {
+				app.macros[app.macroRecording] = app.macroBuffer
+				app.showMessage("Recorded %d keys into macro %c (&%c to replay)", len(app.macroBuffer), app.macroRecording, app.macroRecording)
+				app.macroRecording = 0
+				app.macroBuffer = nil
+				return nil
+			}
+			app.macroPending = 'Q'
+			app.showMessage("Record macro into register: press a-z or 0-9")
+			return nil
+		case '&':
+			if app.macroRecording != 0 {
+				app.showMessage("Stop recording with Q before replaying a macro")
+				return nil
+			}
+			app.macroPending = '&'
+			app.showMessage("Replay macro from register: press a-z or 0-9 (& for the last one)")
+			return nil
+		}
+	}
+
+	if app.macroRecording != 0 {
+		app.macroBuffer = append(app.macroBuffer, macroKey{key: event.Key(), ch: event.Rune(), mods: event.Modifiers()})
+	}
+	return event
+}
+
+// replayMacro feeds the keys recorded in a register back through the key bindings
+func (app *App) replayMacro(register rune) {
+	keys, ok := app.macros[register]
+	if !ok {
+		app.showMessage("Macro register %c is empty; record it with Q%c", register, register)
+		return
+	}
+	app.macroLast = register
+	app.updateStatusBar()
+
+	// Queued from a goroutine, since the event queue may be shorter than the macro and
+	// this runs on the event loop that drains it
+	go func() {
+		for _, k := range keys {
+			app.app.QueueEvent(tcell.NewEventKey(k.key, k.ch, k.mods))
+		}
+	}()
+}
@@ -0,0 +1,3289 @@
+package tui
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/fsnotify/fsnotify"
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/ramy/lazyhydra/internal/state"
+	"github.com/rivo/tview"
+)
+
+func init() {
+	// Set rounded borders globally
+	tview.Borders.Horizontal = '─'
+	tview.Borders.Vertical = '│'
+	tview.Borders.TopLeft = '╭'
+	tview.Borders.TopRight = '╮'
+	tview.Borders.BottomLeft = '╰'
+	tview.Borders.BottomRight = '╯'
+}
+
+// highlightCode applies syntax highlighting to code using chroma
+func highlightCode(code, language string) string {
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	style := styles.Get("gruvbox")
+	if style == nil {
+		style = styles.Fallback
+	}
+
+	var buf strings.Builder
+	iterator, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return tview.Escape(code)
+	}
+
+	for token := iterator(); token != chroma.EOF; token = iterator() {
+		entry := style.Get(token.Type)
+		text := tview.Escape(token.Value)
+		if entry.Colour.IsSet() {
+			r, g, b := entry.Colour.Red(), entry.Colour.Green(), entry.Colour.Blue()
+			buf.WriteString(fmt.Sprintf("[#%02x%02x%02x]%s[-]", r, g, b, text))
+		} else {
+			buf.WriteString(text)
+		}
+	}
+	return buf.String()
+}
+
+// App holds the application state
+type App struct {
+	*state.Project
+	app               *tview.Application
+	pages             *tview.Pages
+	availableList     *tview.List
+	appliedList       *tview.List
+	contentView       *tview.TextView
+	overrideStringView *tview.TextView
+	statusBar         *tview.TextView
+	panels            []tview.Primitive
+	currentPanelIdx   int
+	helpOpen          bool
+	helpSearch        *tview.InputField // filter of the help overlay
+	inputOpen         bool
+	deleteOpen        bool
+	renameOpen        bool
+	renameTarget      *override.Override
+	renameNewName     string // set while asking whether to update includes of other overrides
+	valuesOpen        bool
+	duplicateOpen     bool
+	duplicateSource   *override.Override
+	templateOpen      bool
+	watcher           *fsnotify.Watcher
+	envrcHash         string
+	savedApplied      map[string]bool
+	conflictOpen      bool
+	blockConflictsOpen bool
+	lintOpen          bool
+	environmentOpen   bool
+	applyTagOpen      bool
+	snapshotsOpen     bool
+	importOpen        bool
+	importCandidates  []importCandidate
+	importSelected    map[int]bool
+	importList        *tview.List
+	diffgenOpen       bool
+	pluginsOpen       bool
+	trashOpen         bool
+	trashList         *tview.List
+	trashEntries      []trashEntry
+	restoreTarget     string
+	branch            string // checked out git branch, "" outside git or when detached
+	marked            map[string]bool
+	collapsed         map[string]bool // group folders collapsed in the Available panel
+	collapsedComposites map[string]bool // composite overrides collapsed in the Applied panel
+	ui                *uiState
+	availableRows     []availableRow
+	appliedRows       []appliedRow
+	clearOpen         bool
+	statusMessage     string
+	statusIsError     bool
+	statusSeq         int
+	runnerOpen        bool
+	envViewOpen       bool
+	searchOpen        bool
+	paramsOpen        bool
+	rawMarkdown       bool
+	versionsOpen      bool
+	versionsTarget    *override.Override
+	versions          []override.Version
+	versionsDiff      *tview.TextView
+	editDiffOpen      bool
+	editDiffTarget    *override.Override
+	editDiffFile      string
+	editDiffBefore    []byte // content before the edit shown in the edit diff, for undo
+	replaceOpen       bool
+	bulkEditOpen      bool
+	statsOpen         bool
+	recentOpen        bool
+	macros            map[rune][]macroKey // recorded with Q<reg>, replayed with &<reg>
+	macroRecording    rune                // register being recorded into, 0 when not recording
+	macroBuffer       []macroKey
+	macroPending      rune // Q or & waiting for its register
+	macroLast         rune // register replayed last, for &&
+	countPrefix       int  // Vim-style count typed before a motion, 0 when none
+	gPending          bool // the first g of gg was typed
+	replaceResults    []replaceMatch
+	replaceList       *tview.List
+	replacePreview    *tview.TextView
+	replaceFocus      []tview.Primitive // the replace modal's fields in tab order
+	showArchived      bool // archived overrides are listed in the Available panel
+	resolvePreview    bool
+	mergedPreview     bool
+	currentJob        *job // the job the runner panel shows
+	jobs              []*job // jobs started from the TUI, oldest first
+	jobsOpen          bool
+	jobsList          *tview.List
+	jobsTail          *tview.TextView
+	rightFlex         *tview.Flex
+	leftFlex          *tview.Flex
+	mainFlex          *tview.Flex
+	zoom              int // zoomNone, zoomPanel or zoomContent
+	commandLogView    *tview.TextView
+	commandLogShown   bool
+	previewOpen       bool
+	errorLog          []errorEntry
+	errorsOpen        bool
+	readOnlyFlag      bool // --read-only was given, so reloading the config cannot lift it
+	noDirenvFlag      bool // --no-direnv was given: direnv_command is never run
+	direnvProblem     direnvProblem // shown in the status bar until fixed
+	commandOpen       bool
+	runTargetsOpen    bool
+	runTargetsList    *tview.List
+	previewCommand    string // the command shown by the command preview, on one line
+	direnvOpen        bool
+	direnvText        *tview.TextView
+	forceLock         bool   // --force was given: write even when another instance holds the lock
+	lockReadOnly      bool   // another instance held the lock when the project was opened
+	envFlag           string // --env, the environment every project opens in ("" for the env file's)
+	projectsOpen      bool
+	vertical          bool // the lists are above the content rather than beside it
+	lockCommand       string // "TUI" or the subcommand, shown to instances the lock keeps out
+}
+
+// globalFlags are options accepted anywhere on the command line
+type globalFlags struct {
+	debug    bool
+	dryRun   bool
+	readOnly bool
+	noDirenv bool
+	force    bool
+	env      string
+	remote    string
+	container string
+	project   string
+}
+
+// parseGlobalFlags separates global flags from the remaining arguments.
+// Parsing stops at "--" so flags meant for child commands are left alone.
+func parseGlobalFlags(args []string) (globalFlags, []string) {
+	var flags globalFlags
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--env="); ok {
+			flags.env = value
+			continue
+		}
+		if value, ok := strings.CutPrefix(arg, "--remote="); ok {
+			flags.remote = value
+			continue
+		}
+		if value, ok := strings.CutPrefix(arg, "--container="); ok {
+			flags.container = value
+			continue
+		}
+		if value, ok := strings.CutPrefix(arg, "--project="); ok {
+			flags.project = value
+			continue
+		}
+		switch arg {
+		case "--env":
+			if i+1 < len(args) {
+				i++
+				flags.env = args[i]
+			}
+		case "--remote":
+			if i+1 < len(args) {
+				i++
+				flags.remote = args[i]
+			}
+		case "--container":
+			if i+1 < len(args) {
+				i++
+				flags.container = args[i]
+			}
+		case "--project":
+			if i+1 < len(args) {
+				i++
+				flags.project = args[i]
+			}
+		case "--debug":
+			flags.debug = true
+		case "--dry-run":
+			flags.dryRun = true
+		case "--read-only":
+			flags.readOnly = true
+		case "--no-direnv":
+			flags.noDirenv = true
+		case "--force":
+			flags.force = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return flags, rest
+}
+
+// Main runs lazyhydra with the command line arguments: one of the subcommands, or the TUI.
+func Main() {
+	flags, args := parseGlobalFlags(os.Args[1:])
+
+	if flags.debug || debugLogRequested() {
+		closeLog, err := setupDebugLog()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not enable debug logging: %v\n", err)
+		} else {
+			defer closeLog()
+		}
+	}
+
+	// Doctor runs before anything is loaded so it can report on broken setups
+	if len(args) > 0 && args[0] == "doctor" {
+		os.Exit(runDoctor(fsys.OS))
+	}
+
+	// Init runs before anything is loaded, since it creates what loading needs
+	if len(args) > 0 && args[0] == "init" {
+		if err := runInit(args[1:], flags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Hook only prints a shell snippet, so it needs no project or config
+	if len(args) > 0 && args[0] == "hook" {
+		if err := runHook(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := config.MigrateTemplates(fsys.OS); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	var projectRoot string
+	switch {
+	case flags.remote != "" && flags.container != "":
+		fmt.Fprintln(os.Stderr, "Error: --remote and --container cannot be combined")
+		os.Exit(1)
+	case flags.project != "" && (flags.remote != "" || flags.container != ""):
+		fmt.Fprintln(os.Stderr, "Error: --project opens a bookmarked local project and cannot be combined with --remote or --container")
+		os.Exit(1)
+	case flags.project != "":
+		root, err := openBookmark(flags.project)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		projectRoot = root
+	case flags.remote != "" || flags.container != "":
+		open, spec := openRemote, flags.remote
+		if flags.container != "" {
+			open, spec = openContainer, flags.container
+		}
+		root, err := open(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		projectRoot = root
+	default:
+		projectRoot, _ = setProjectRoot()
+	}
+	store := projectStore(projectRoot)
+
+	if flags.env != "" {
+		if err := state.ValidateEnvironmentName(flags.env); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	cfg, err := config.Load(store)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	app := &App{
+		Project: &state.Project{
+			Config:   cfg,
+			FS:       store,
+			Root:     projectRoot,
+			Env:      flags.env,
+			DryRun:   flags.dryRun,
+			ReadOnly: flags.readOnly || cfg.ReadOnly,
+			Applied:  make(map[string]bool),
+		},
+		marked:      make(map[string]bool),
+		collapsed:   make(map[string]bool),
+		collapsedComposites: make(map[string]bool),
+		ui:          loadUIState(),
+		macros:      make(map[rune][]macroKey),
+		readOnlyFlag: flags.readOnly,
+		noDirenvFlag: flags.noDirenv,
+		forceLock:   flags.force,
+		envFlag:     flags.env,
+		lockCommand: "TUI",
+	}
+	if len(args) > 0 {
+		app.lockCommand = strings.TrimLeft(args[0], "-")
+	}
+
+	// Load overrides from disk
+	if err := app.LoadOverrides(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading overrides: %v\n", err)
+		os.Exit(1)
+	}
+
+	// With branch_environments, each branch has its own applied set unless --env is given
+	// A remote project's checkout cannot be watched, so its branch is not followed
+	if remote == nil {
+		app.branch = currentGitBranch(store, projectRoot)
+	}
+	if cfg.BranchEnvironments && app.Env == "" && app.branch != "" {
+		app.Env = branchEnvironment(app.branch)
+	}
+	requestedEnv := app.Env
+
+	// Load persisted state from .envrc
+	if err := app.loadPersistedState(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load persisted state: %v\n", err)
+	}
+
+	// Reconcile symlinks: ensure applied overrides have symlinks, remove stale ones
+	app.ReconcileSymlinks()
+
+	// Check for --help flag
+	if len(args) > 0 && (args[0] == "--help" || args[0] == "-h") {
+		fmt.Println(`LazyHydra - Lazy-style TUI for managing Hydra CLI overrides
+
+Usage:
+  lazyhydra           Launch the TUI
+  lazyhydra -l        List all overrides and their status
+  lazyhydra list --json
+                      List overrides, metadata and applied flags as JSON
+  lazyhydra list --names
+                      List override names, one per line
+  lazyhydra status [--json]
+                      Show the applied overrides and override string
+  lazyhydra status --prompt [--no-color]
+                      Print a one-line summary for prompts and status bars
+                      (e.g. ⚡3 overrides), empty when nothing is applied
+  lazyhydra stats [--json]
+                      Show how often and when each override was applied
+  lazyhydra path [NAME]
+                      Print the folder of override NAME, or the overrides directory
+                      (e.g. cd "$(lazyhydra path foo)")
+  lazyhydra -p        Print the current override string (for use in scripts)
+      --sep=space|newline|null
+                      Join the individual arguments with the given separator
+      --argv          Print shell-quoted arguments (for eval)
+      --group NAME    Only the overrides for export group NAME and shared ones
+  lazyhydra copy      Copy the current override string to the clipboard
+  lazyhydra run -- <command>
+                      Run a command with the overrides injected (see run_inject)
+  lazyhydra snapshot save|restore <name>
+                      Save or restore the applied set and override files
+  lazyhydra snapshot list
+                      List saved snapshots
+  lazyhydra export --wandb|--mlflow [--output FILE]
+                      Write the applied overrides, override string and hash as
+                      YAML for wandb.init(config=...) or as JSON MLflow run tags
+  lazyhydra trash list|restore <name>
+                      List deleted overrides or restore one from the trash
+  lazyhydra trash purge [--older-than AGE]
+                      Delete trashed overrides for good (AGE e.g. 30d or 12h)
+  lazyhydra batch [-c COMMAND]...
+                      Run commands (apply, remove, clear, snapshot, print, status)
+                      from -c flags or stdin and save once
+  lazyhydra serve [--socket PATH]
+                      Serve list/status/print/apply/remove as JSON over a unix
+                      socket (default: .lazyhydra/lazyhydra.sock in the project)
+  lazyhydra ipc       Speak newline-delimited JSON on stdin/stdout for editor plugins
+  lazyhydra diffgen base.yaml modified.yaml --name NAME [--block BLOCK] [--package PACKAGE]
+                      Create a merge override with the keys modified.yaml changes
+  lazyhydra init [DIR] [--example]
+                      Set up a project: overrides directory, .lazyhydra.yaml and
+                      env file (--example also seeds an example override)
+  lazyhydra doctor    Check the environment and override definitions
+  lazyhydra hook bash|zsh|fish
+                      Print shell functions (lzh, lzh-run, lzh-cd) and completion,
+                      e.g. eval "$(lazyhydra hook zsh)" in ~/.zshrc
+  lazyhydra -h        Show this help
+
+Options:
+  --debug             Write debug logs to ~/.local/state/lazyhydra/lazyhydra.log
+  --dry-run           Never write files; show the .envrc diff a save would make
+  --read-only         Disable all actions that change overrides or state
+  --no-direnv         Never run direnv_command (direnv allow) after saving
+  --force             Write even when another lazyhydra instance holds the
+                      project's lock
+  --env NAME          Use the applied set of environment NAME (e.g. dev, prod)
+  --remote HOST:PATH  Open the project at PATH on HOST over ssh, e.g.
+                      --remote user@cluster:/home/user/project
+  --container NAME[:PATH]
+                      Open the project at PATH (default: the working directory)
+                      in running container NAME with docker exec
+  --project NAME      Open the project bookmarked as NAME under projects: in
+                      config.yaml
+
+Environment:
+  PROJECT_ROOT        Directory for .envrc file (default: nearest parent with
+                      .git, .envrc or .lazyhydra.yaml, else current directory)
+  LAZYHYDRA_LOG       Set to "debug" to enable debug logging
+
+Overrides are loaded from: ~/.config/tbp/overrides/
+Each override folder should contain:
+  - override.yaml     The override configuration
+  - apply.md          Metadata (type, block, file) in YAML frontmatter
+
+Keybindings in TUI:`)
+		fmt.Print(usageKeys())
+		return
+	}
+
+	// Check for --list flag to print overrides without TUI
+	if len(args) > 0 && (args[0] == "--list" || args[0] == "-l" || args[0] == "list") {
+		if hasFlag(args[1:], "--names") {
+			app.printNames()
+			return
+		}
+		if err := app.printList(hasFlag(args[1:], "--json")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for stats command to print how often each override was applied
+	if len(args) > 0 && args[0] == "stats" {
+		if err := app.printStats(hasFlag(args[1:], "--json")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for path command to print where an override lives, e.g. for cd
+	if len(args) > 0 && args[0] == "path" {
+		if err := app.printPath(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for status command to print the applied state
+	if len(args) > 0 && args[0] == "status" {
+		if hasFlag(args[1:], "--prompt") {
+			app.printPrompt(!hasFlag(args[1:], "--no-color") && os.Getenv("NO_COLOR") == "")
+			return
+		}
+		if err := app.printStatus(hasFlag(args[1:], "--json")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for --print flag to only print override string
+	if len(args) > 0 && (args[0] == "--print" || args[0] == "-p") {
+		if err := app.printOverrideString(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for run command to execute a command with the overrides injected
+	if len(args) > 0 && args[0] == "run" {
+		os.Exit(app.runWithOverrides(args[1:]))
+	}
+
+	// Check for snapshot command to save or restore the applied state
+	if len(args) > 0 && args[0] == "snapshot" {
+		if err := app.runSnapshotCommand(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for export command to hand the applied state to an experiment tracker
+	if len(args) > 0 && args[0] == "export" {
+		if err := app.runExport(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for trash command to list, restore or purge deleted overrides
+	if len(args) > 0 && args[0] == "trash" {
+		if err := app.runTrashCommand(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for serve command to answer API requests on a unix socket
+	if len(args) > 0 && args[0] == "serve" {
+		if err := app.runServe(args[1:], requestedEnv); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for ipc command to talk to editor plugins over stdin and stdout
+	if len(args) > 0 && args[0] == "ipc" {
+		if err := app.runIPC(requestedEnv); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for batch command to run commands headlessly
+	if len(args) > 0 && args[0] == "batch" {
+		if err := app.runBatch(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for diffgen command to create an override from the diff of two files
+	if len(args) > 0 && args[0] == "diffgen" {
+		if err := app.runDiffgenCommand(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check for copy command to put the override string on the clipboard
+	if len(args) > 0 && args[0] == "copy" {
+		overrideStr := strings.ReplaceAll(app.BuildString(), "\n", " ")
+		if err := copyToClipboard(overrideStr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error copying to clipboard: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The TUI holds the project's lock while it runs, so other instances do not write over it
+	if !app.ReadOnly && !app.DryRun {
+		if !app.lockTUI() {
+			return
+		}
+		defer app.releaseLock()
+	}
+
+	app.setupUI()
+	app.refreshAll()
+	app.reportMissing()
+	app.updateDirenvHealth(false)
+	app.restoreSession()
+
+	// Watch for external changes to overrides and .envrc
+	// Reloading the config replaces the watcher, so close whichever is current
+	if err := app.startWatcher(); err == nil {
+		defer func() {
+			if app.watcher != nil {
+				app.watcher.Close()
+			}
+		}()
+	}
+
+	if err := app.app.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	app.saveSession()
+}
+
+// projectRootMarkers are the files whose presence marks a directory as the project root
+var projectRootMarkers = []string{".git", ".envrc", ".lazyhydra.yaml"}
+
+// detectProjectRoot returns PROJECT_ROOT when it is set. Otherwise it walks up from the
+// current directory to the nearest directory containing one of projectRootMarkers,
+// falling back to the current directory. source says how the root was found: the
+// PROJECT_ROOT variable, the marker's name, or "" for the fallback.
+func detectProjectRoot() (root, source string) {
+	if root := os.Getenv("PROJECT_ROOT"); root != "" {
+		return root, "PROJECT_ROOT"
+	}
+
+	cwd, _ := os.Getwd()
+	for dir := cwd; ; {
+		for _, marker := range projectRootMarkers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, marker
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return cwd, ""
+		}
+		dir = parent
+	}
+}
+
+// setProjectRoot detects the project root and exports it as PROJECT_ROOT, so config
+// paths such as $PROJECT_ROOT/conf and commands run from LazyHydra refer to it.
+func setProjectRoot() (root, source string) {
+	root, source = detectProjectRoot()
+	os.Setenv("PROJECT_ROOT", root)
+	logging.Logger.Debug("project root", "root", root, "source", source)
+	return root, source
+}
+
+func (app *App) loadPersistedState() error {
+	applied, err := app.LoadApplied()
+
+	// Remember what was loaded so external edits can be detected before saving
+	app.envrcHash = app.envrcFingerprint()
+	app.savedApplied = copyApplied(app.Applied)
+
+	logging.Logger.Debug("loaded persisted state", "path", app.EnvFilePath(), "environment", app.Env,
+		"applied", len(applied), "error", err)
+	return err
+}
+
+// envrcFingerprint returns a hash of the env file's current content, or "" if it doesn't exist.
+func (app *App) envrcFingerprint() string {
+	envrcPath := app.EnvFilePath()
+	data, err := app.FS.ReadFile(envrcPath)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func copyApplied(applied map[string]bool) map[string]bool {
+	result := make(map[string]bool, len(applied))
+	for name, ok := range applied {
+		result[name] = ok
+	}
+	return result
+}
+
+// errEnvrcConflict is returned when the env file was modified by another process since it was read
+var errEnvrcConflict = errors.New("env file was modified outside lazyhydra")
+
+// errReadOnly is returned when a save is attempted in read-only mode
+var errReadOnly = errors.New("lazyhydra is in read-only mode")
+
+// errWritePending is returned when a save is waiting on the write preview
+var errWritePending = errors.New("write awaiting confirmation")
+
+// savePersistedState writes the applied state unless the env file changed since it was
+// last read, in which case the TUI asks whether to reload, overwrite or merge.
+// In dry-run mode, or with preview_writes, the diff is shown instead of writing directly.
+func (app *App) savePersistedState() error {
+	if app.ReadOnly {
+		return errReadOnly
+	}
+
+	if app.envrcFingerprint() != app.envrcHash {
+		logging.Logger.Debug("env file changed since it was read", "path", app.Config.ProjectEnvFile)
+		if app.app != nil && app.pages != nil && !app.conflictOpen {
+			app.showEnvrcConflict()
+		}
+		return errEnvrcConflict
+	}
+
+	if app.DryRun || app.Config.PreviewWrites {
+		if app.app != nil && app.pages != nil {
+			if !diffChanged(app.envFileDiff()) {
+				return nil
+			}
+			app.showWritePreview()
+			return errWritePending
+		}
+		if app.DryRun {
+			envrcPath := app.EnvFilePath()
+			fmt.Printf("Dry run: would write %s\n%s", envrcPath, formatDiff(app.envFileDiff()))
+			return nil
+		}
+	}
+
+	return app.writePersistedState()
+}
+
+// envFileDiff returns the diff between the env file on disk and what saving would write
+func (app *App) envFileDiff() []diffLine {
+	envrcPath := app.EnvFilePath()
+	current, _ := app.FS.ReadFile(envrcPath)
+	content, _ := app.BuildEnvFile()
+	return lineDiff(string(current), string(content))
+}
+
+func (app *App) writePersistedState() error {
+	unlock, err := app.lockForSave()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	content, err := app.WriteEnvFile()
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(content)
+	app.envrcHash = hex.EncodeToString(sum[:])
+	previous := app.savedApplied
+	app.savedApplied = copyApplied(app.Applied)
+
+	// Run direnv_command so changes take effect immediately; other env file formats are
+	// loaded by whatever reads them
+	err = app.WriteOverridesFile()
+	if err == nil && !app.noDirenvFlag {
+		err = runDirenvCommand(app.Config, app.Root)
+	}
+
+	app.runHooks(previous)
+	if app.app != nil {
+		app.updateDirenvHealth(true)
+	}
+	// The TUI reports a missing direnv with its indicator instead
+	if errors.Is(err, errDirenvMissing) {
+		if app.app == nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		return nil
+	}
+	return err
+}
+
+// persistState saves the applied state, reporting failures in the status bar.
+// It returns false when the state could not be saved.
+func (app *App) persistState() bool {
+	err := app.savePersistedState()
+	if err == nil {
+		return true
+	}
+	// Conflicts and previews are resolved through their own prompt
+	if !errors.Is(err, errEnvrcConflict) && !errors.Is(err, errWritePending) {
+		app.showError(err)
+	}
+	return false
+}
+
+func (app *App) buildOverrideStringForOne(o *override.Override) string {
+	return strings.Join(o.Args(app.Config), " ")
+}
+
+func copyToClipboard(text string) error {
+	// Over SSH the local clipboard is only reachable through the terminal
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		return copyViaOSC52(text)
+	}
+
+	// Try different clipboard commands in order of preference
+	clipboardCmds := []struct {
+		name string
+		args []string
+	}{
+		{"wl-copy", nil},                         // Wayland
+		{"xclip", []string{"-selection", "clipboard"}}, // X11
+		{"xsel", []string{"--clipboard", "--input"}},   // X11 alternative
+		{"pbcopy", nil},                          // macOS
+		{"clip", nil},                            // Windows
+	}
+
+	for _, clip := range clipboardCmds {
+		path, err := exec.LookPath(clip.name)
+		if err != nil {
+			continue
+		}
+
+		cmd := exec.Command(path, clip.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := runLogged(cmd); err == nil {
+			return nil
+		}
+	}
+
+	// No clipboard command worked; let the terminal handle it
+	return copyViaOSC52(text)
+}
+
+// copyViaOSC52 asks the terminal emulator to set the clipboard using the OSC 52
+// escape sequence, which also works across SSH. Inside tmux the sequence is wrapped
+// in a passthrough so it reaches the outer terminal.
+func copyViaOSC52(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no clipboard command or terminal available: %w", err)
+	}
+	defer tty.Close()
+
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+
+	_, err = tty.WriteString(seq)
+	return err
+}
+
+func (app *App) copySelectedOverrideString() {
+	selected := app.getSelectedOverride()
+	if selected == nil {
+		return
+	}
+
+	overrideStr := app.buildOverrideStringForOne(selected)
+	if err := copyToClipboard(overrideStr); err != nil {
+		app.showError(err)
+		return
+	}
+	app.showMessage("Copied override string for %s", selected.Name)
+}
+
+func (app *App) copyAllOverrideStrings() {
+	overrideStr := strings.ReplaceAll(app.BuildString(), "\n", " ")
+	if overrideStr == "" {
+		return
+	}
+	if err := copyToClipboard(overrideStr); err != nil {
+		app.showError(err)
+		return
+	}
+	app.showMessage("Copied override string for all applied overrides")
+}
+
+// copySelectedContent copies the selected override's override.yaml, or what the content
+// view shows instead of it: the merged config, the resolved interpolations or the
+// members of a composite
+func (app *App) copySelectedContent() {
+	selected := app.getSelectedOverride()
+	if selected == nil {
+		return
+	}
+
+	text, what := selected.Content, "override.yaml"
+	switch {
+	case selected.IsComposite():
+		text, what = stripTags(app.formatComposite(selected)), "members"
+	case app.mergedPreview && selected.Block != "":
+		_, merged, err := app.mergedConfig(selected)
+		if err != nil {
+			app.showError(err)
+			return
+		}
+		text, what = merged, "merged config"
+	case app.resolvePreview && hasInterpolations(selected.RenderedContent()):
+		text, what = stripTags(app.formatResolvedContent(selected)), "resolved override.yaml"
+	}
+	if err := copyToClipboard(text); err != nil {
+		app.showError(err)
+		return
+	}
+	app.showMessage("Copied %s of %s", what, selected.Name)
+}
+
+// copySelectedPath copies the absolute path of the selected override's folder and shows it
+func (app *App) copySelectedPath() {
+	selected := app.getSelectedOverride()
+	if selected == nil {
+		return
+	}
+	if err := copyToClipboard(selected.FolderPath); err != nil {
+		app.showError(err)
+		return
+	}
+	app.showMessage("Copied %s", selected.FolderPath)
+}
+
+// openSelectedFolder reveals the selected override's folder in the file manager. The
+// opener runs in the background, so a slow file manager does not block the TUI.
+func (app *App) openSelectedFolder() {
+	selected := app.getSelectedOverride()
+	if selected == nil {
+		return
+	}
+	if remote != nil {
+		app.showError(fmt.Errorf("%s is on %s; o only opens local folders", selected.FolderPath, remote.Name))
+		return
+	}
+	cmd := openCommand(selected.FolderPath)
+	go func() {
+		if err := runLogged(cmd); err != nil {
+			app.app.QueueUpdateDraw(func() {
+				app.showError(fmt.Errorf("opening %s: %w", selected.FolderPath, err))
+			})
+		}
+	}()
+	app.showMessage("Opening %s", homeRelative(selected.FolderPath))
+}
+
+// stripTags returns text formatted for a TextView without its color tags
+func stripTags(text string) string {
+	return tview.NewTextView().SetDynamicColors(true).SetText(text).GetText(true)
+}
+
+func (app *App) setupUI() {
+	app.app = tview.NewApplication()
+
+	// Lazygit-style blue selection color: #6a9fb5
+	selectionColor := tcell.NewRGBColor(106, 159, 181)
+
+	// Create Available Overrides list
+	app.availableList = tview.NewList().
+		ShowSecondaryText(app.Config.ShowDescriptions).
+		SetSecondaryTextColor(tcell.ColorGray).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(selectionColor).
+		SetSelectedTextColor(tcell.ColorWhite)
+	app.availableList.SetBorder(true).
+		SetTitle(" [1] Available Overrides ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorDefault)
+
+	// Create Applied Overrides list
+	app.appliedList = tview.NewList().
+		ShowSecondaryText(app.Config.ShowDescriptions).
+		SetSecondaryTextColor(tcell.ColorGray).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(selectionColor).
+		SetSelectedTextColor(tcell.ColorWhite)
+	app.appliedList.SetBorder(true).
+		SetTitle(" [2] Applied Overrides ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorDefault)
+
+	// Create Content view
+	app.contentView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(!app.ui.NoWrap).
+		SetWordWrap(true).
+		SetScrollable(true)
+	app.contentView.SetBorder(true).
+		SetTitle(" Override Content ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorDefault)
+
+	// Create Override String view
+	app.overrideStringView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true)
+	app.overrideStringView.SetBorder(true).
+		SetTitle(" Override String ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorDefault)
+
+	// Create Status bar
+	app.statusBar = tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+
+	// Store panels for navigation (only 1 and 2 are navigable)
+	app.panels = []tview.Primitive{app.availableList, app.appliedList}
+
+	// Create Command Log view (toggled with @)
+	app.commandLogView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	app.commandLogView.SetBorder(true).
+		SetTitle(" Command Log ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorDefault)
+	// Commands also run on the event loop (e.g. direnv allow while saving), where waiting
+	// for a queued update would deadlock, so recording one only signals this goroutine,
+	// which refreshes the log once for any number of commands recorded meanwhile
+	go func() {
+		for range commands.changed {
+			app.app.QueueUpdateDraw(app.refreshCommandLog)
+		}
+	}()
+
+	// The lists and the content side, arranged in the main layout as layout in the
+	// config says and resized with < and >
+	app.leftFlex = tview.NewFlex()
+	app.rightFlex = tview.NewFlex().SetDirection(tview.FlexRow)
+	app.mainFlex = tview.NewFlex()
+	app.layoutFromConfig()
+
+	// Root layout with status bar
+	rootFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(app.mainFlex, 0, 1, true).
+		AddItem(app.statusBar, 1, 0, false)
+
+	// Set up keybindings
+	app.setupKeybindings()
+
+	// Set up list selection handlers
+	app.availableList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		app.updateContentAndInfo()
+	})
+
+	app.appliedList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		app.updateContentAndInfo()
+	})
+
+	// Focus handler to update border colors
+	app.app.SetFocus(app.availableList)
+	app.updateBorderColors()
+
+	// Keep the status bar hints in sync with whichever modal or panel is active
+	app.app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		app.updateStatusBar()
+		return false
+	})
+
+	// Create pages for overlay support
+	app.pages = tview.NewPages().
+		AddPage("main", rootFlex, true, true)
+
+	app.app.SetRoot(app.pages, true)
+}
+
+func (app *App) setupKeybindings() {
+	app.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Macros record and replay keys before any binding sees them
+		if event = app.handleMacroKey(event); event == nil {
+			return nil
+		}
+
+		// If help is open, close it on Escape or q
+		if app.helpOpen {
+			if app.helpSearch.HasFocus() {
+				return event
+			}
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+				app.closeHelp()
+				return nil
+			case event.Rune() == '/':
+				app.app.SetFocus(app.helpSearch)
+				return nil
+			}
+			return event
+		}
+
+		// If clear-all confirmation is open, handle it
+		if app.clearOpen {
+			if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+				app.closeClearConfirmation()
+				return nil
+			}
+			if event.Key() == tcell.KeyEnter {
+				app.clearAllOverrides()
+				app.closeClearConfirmation()
+				return nil
+			}
+			return event
+		}
+
+		// If the runner panel is open, scroll it, kill the job or close it
+		if app.runnerOpen {
+			switch {
+			case event.Key() == tcell.KeyCtrlC:
+				app.killJob(app.currentJob)
+				return nil
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 'x':
+				app.closeRunner()
+				return nil
+			case event.Key() == tcell.KeyCtrlT:
+				app.closeRunner()
+				app.showJobs()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the jobs panel is open, stop, rerun or remove the selected job, or close it
+		if app.jobsOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Key() == tcell.KeyCtrlT:
+				app.closeJobs()
+				return nil
+			case event.Rune() == 's' || event.Key() == tcell.KeyCtrlC:
+				app.stopSelectedJob()
+				return nil
+			case event.Rune() == 'r':
+				app.rerunSelectedJob()
+				return nil
+			case event.Rune() == 'D':
+				app.removeSelectedJob()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the run menu is open, run a target in the terminal or close it
+		if app.runTargetsOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 'x':
+				app.closeRunTargets()
+				return nil
+			case event.Rune() == 's':
+				app.runSelectedTarget()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the command preview is open, copy the command or close it
+		if app.commandOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 't':
+				app.closeCommandPreview()
+				return nil
+			case event.Rune() == 'y':
+				app.copyCommand()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the direnv view is open, allow the env file, check again or close it
+		if app.direnvOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyCtrlE || event.Rune() == 'q':
+				app.closeDirenvHealth()
+				return nil
+			case event.Rune() == 'a':
+				app.allowEnvFile()
+				return nil
+			case event.Rune() == 'r':
+				app.updateDirenvHealth(false)
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the env file viewer is open, scroll it, edit the file or close it
+		if app.envViewOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 'v':
+				app.closeEnvFileView()
+				return nil
+			case event.Rune() == 'e':
+				app.editEnvFile()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the write preview is open, confirm or cancel it
+		if app.previewOpen {
+			switch {
+			case event.Key() == tcell.KeyEnter:
+				app.confirmWritePreview()
+				return nil
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+				app.cancelWritePreview()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the env file conflict prompt is open, handle it
+		if app.conflictOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+				app.closeEnvrcConflict()
+				return nil
+			case event.Rune() == 'r':
+				app.closeEnvrcConflict()
+				app.reloadFromDisk()
+				return nil
+			case event.Rune() == 'o':
+				app.closeEnvrcConflict()
+				if err := app.writePersistedState(); err != nil {
+					app.showError(err)
+				}
+				app.refreshAll()
+				return nil
+			case event.Rune() == 'm':
+				app.closeEnvrcConflict()
+				app.mergePersistedState()
+				return nil
+			}
+			return event
+		}
+
+		// If template picker is open, close it on Escape
+		if app.templateOpen {
+			if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+				app.closeTemplatePicker()
+				return nil
+			}
+			return event
+		}
+
+		// If the block conflicts view is open, scroll it or close it
+		if app.blockConflictsOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 'c':
+				app.closeConflicts()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the lint view is open, scroll it or close it
+		if app.lintOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 'L':
+				app.closeLint()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// Quick switcher: its list toggles the override on a number key or Enter
+		if app.recentOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyCtrlO || event.Rune() == 'q' || event.Rune() == '\'':
+				app.closeRecent()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the usage statistics are open, scroll them or close them
+		if app.statsOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 'U':
+				app.closeStats()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If error log is open, scroll it or close it
+		if app.errorsOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == '!':
+				app.closeErrorLog()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If search is open, close it on Escape; the modal handles the rest
+		if app.searchOpen {
+			if event.Key() == tcell.KeyEsc {
+				app.closeSearch()
+				return nil
+			}
+			return event
+		}
+
+		// If input is open, close it on Escape
+		if app.inputOpen {
+			if event.Key() == tcell.KeyEsc {
+				app.closeInput()
+				return nil
+			}
+			return event
+		}
+
+		// If delete confirmation is open, handle it
+		if app.deleteOpen {
+			if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+				app.closeDeleteConfirmation()
+				return nil
+			}
+			if event.Key() == tcell.KeyEnter {
+				app.deleteSelectedOverride()
+				app.closeDeleteConfirmation()
+				return nil
+			}
+			return event
+		}
+
+		// Search and replace: tab between the fields; in the file list include or skip files
+		if app.replaceOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc:
+				app.closeReplace()
+				return nil
+			case event.Key() == tcell.KeyTab:
+				app.cycleReplaceFocus(1)
+				return nil
+			case event.Key() == tcell.KeyBacktab:
+				app.cycleReplaceFocus(-1)
+				return nil
+			case !app.replaceList.HasFocus():
+				if event.Key() == tcell.KeyDown && len(app.replaceResults) > 0 {
+					app.app.SetFocus(app.replaceList)
+					return nil
+				}
+				return event
+			case event.Rune() == ' ':
+				app.toggleReplaceFile()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			case event.Rune() == 'J' || event.Rune() == 'K':
+				row, _ := app.replacePreview.GetScrollOffset()
+				if event.Rune() == 'J' {
+					row++
+				} else if row > 0 {
+					row--
+				}
+				app.replacePreview.ScrollTo(row, 0)
+				return nil
+			}
+			return event
+		}
+
+		// Diff of an edit made in $EDITOR: close it or undo the edit
+		if app.editDiffOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyEnter || event.Rune() == 'q':
+				app.closeEditDiff()
+				return nil
+			case event.Rune() == 'u':
+				app.undoEdit()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// Version history: move with j/k, scroll the diff with J/K
+		if app.versionsOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+				app.closeVersions()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			case event.Rune() == 'J' || event.Rune() == 'K':
+				row, _ := app.versionsDiff.GetScrollOffset()
+				if event.Rune() == 'J' {
+					row++
+				} else if row > 0 {
+					row--
+				}
+				app.versionsDiff.ScrollTo(row, 0)
+				return nil
+			}
+			return event
+		}
+
+		// Trash browser: restore or purge the override under the cursor
+		if app.trashOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+				app.closeTrash()
+				return nil
+			case event.Rune() == 'D':
+				app.purgeSelectedTrash()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// Plugin menu: run the plugin under the cursor
+		if app.pluginsOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+				app.closePlugins()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// Import wizard: select config group options and import them
+		if app.importOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+				app.closeImportWizard()
+				return nil
+			case event.Key() == tcell.KeyEnter:
+				app.confirmImport()
+				return nil
+			case event.Rune() == ' ':
+				app.toggleImportSelection(false)
+				return nil
+			case event.Rune() == 'a':
+				app.toggleImportSelection(true)
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// Snapshot list, name input and restore confirmation
+		if app.snapshotsOpen {
+			front, _ := app.pages.GetFrontPage()
+			switch {
+			case event.Key() == tcell.KeyEsc:
+				app.closeSnapshots()
+				return nil
+			case front == "snapshot-save":
+				return event
+			case event.Rune() == 'q':
+				app.closeSnapshots()
+				return nil
+			case front == "snapshot-restore" && event.Key() == tcell.KeyEnter:
+				app.confirmRestoreSnapshot()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the project switcher is open, move with j/k and close it
+		if app.projectsOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 'W':
+				app.closeProjectSwitcher()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// The environment picker and its name input close on Escape
+		if app.environmentOpen {
+			if event.Key() == tcell.KeyEsc {
+				app.closeEnvironmentPicker()
+				return nil
+			}
+			// j/k/q only act in the picker; the name input needs them as text
+			if front, _ := app.pages.GetFrontPage(); front == "environments" {
+				switch event.Rune() {
+				case 'q':
+					app.closeEnvironmentPicker()
+					return nil
+				case 'j':
+					return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+				case 'k':
+					return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+				}
+			}
+			return event
+		}
+
+		// The apply tag picker moves with j/k and closes on Escape or q
+		if app.applyTagOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+				app.closeApplyTagPicker()
+				return nil
+			case event.Rune() == 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case event.Rune() == 'k':
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+			return event
+		}
+
+		// If the diffgen name input is open, close it on Escape
+		if app.diffgenOpen {
+			if event.Key() == tcell.KeyEsc {
+				app.closeDiffgenInput()
+				return nil
+			}
+			return event
+		}
+
+		// If rename input is open, close it on Escape; when it asks about references,
+		// y and n choose whether to update them
+		if app.renameOpen {
+			if event.Key() == tcell.KeyEsc {
+				app.closeRenameInput()
+				return nil
+			}
+			if app.renameNewName != "" {
+				switch event.Rune() {
+				case 'y', 'n':
+					app.renameSelectedOverride(app.renameNewName, event.Rune() == 'y')
+					app.closeRenameInput()
+				case 'q':
+					app.closeRenameInput()
+				}
+				return nil
+			}
+			return event
+		}
+
+		// If duplicate input is open, close it on Escape
+		if app.duplicateOpen {
+			if event.Key() == tcell.KeyEsc {
+				app.closeDuplicateInput()
+				return nil
+			}
+			return event
+		}
+
+		// If value editor is open, close it on Escape
+		if app.valuesOpen {
+			if event.Key() == tcell.KeyEsc {
+				app.closeValueEditor()
+				return nil
+			}
+			return event
+		}
+
+		// If the bulk frontmatter form is open, close it on Escape
+		if app.bulkEditOpen {
+			if event.Key() == tcell.KeyEsc {
+				app.closeBulkEdit()
+				return nil
+			}
+			return event
+		}
+
+		// If the parameters form is open, close it on Escape
+		if app.paramsOpen {
+			if event.Key() == tcell.KeyEsc {
+				app.closeParamsForm()
+				return nil
+			}
+			return event
+		}
+
+		if app.handleMotionKey(event) {
+			return nil
+		}
+
+		switch event.Key() {
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q':
+				app.app.Stop()
+				return nil
+			case '1':
+				app.focusPanel(0)
+				return nil
+			case '2':
+				app.focusPanel(1)
+				return nil
+			case 'h':
+				app.prevPanel()
+				return nil
+			case 'l':
+				app.nextPanel()
+				return nil
+			case 'j':
+				app.cursorTo(app.currentList().GetCurrentItem() + app.takeCount())
+				return nil
+			case 'k':
+				app.cursorTo(app.currentList().GetCurrentItem() - app.takeCount())
+				return nil
+			case 'J':
+				for n := app.takeCount(); n > 0; n-- {
+					app.scrollContentDown()
+				}
+				return nil
+			case '+':
+				app.cycleZoom(1)
+				return nil
+			case '_':
+				app.cycleZoom(-1)
+				return nil
+			case '|':
+				app.toggleDirection()
+				return nil
+			case '<':
+				app.resizeSplit(-1)
+				return nil
+			case '>':
+				app.resizeSplit(1)
+				return nil
+			case 'K':
+				for n := app.takeCount(); n > 0; n-- {
+					app.scrollContentUp()
+				}
+				return nil
+			case ' ':
+				app.toggleOverride()
+				return nil
+			case '?':
+				app.showHelp()
+				return nil
+			case 'e':
+				app.openInEditor("apply.md")
+				return nil
+			case 'E':
+				app.openInEditor("override.yaml")
+				return nil
+			case 'i':
+				app.showValueEditor()
+				return nil
+			case 'm':
+				app.toggleMark()
+				return nil
+			case 'A':
+				app.showApplyTagPicker()
+				return nil
+			case 'C':
+				app.showClearConfirmation()
+				return nil
+			case '!':
+				app.showErrorLog()
+				return nil
+			case 'x':
+				app.runProjectCommand()
+				return nil
+			case '@':
+				app.toggleCommandLog()
+				return nil
+			case 'v':
+				app.showEnvFileView()
+				return nil
+			case 'W':
+				app.showProjectSwitcher()
+				return nil
+			case 't':
+				app.showCommandPreview()
+				return nil
+			case 'M':
+				app.toggleRawMarkdown()
+				return nil
+			case 's':
+				app.cycleSortMode()
+				return nil
+			case 'p':
+				app.togglePin()
+				return nil
+			case 'z':
+				app.toggleFold()
+				return nil
+			case 'S':
+				app.showEnvironmentPicker()
+				return nil
+			case 'O':
+				app.showSnapshots()
+				return nil
+			case 'R':
+				app.reloadConfig()
+				return nil
+			case 'b':
+				app.showImportWizard()
+				return nil
+			case 'f':
+				app.showDiffgen()
+				return nil
+			case 'X':
+				app.showPlugins()
+				return nil
+			case '/':
+				app.showSearch()
+				return nil
+			case 'P':
+				app.toggleMergedPreview()
+				return nil
+			case 'I':
+				app.toggleResolvePreview()
+				return nil
+			case 'c':
+				app.showConflicts()
+				return nil
+			case 'L':
+				app.showLint()
+				return nil
+			case 'U':
+				app.showStats()
+				return nil
+			case 'w':
+				app.toggleWrap()
+				return nil
+			case 'o':
+				app.openSelectedFolder()
+				return nil
+			case '#':
+				app.toggleLineNumbers()
+				return nil
+			case '=':
+				app.toggleColumns()
+				return nil
+			case '(':
+				app.scrollContentSideways(-8)
+				return nil
+			case ')':
+				app.scrollContentSideways(8)
+				return nil
+			case '\'':
+				app.showRecent()
+				return nil
+			case 'n':
+				if name, ok := app.selectedMissing(); ok {
+					app.recreateMissing(name)
+					return nil
+				}
+				app.showTemplatePicker()
+				return nil
+			case 'D':
+				app.showDeleteConfirmation()
+				return nil
+			case 'T':
+				app.showTrash()
+				return nil
+			case 'a':
+				app.toggleArchived()
+				return nil
+			case 'H':
+				app.toggleShowArchived()
+				return nil
+			case 'V':
+				app.showVersions()
+				return nil
+			case 'F':
+				app.showReplace()
+				return nil
+			case 'B':
+				app.showBulkEdit()
+				return nil
+			case 'r':
+				app.showRenameInput()
+				return nil
+			case 'd':
+				app.showDuplicateInput()
+				return nil
+			case 'y':
+				app.copySelectedOverrideString()
+				return nil
+			case 'Y':
+				app.copyAllOverrideStrings()
+				return nil
+			}
+		case tcell.KeyCtrlO:
+			app.showRecent()
+			return nil
+		case tcell.KeyCtrlG:
+			app.toggleGroupApplied()
+			return nil
+		case tcell.KeyCtrlT:
+			app.showJobs()
+			return nil
+		case tcell.KeyCtrlE:
+			app.showDirenvHealth()
+			return nil
+		case tcell.KeyCtrlY:
+			app.copySelectedContent()
+			return nil
+		case tcell.KeyCtrlP:
+			app.copySelectedPath()
+			return nil
+		case tcell.KeyPgDn, tcell.KeyCtrlF:
+			app.pageList(1)
+			return nil
+		case tcell.KeyPgUp, tcell.KeyCtrlB:
+			app.pageList(-1)
+			return nil
+		case tcell.KeyCtrlD:
+			app.scrollContentHalfPage(1)
+			return nil
+		case tcell.KeyCtrlU:
+			app.scrollContentHalfPage(-1)
+			return nil
+		case tcell.KeyTab:
+			app.nextPanel()
+			return nil
+		case tcell.KeyBacktab:
+			app.prevPanel()
+			return nil
+		case tcell.KeyEnter:
+			app.toggleOverride()
+			return nil
+		case tcell.KeyLeft:
+			app.prevPanel()
+			return nil
+		case tcell.KeyRight:
+			app.nextPanel()
+			return nil
+		case tcell.KeyEsc:
+			// Escape clears marks before it quits
+			if len(app.marked) > 0 {
+				app.clearMarks()
+				return nil
+			}
+			app.app.Stop()
+			return nil
+		}
+		return event
+	})
+}
+
+func (app *App) cursorDown() {
+	switch app.currentPanelIdx {
+	case 0:
+		count := app.availableList.GetItemCount()
+		current := app.availableList.GetCurrentItem()
+		if current < count-1 {
+			app.availableList.SetCurrentItem(current + 1)
+		}
+	case 1:
+		count := app.appliedList.GetItemCount()
+		current := app.appliedList.GetCurrentItem()
+		if current < count-1 {
+			app.appliedList.SetCurrentItem(current + 1)
+		}
+	}
+	app.updateContentAndInfo()
+}
+
+func (app *App) scrollContentDown() {
+	row, col := app.contentView.GetScrollOffset()
+	app.contentView.ScrollTo(row+1, col)
+}
+
+func (app *App) scrollContentUp() {
+	row, col := app.contentView.GetScrollOffset()
+	if row > 0 {
+		app.contentView.ScrollTo(row-1, col)
+	}
+}
+
+func (app *App) focusPanel(idx int) {
+	if idx >= 0 && idx < len(app.panels) {
+		app.currentPanelIdx = idx
+		app.applyLayout()
+		app.app.SetFocus(app.panels[idx])
+		app.updateBorderColors()
+		app.updateContentAndInfo()
+	}
+}
+
+func (app *App) nextPanel() {
+	app.currentPanelIdx = (app.currentPanelIdx + 1) % len(app.panels)
+	app.applyLayout()
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+	app.updateContentAndInfo()
+}
+
+func (app *App) prevPanel() {
+	app.currentPanelIdx = (app.currentPanelIdx - 1 + len(app.panels)) % len(app.panels)
+	app.applyLayout()
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+	app.updateContentAndInfo()
+}
+
+func (app *App) updateBorderColors() {
+	// Lazygit-style blue selection color
+	selectionColor := tcell.NewRGBColor(106, 159, 181)
+
+	// Reset all borders to default
+	app.availableList.SetBorderColor(tcell.ColorDefault)
+	app.appliedList.SetBorderColor(tcell.ColorDefault)
+	app.contentView.SetBorderColor(tcell.ColorDefault)
+	app.overrideStringView.SetBorderColor(tcell.ColorDefault)
+
+	// Reset selection colors - unfocused lists don't show selection highlight
+	app.availableList.SetSelectedBackgroundColor(tcell.ColorDefault)
+	app.appliedList.SetSelectedBackgroundColor(tcell.ColorDefault)
+
+	// Highlight focused panel with green border and blue selection (lazygit style)
+	switch app.currentPanelIdx {
+	case 0:
+		app.availableList.SetBorderColor(tcell.ColorGreen)
+		app.availableList.SetSelectedBackgroundColor(selectionColor)
+	case 1:
+		app.appliedList.SetBorderColor(tcell.ColorGreen)
+		app.appliedList.SetSelectedBackgroundColor(selectionColor)
+	}
+}
+
+func (app *App) toggleOverride() {
+	// On a folder row with nothing marked, space and Enter collapse or expand it
+	if dir, ok := app.selectedFolder(); ok && len(app.markedInPanel()) == 0 {
+		app.toggleFolder(dir)
+		return
+	}
+	// On a missing override with nothing marked, they prune it from the applied state
+	if name, ok := app.selectedMissing(); ok && len(app.markedInPanel()) == 0 {
+		app.pruneMissing(name)
+		return
+	}
+
+	targets := app.actionTargets()
+	if len(targets) == 0 || !app.stateChangesAllowed("Applying and removing") {
+		return
+	}
+
+	// Incomplete overrides would produce a broken override string
+	if app.currentPanelIdx == 0 {
+		var complete []*override.Override
+		for _, o := range targets {
+			if !app.rejectIncomplete(o) {
+				complete = append(complete, o)
+			}
+		}
+		if len(complete) == 0 {
+			return
+		}
+		targets = complete
+	}
+
+	// A single parameterized override asks for its values before it is applied;
+	// bulk applies use the values chosen last time or the defaults
+	if app.currentPanelIdx == 0 && len(targets) == 1 && targets[0].HasParams() {
+		app.showParamsForm(targets[0])
+		return
+	}
+
+	var linkErr error
+	for _, override := range targets {
+		switch app.currentPanelIdx {
+		case 0: // Available list - apply override
+			if err := app.applyOverride(override); err != nil {
+				linkErr = err
+			}
+		case 1: // Applied list - remove override
+			app.removeOverride(override)
+		}
+		delete(app.marked, override.Name)
+	}
+	app.saveUIState()
+
+	saved := app.persistState()
+	app.refreshAll()
+	if linkErr != nil {
+		app.showError(linkErr)
+		return
+	}
+	if !saved {
+		return
+	}
+
+	verb := "Applied"
+	if app.currentPanelIdx == 1 {
+		verb = "Removed"
+	}
+	if len(targets) == 1 {
+		app.showMessage("%s %s", verb, targets[0].Name)
+	} else {
+		app.showMessage("%s %d overrides", verb, len(targets))
+	}
+}
+
+// applyOverride links an override and marks it applied. A composite is applied together
+// with the overrides it includes, or not at all.
+func (app *App) applyOverride(o *override.Override) error {
+	applied, err := app.Apply(o)
+	for _, a := range applied {
+		app.recordApplied(a)
+	}
+	if len(applied) > 0 {
+		app.recordRecent(o, true)
+	}
+	return err
+}
+
+func (app *App) showClearConfirmation() {
+	applied := app.getAppliedOverrides()
+	if len(applied) == 0 || !app.stateChangesAllowed("Clearing") {
+		return
+	}
+
+	app.clearOpen = true
+
+	confirmText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText(fmt.Sprintf(`[yellow::b]Clear Applied Overrides[-:-:-]
+
+Remove all [red]%d[-] applied overrides?
+
+The override folders are kept.
+
+[green]Enter[-] to confirm    [yellow]Esc/q[-] to cancel`, len(applied)))
+
+	confirmText.SetBorder(true).
+		SetTitle(" Confirm Clear ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorRed)
+
+	app.pages.AddPage("clear", modal(confirmText, 55, 11), true, true)
+	app.app.SetFocus(confirmText)
+}
+
+func (app *App) closeClearConfirmation() {
+	app.clearOpen = false
+	app.pages.RemovePage("clear")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// clearAllOverrides removes every applied override, saving the state once.
+func (app *App) clearAllOverrides() {
+	for _, o := range app.getAppliedOverrides() {
+		app.Unlink(o)
+		delete(app.Applied, o.Name)
+		delete(app.marked, o.Name)
+	}
+	for _, name := range app.MissingApplied() {
+		delete(app.Applied, name)
+	}
+
+	saved := app.persistState()
+	app.refreshAll()
+	if saved {
+		app.showMessage("Cleared all applied overrides")
+	}
+}
+
+// panelOverrides returns the overrides shown in the focused panel and the cursor index.
+// Folder rows of the Available panel are nil.
+func (app *App) panelOverrides() ([]*override.Override, int) {
+	switch app.currentPanelIdx {
+	case 0:
+		list := make([]*override.Override, len(app.availableRows))
+		for i, row := range app.availableRows {
+			list[i] = row.override
+		}
+		return list, app.availableList.GetCurrentItem()
+	case 1:
+		list := make([]*override.Override, len(app.appliedRows))
+		for i, row := range app.appliedRows {
+			list[i] = row.override
+		}
+		return list, app.appliedList.GetCurrentItem()
+	}
+	return nil, -1
+}
+
+// markedInPanel returns the marked overrides shown in the focused panel.
+func (app *App) markedInPanel() []*override.Override {
+	list, _ := app.panelOverrides()
+	var marked []*override.Override
+	for _, o := range list {
+		if o != nil && app.marked[o.Name] {
+			marked = append(marked, o)
+		}
+	}
+	return marked
+}
+
+// actionTargets returns the marked overrides in the focused panel, or the override
+// under the cursor when nothing is marked.
+func (app *App) actionTargets() []*override.Override {
+	if marked := app.markedInPanel(); len(marked) > 0 {
+		return marked
+	}
+	list, idx := app.panelOverrides()
+	if idx >= 0 && idx < len(list) && list[idx] != nil {
+		return []*override.Override{list[idx]}
+	}
+	return nil
+}
+
+// toggleMark marks or unmarks the override under the cursor and moves to the next one.
+func (app *App) toggleMark() {
+	list, idx := app.panelOverrides()
+	if idx < 0 || idx >= len(list) || list[idx] == nil {
+		return
+	}
+
+	name := list[idx].Name
+	if app.marked[name] {
+		delete(app.marked, name)
+	} else {
+		app.marked[name] = true
+	}
+
+	app.refreshAll()
+	app.cursorDown()
+}
+
+func (app *App) clearMarks() {
+	app.marked = make(map[string]bool)
+	app.refreshAll()
+}
+
+func (app *App) openInEditor(filename string) {
+	selected := app.getSelectedOverride()
+	if selected == nil || !app.writesAllowed("Editing") {
+		return
+	}
+
+	filePath := filepath.Join(selected.FolderPath, filename)
+
+	// Check if file exists
+	if _, err := app.FS.Stat(filePath); os.IsNotExist(err) {
+		return
+	}
+
+	// Keep the content from before the edit in the override's history
+	before, readErr := app.FS.ReadFile(filePath)
+	if err := app.editProjectFile(filePath); err != nil {
+		app.showError(err)
+	}
+	after, err := app.FS.ReadFile(filePath)
+	changed := readErr == nil && err == nil && !bytes.Equal(before, after)
+	if changed {
+		app.recordVersion(selected, filename, before)
+	}
+
+	// Reload the override content after editing
+	app.reloadOverride(selected.Name)
+	app.updateContentAndInfo()
+	if changed {
+		app.showEditDiff(selected, filename, before, after)
+	}
+}
+
+// findEditor returns the user's editor, falling back to sensible defaults. $EDITOR may
+// include arguments, e.g. "code --wait".
+func findEditor() string {
+	// Get editor from environment
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		// Try common editors
+		for _, e := range fallbackEditors {
+			if _, err := exec.LookPath(strings.Fields(e)[0]); err == nil {
+				editor = e
+				break
+			}
+		}
+	}
+	return editor
+}
+
+// runEditor suspends the TUI and opens filePath in the user's editor
+func (app *App) runEditor(filePath string) error {
+	editor := findEditor()
+	if editor == "" {
+		return fmt.Errorf("no editor found; set $EDITOR")
+	}
+
+	// Suspend tview and run editor
+	var editorErr error
+	app.app.Suspend(func() {
+		args := strings.Fields(editor)
+		cmd := exec.Command(args[0], append(args[1:], filePath)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		editorErr = runLogged(cmd)
+	})
+	if editorErr != nil {
+		return fmt.Errorf("running %s: %w", editor, editorErr)
+	}
+	return nil
+}
+
+func (app *App) reloadOverride(name string) {
+	for _, o := range app.Overrides {
+		if o.Name != name {
+			continue
+		}
+
+		// Reload apply.md
+		applyPath := filepath.Join(o.FolderPath, "apply.md")
+		if content, err := app.FS.ReadFile(applyPath); err == nil {
+			o.ApplyInfo = string(content)
+			o.ParseApplyInfo()
+			override.ResolveIncludes(app.Overrides)
+		}
+
+		// Reload override.yaml
+		overridePath := filepath.Join(o.FolderPath, "override.yaml")
+		if content, err := app.FS.ReadFile(overridePath); err == nil {
+			o.Content = string(content)
+		}
+		o.Modified = override.ModTime(app.FS, o.FolderPath)
+
+		// Re-reconcile symlink if override is applied (block may have changed)
+		if app.Applied[o.Name] {
+			app.Unlink(o)
+			app.Link(o)
+		}
+
+		break
+	}
+}
+
+// getAvailableOverrides returns the overrides that are not applied, leaving out archived
+// ones unless they are shown
+func (app *App) getAvailableOverrides() []*override.Override {
+	var list []*override.Override
+	for _, o := range app.Overrides {
+		if !app.Applied[o.Name] && (!o.Archived || app.showArchived) {
+			list = append(list, o)
+		}
+	}
+	app.sortOverrides(list)
+	return list
+}
+
+func (app *App) getAppliedOverrides() []*override.Override {
+	var list []*override.Override
+	for _, o := range app.Overrides {
+		if app.Applied[o.Name] {
+			list = append(list, o)
+		}
+	}
+	app.sortOverrides(list)
+	return list
+}
+
+func (app *App) getSelectedOverride() *override.Override {
+	switch app.currentPanelIdx {
+	case 0:
+		idx := app.availableList.GetCurrentItem()
+		if idx >= 0 && idx < len(app.availableRows) {
+			// nil when the cursor is on a folder
+			return app.availableRows[idx].override
+		}
+	case 1:
+		idx := app.appliedList.GetCurrentItem()
+		if idx >= 0 && idx < len(app.appliedRows) {
+			return app.appliedRows[idx].override
+		}
+	}
+	// Default: return first available or applied
+	if len(app.Overrides) > 0 {
+		return app.Overrides[0]
+	}
+	return nil
+}
+
+func (app *App) refreshAll() {
+	// Refresh available list
+	currentAvailableIdx := app.availableList.GetCurrentItem()
+	app.availableList.Clear()
+	available := app.buildAvailableRows()
+	app.availableRows = available
+	texts := make([]string, len(available))
+	overrides := make([]*override.Override, len(available))
+	for i, row := range available {
+		texts[i], overrides[i] = app.formatAvailableRow(row), row.override
+	}
+	for i, text := range app.withColumns(texts, overrides) {
+		app.availableList.AddItem(text, app.formatDescription(available[i].override, available[i].depth), 0, nil)
+	}
+	if currentAvailableIdx >= len(available) {
+		currentAvailableIdx = len(available) - 1
+	}
+	if currentAvailableIdx >= 0 {
+		app.availableList.SetCurrentItem(currentAvailableIdx)
+	}
+
+	// Refresh applied list
+	currentAppliedIdx := app.appliedList.GetCurrentItem()
+	app.appliedList.Clear()
+	applied := app.buildAppliedRows()
+	app.appliedRows = applied
+	texts = make([]string, len(applied))
+	overrides = make([]*override.Override, len(applied))
+	for i, row := range applied {
+		texts[i], overrides[i] = app.formatAppliedRow(row), row.override
+	}
+	for i, text := range app.withColumns(texts, overrides) {
+		app.appliedList.AddItem(text, app.formatDescription(applied[i].override, applied[i].depth), 0, nil)
+	}
+	if currentAppliedIdx >= len(applied) {
+		currentAppliedIdx = len(applied) - 1
+	}
+	if currentAppliedIdx >= 0 {
+		app.appliedList.SetCurrentItem(currentAppliedIdx)
+	}
+
+	app.updatePanelTitles()
+	app.updateContentAndInfo()
+	app.updateStatusBar()
+	app.updateBorderColors()
+}
+
+// formatDescription returns the secondary list text for an override: its description,
+// indented to line up under the name. Folder rows (nil) have none.
+func (app *App) formatDescription(o *override.Override, depth int) string {
+	if o == nil || o.Description == "" {
+		return ""
+	}
+	return strings.Repeat("  ", depth+1) + tview.Escape(o.Description)
+}
+
+// markPrefix returns the list prefix shown before marked overrides
+func (app *App) markPrefix(o *override.Override) string {
+	if app.marked[o.Name] {
+		return "[magenta]*[-] "
+	}
+	return ""
+}
+
+// updatePanelTitles shows the number of marked overrides in each list's title
+func (app *App) updatePanelTitles() {
+	countMarked := func(list []*override.Override) int {
+		n := 0
+		for _, o := range list {
+			if app.marked[o.Name] {
+				n++
+			}
+		}
+		return n
+	}
+
+	sortLabel := fmt.Sprintf("[darkgray]↓%s[-] ", app.sortMode())
+
+	availableTitle := " [1] Available Overrides "
+	if n := countMarked(app.getAvailableOverrides()); n > 0 {
+		availableTitle = fmt.Sprintf(" [1] Available Overrides (%d marked) ", n)
+	}
+	if n := app.hiddenArchived(); n > 0 {
+		availableTitle += fmt.Sprintf("[darkgray]%d archived[-] ", n)
+	}
+	app.availableList.SetTitle(availableTitle + sortLabel)
+
+	appliedTitle := " [2] Applied Overrides "
+	if n := countMarked(app.getAppliedOverrides()); n > 0 {
+		appliedTitle = fmt.Sprintf(" [2] Applied Overrides (%d marked) ", n)
+	}
+	if n := len(app.blockConflicts()); n > 0 {
+		appliedTitle += fmt.Sprintf("[red]%d conflict(s), c for details[-] ", n)
+	}
+	if n := len(app.MissingApplied()); n > 0 {
+		appliedTitle += fmt.Sprintf("[red]%d missing[-] ", n)
+	}
+	app.appliedList.SetTitle(appliedTitle + sortLabel)
+}
+
+func (app *App) updateContentAndInfo() {
+	selected := app.getSelectedOverride()
+
+	// Update override string view
+	overrideStr := app.BuildString()
+	app.overrideStringView.Clear()
+	if overrideStr != "" {
+		app.overrideStringView.SetText(overrideStr)
+	} else {
+		app.overrideStringView.SetText("(no overrides applied)")
+	}
+
+	// Update content view
+	app.contentView.Clear()
+	if name, ok := app.selectedMissing(); ok {
+		app.contentView.SetText(app.formatMissing(name))
+	} else if group, ok := app.selectedGroup(); ok {
+		app.contentView.SetText(app.formatGroup(group))
+	} else if selected == nil {
+		app.contentView.SetText("Select an override to view its content")
+	} else {
+		content := fmt.Sprintf("[cyan::b]# %s/override.yaml[-:-:-]\n\n%s", selected.Name, app.numberLines(highlightCode(selected.Content, "yaml")))
+		if selected.IsComposite() {
+			content = app.formatComposite(selected)
+		} else if app.mergedPreview && selected.Block != "" {
+			content = app.formatMergedContent(selected)
+		} else if app.resolvePreview && hasInterpolations(selected.RenderedContent()) {
+			content = fmt.Sprintf("[cyan::b]# %s/override.yaml (resolved)[-:-:-]\n\n%s", selected.Name, app.numberLines(app.formatResolvedContent(selected)))
+		}
+		if selected.HasParams() {
+			content += "\n\n" + formatParams(selected)
+		}
+		if selected.ApplyInfo != "" {
+			content += fmt.Sprintf("\n\n[yellow::b]# Apply Configuration[-:-:-]\n%s", app.formatApplyInfo(selected.ApplyInfo))
+		}
+		app.contentView.SetText(app.formatDetails(selected) + "\n\n" + content)
+	}
+}
+
+// statusMessageDuration is how long transient status bar messages stay visible
+const statusMessageDuration = 4 * time.Second
+
+// formatApplyInfo renders apply.md for the content view: the frontmatter as YAML and
+// the body as formatted markdown, or the whole file highlighted in raw mode.
+func (app *App) formatApplyInfo(applyInfo string) string {
+	if app.rawMarkdown {
+		return highlightCode(applyInfo, "markdown")
+	}
+
+	frontmatter, body, ok := override.SplitFrontmatter(applyInfo)
+	if !ok {
+		return renderMarkdown(applyInfo)
+	}
+
+	content := "[darkgray]---[-]\n" + highlightCode(strings.Trim(frontmatter, "\n"), "yaml") + "\n[darkgray]---[-]"
+	if body = strings.TrimSpace(body); body != "" {
+		content += "\n\n" + renderMarkdown(body)
+	}
+	return content
+}
+
+// toggleRawMarkdown switches the content view between rendered and raw apply.md
+func (app *App) toggleRawMarkdown() {
+	app.rawMarkdown = !app.rawMarkdown
+	app.updateContentAndInfo()
+	if app.rawMarkdown {
+		app.showMessage("Showing raw apply.md")
+	} else {
+		app.showMessage("Showing rendered apply.md")
+	}
+}
+
+// updateStatusBar shows the latest transient message, or key hints for the
+// open modal or focused panel.
+func (app *App) updateStatusBar() {
+	if app.statusMessage != "" {
+		color := "green"
+		if app.statusIsError {
+			color = "red"
+		}
+		app.statusBar.SetText(fmt.Sprintf(" [%s]%s[-]", color, tview.Escape(app.statusMessage)))
+		return
+	}
+
+	mode := ""
+	if app.ReadOnly {
+		mode = "[black:red] READ-ONLY [-:-] "
+	} else if app.DryRun {
+		mode = "[black:yellow] DRY RUN [-:-] "
+	}
+	if app.macroRecording != 0 {
+		mode += fmt.Sprintf("[black:red] REC %c [-:-] ", app.macroRecording)
+	}
+	mode += app.direnvBadge()
+	if n := app.runningJobs(); n > 0 {
+		mode += fmt.Sprintf("[black:green] %d RUNNING [-:-] ", n)
+	}
+	if b, ok := app.currentBookmark(); ok {
+		mode += fmt.Sprintf("[darkgray]▣ %s[-] ", tview.Escape(b.Name))
+	}
+	if app.branch != "" {
+		mode += fmt.Sprintf("[darkgray]⎇ %s[-] ", tview.Escape(app.branch))
+	}
+	// The environment of the current branch needs no separate badge
+	if app.Env != state.DefaultEnvironment && !(app.Config.BranchEnvironments && app.Env == branchEnvironment(app.branch)) {
+		mode += fmt.Sprintf("[black:blue] %s [-:-] ", strings.ToUpper(app.Env))
+	}
+	app.statusBar.SetText(" " + mode + app.statusHints())
+}
+
+// statusHints returns the key hints relevant to the current mode
+func (app *App) statusHints() string {
+	switch {
+	case app.helpOpen && app.helpSearch.HasFocus():
+		return "[ enter ] keep filter  [ esc ] clear filter"
+	case app.helpOpen:
+		return "[ j/k ] scroll  [ / ] filter keys  [ esc/q ] close help"
+	case app.runTargetsOpen:
+		return "[ enter/1-9 ] run  [ s ] run in the terminal  [ j/k ] move  [ esc/q/x ] close"
+	case app.commandOpen:
+		return "[ y ] copy command  [ j/k ] scroll  [ esc/q/t ] close"
+	case app.direnvOpen && app.direnvProblem == direnvBlocked:
+		return "[ a ] run direnv allow  [ r ] check again  [ esc/q/ctrl+e ] close"
+	case app.direnvOpen:
+		return "[ r ] check again  [ esc/q/ctrl+e ] close"
+	case app.envViewOpen:
+		return "[ j/k ] scroll  [ e ] edit in $EDITOR  [ esc/q/v ] close"
+	case app.runnerOpen && app.currentJob != nil && !app.currentJob.done:
+		return "[ j/k ] scroll  [ ctrl+c ] stop job  [ ctrl+t ] all jobs  [ esc/q ] hide (job keeps running)"
+	case app.runnerOpen:
+		return "[ j/k ] scroll  [ ctrl+t ] all jobs  [ esc/q/x ] close"
+	case app.jobsOpen:
+		return "[ enter ] output  [ s ] stop  [ r ] rerun  [ D ] remove  [ j/k ] move  [ esc/q ] close"
+	case app.errorsOpen:
+		return "[ j/k ] scroll  [ esc/q ] close error log"
+	case app.blockConflictsOpen:
+		return "[ j/k ] scroll  [ esc/q/c ] close"
+	case app.recentOpen:
+		return "[ 1-9 ] toggle  [ j/k ] move  [ enter ] toggle  [ esc/q ] close"
+	case app.statsOpen:
+		return "[ j/k ] scroll  [ esc/q/U ] close"
+	case app.lintOpen:
+		return "[ j/k ] scroll  [ esc/q/L ] close"
+	case app.previewOpen && app.DryRun:
+		return "[ j/k ] scroll  [ enter/esc ] close (dry run, nothing is written)"
+	case app.previewOpen:
+		return "[ j/k ] scroll  [ enter ] write  [ esc/q ] discard change"
+	case app.conflictOpen:
+		return "[ r ] reload  [ o ] overwrite  [ m ] merge  [ esc/q ] cancel"
+	case app.deleteOpen, app.clearOpen:
+		return "[ enter ] confirm  [ esc/q ] cancel"
+	case app.templateOpen:
+		return "[ ↑/↓ ] move  [ enter ] choose template  [ esc/q ] cancel"
+	case app.searchOpen:
+		return "[ enter/↓ ] results  [ j/k ] move  [ enter ] jump  [ / ] edit query  [ esc ] close"
+	case app.inputOpen, app.valuesOpen, app.paramsOpen, app.bulkEditOpen:
+		return "[ tab/shift+tab ] next/prev field  [ enter ] confirm  [ esc ] cancel"
+	case app.renameOpen && app.renameNewName != "":
+		return "[ y ] update includes  [ n ] rename only  [ esc/q ] cancel"
+	case app.renameOpen, app.duplicateOpen, app.diffgenOpen:
+		return "[ enter ] confirm  [ esc ] cancel"
+	case app.environmentOpen:
+		return "[ j/k ] move  [ enter ] switch  [ esc ] cancel"
+	case app.applyTagOpen:
+		return "[ j/k ] move  [ enter ] apply tag  [ esc/q ] cancel"
+	case app.projectsOpen:
+		return "[ j/k ] move  [ enter ] switch project  [ esc/q/W ] cancel"
+	case app.snapshotsOpen && app.restoreTarget != "":
+		return "[ j/k ] scroll  [ enter ] restore  [ esc/q ] cancel"
+	case app.snapshotsOpen:
+		return "[ j/k ] move  [ enter ] choose  [ esc ] cancel"
+	case app.pluginsOpen:
+		return "[ j/k ] move  [ enter ] run  [ esc/q ] cancel"
+	case app.replaceOpen && app.replaceList.HasFocus():
+		return "[ j/k ] move  [ J/K ] scroll diff  [ space ] include/skip file  [ enter ] replace  [ tab ] fields  [ esc ] close"
+	case app.replaceOpen:
+		return "[ tab/shift+tab ] next/prev field  [ ↓ ] files  [ esc ] close"
+	case app.editDiffOpen:
+		return "[ j/k ] scroll  [ u ] undo edit  [ enter/esc/q ] close"
+	case app.versionsOpen:
+		return "[ j/k ] move  [ J/K ] scroll diff  [ enter ] revert  [ esc/q ] close"
+	case app.trashOpen:
+		return "[ j/k ] move  [ enter ] restore  [ D ] delete permanently  [ esc/q ] close"
+	case app.importOpen:
+		return "[ j/k ] move  [ space ] select  [ a ] select all  [ enter ] import  [ esc/q ] cancel"
+	}
+
+	if app.zoom == zoomContent {
+		return "[ J/K ] scroll  [ j/k ] move  [ +/_ ] next/previous zoom  [ ? ] help"
+	}
+
+	action := "apply"
+	if app.currentPanelIdx == 1 {
+		action = "remove"
+	}
+	if dir, ok := app.selectedFolder(); ok {
+		action = "expand"
+		if !app.collapsed[dir] {
+			action = "collapse"
+		}
+	}
+	if _, ok := app.selectedMissing(); ok && len(app.markedInPanel()) == 0 {
+		return "[space/enter] prune from state  [ n ] recreate as stub  [ q ] quit  [ ? ] help"
+	}
+	if n := len(app.markedInPanel()); n > 0 {
+		return fmt.Sprintf("[space/enter] %s %d marked  [ m ] mark  [ D ] delete marked  [ esc ] clear marks  [ ? ] help", action, n)
+	}
+	return fmt.Sprintf("[1-2] panels  [space/enter] %s  [ n ] new  [ d ] duplicate  [ D ] delete  [ r ] rename  [ i ] values  [ m ] mark  [ y/Y ] copy  [ q ] quit  [ ? ] help", action)
+}
+
+// showMessage displays a transient message in the status bar
+func (app *App) showMessage(format string, args ...interface{}) {
+	app.setStatusMessage(fmt.Sprintf(format, args...), false)
+}
+
+// showError displays a transient error in the status bar and records it in the error log
+func (app *App) showError(err error) {
+	app.logError(err)
+	app.setStatusMessage("Error: "+err.Error()+" (! for details)", true)
+}
+
+// maxErrorLog is the number of recent errors kept for the error log view
+const maxErrorLog = 50
+
+// errorEntry is a failure recorded in the error log
+type errorEntry struct {
+	Time    time.Time
+	Message string
+}
+
+// logError records an error in the error log without interrupting the user
+func (app *App) logError(err error) {
+	logging.Logger.Error(err.Error())
+	app.errorLog = append(app.errorLog, errorEntry{Time: time.Now(), Message: err.Error()})
+	if len(app.errorLog) > maxErrorLog {
+		app.errorLog = app.errorLog[len(app.errorLog)-maxErrorLog:]
+	}
+}
+
+func (app *App) setStatusMessage(message string, isError bool) {
+	if app.app == nil {
+		return
+	}
+
+	app.statusSeq++
+	seq := app.statusSeq
+	app.statusMessage = message
+	app.statusIsError = isError
+	app.updateStatusBar()
+
+	time.AfterFunc(statusMessageDuration, func() {
+		app.app.QueueUpdateDraw(func() {
+			// A newer message replaced this one
+			if app.statusSeq != seq {
+				return
+			}
+			app.statusMessage = ""
+			app.updateStatusBar()
+		})
+	})
+}
+
+// modalOpen reports whether any overlay is currently shown
+func (app *App) modalOpen() bool {
+	return app.helpOpen || app.inputOpen || app.deleteOpen || app.renameOpen ||
+		app.valuesOpen || app.duplicateOpen || app.templateOpen || app.conflictOpen ||
+		app.clearOpen || app.errorsOpen || app.previewOpen || app.runnerOpen ||
+		app.envViewOpen || app.searchOpen || app.paramsOpen || app.blockConflictsOpen ||
+		app.lintOpen || app.environmentOpen || app.applyTagOpen || app.snapshotsOpen ||
+		app.importOpen || app.diffgenOpen || app.pluginsOpen || app.trashOpen ||
+		app.versionsOpen || app.editDiffOpen || app.replaceOpen ||
+		app.bulkEditOpen || app.statsOpen || app.recentOpen || app.direnvOpen ||
+		app.commandOpen || app.runTargetsOpen || app.jobsOpen || app.projectsOpen
+}
+
+// stateChangesAllowed reports whether actions that change the applied set may run,
+// showing why not in the status bar when they may not.
+func (app *App) stateChangesAllowed(action string) bool {
+	if app.ReadOnly {
+		app.showError(fmt.Errorf("%s is disabled in read-only mode", action))
+		return false
+	}
+	return true
+}
+
+// writesAllowed reports whether actions that modify override folders may run,
+// showing why not in the status bar when they may not.
+func (app *App) writesAllowed(action string) bool {
+	if !app.stateChangesAllowed(action) {
+		return false
+	}
+	if app.DryRun {
+		app.showError(fmt.Errorf("%s is disabled in dry-run mode", action))
+		return false
+	}
+	return true
+}
+
+// modal creates a centered modal overlay that shows the background through transparent
+// areas. It shrinks to fit screens smaller than width x height.
+func modal(content tview.Primitive, width, height int) tview.Primitive {
+	return &centered{Box: tview.NewBox(), content: content, width: width, height: height}
+}
+
+func (app *App) showErrorLog() {
+	app.errorsOpen = true
+
+	var b strings.Builder
+	if len(app.errorLog) == 0 {
+		b.WriteString("[darkgray]No errors so far[-]")
+	}
+	// Newest first
+	for i := len(app.errorLog) - 1; i >= 0; i-- {
+		entry := app.errorLog[i]
+		fmt.Fprintf(&b, "[darkgray]%s[-] [red]%s[-]\n", entry.Time.Format("15:04:05"), tview.Escape(entry.Message))
+	}
+
+	logText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true).
+		SetText(b.String())
+
+	logText.SetBorder(true).
+		SetTitle(" Error Log ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorRed)
+
+	app.pages.AddPage("errors", modal(logText, 80, 20), true, true)
+	app.app.SetFocus(logText)
+}
+
+// showEnvFileView shows the raw env file with the lines lazyhydra manages highlighted
+func (app *App) showEnvFileView() {
+	app.envViewOpen = true
+
+	envText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(app.formatEnvFile())
+
+	envText.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s ", app.EnvFilePath())).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("envfile", modal(envText, 100, 25), true, true)
+	app.app.SetFocus(envText)
+}
+
+func (app *App) closeEnvFileView() {
+	app.envViewOpen = false
+	app.pages.RemovePage("envfile")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// formatEnvFile renders the env file, highlighting lazyhydra-managed lines
+func (app *App) formatEnvFile() string {
+	data, err := app.FS.ReadFile(app.EnvFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "[darkgray](file does not exist yet; it is created on the first save)[-]"
+		}
+		return fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error()))
+	}
+
+	managed := app.ManagedEnvVars(data)
+	var b strings.Builder
+	for _, line := range splitLines(string(data)) {
+		if app.IsManagedEnvLine(line, managed) {
+			fmt.Fprintf(&b, "[black:green]%s[-:-]  [green]← lazyhydra[-]\n", tview.Escape(line))
+		} else {
+			fmt.Fprintf(&b, "%s\n", tview.Escape(line))
+		}
+	}
+	return b.String()
+}
+
+// editEnvFile opens the env file in $EDITOR and reloads the state afterwards
+func (app *App) editEnvFile() {
+	if !app.writesAllowed("Editing the env file") {
+		return
+	}
+
+	app.closeEnvFileView()
+	if err := app.editProjectFile(app.EnvFilePath()); err != nil {
+		app.showError(err)
+	}
+	app.reloadFromDisk()
+	app.showEnvFileView()
+}
+
+func (app *App) closeErrorLog() {
+	app.errorsOpen = false
+	app.pages.RemovePage("errors")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+func (app *App) showDeleteConfirmation() {
+	targets := app.actionTargets()
+	if len(targets) == 0 || !app.writesAllowed("Deleting") {
+		return
+	}
+
+	app.deleteOpen = true
+
+	subject := fmt.Sprintf(`"[red]%s[-]"`, targets[0].Name)
+	folder := "folder"
+	if len(targets) > 1 {
+		subject = fmt.Sprintf("[red]%d marked overrides[-]", len(targets))
+		folder = "folders"
+	}
+
+	confirmText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText(fmt.Sprintf(`[yellow::b]Delete Override[-:-:-]
+
+Are you sure you want to delete %s?
+
+This moves the override %s to the trash.
+
+[green]Enter[-] to confirm    [yellow]Esc/q[-] to cancel`, subject, folder))
+
+	confirmText.SetBorder(true).
+		SetTitle(" Confirm Delete ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorRed)
+
+	app.pages.AddPage("delete", modal(confirmText, 55, 11), true, true)
+	app.app.SetFocus(confirmText)
+}
+
+func (app *App) closeDeleteConfirmation() {
+	app.deleteOpen = false
+	app.pages.RemovePage("delete")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+func (app *App) deleteSelectedOverride() {
+	targets := app.actionTargets()
+	if len(targets) == 0 {
+		return
+	}
+
+	var trashed []*override.Override
+	for _, selected := range targets {
+		// Move the folder to the trash first, so a failure leaves the override in place
+		if err := app.trashOverride(selected.Name, selected.FolderPath); err != nil {
+			app.showError(err)
+			continue
+		}
+		trashed = append(trashed, selected)
+
+		// Remove its symlink and drop it from the applied, marked and listed overrides
+		app.Forget(selected)
+		delete(app.marked, selected.Name)
+	}
+
+	if len(trashed) == 0 {
+		return
+	}
+
+	// Save state and refresh
+	saved := app.persistState()
+	app.refreshAll()
+	if !saved {
+		return
+	}
+
+	if len(trashed) == 1 {
+		app.showMessage("Moved %s to the trash (T to restore)", trashed[0].Name)
+	} else {
+		app.showMessage("Moved %d overrides to the trash (T to restore)", len(trashed))
+	}
+}
+
+// showWritePreview shows the env file diff a save would write. Outside dry-run mode
+// the write happens only after it is confirmed.
+func (app *App) showWritePreview() {
+	app.previewOpen = true
+
+	envrcPath := app.EnvFilePath()
+	title := fmt.Sprintf(" Preview: %s ", envrcPath)
+	if app.DryRun {
+		title = fmt.Sprintf(" Dry Run: %s ", envrcPath)
+	}
+
+	previewText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(formatDiffColored(app.envFileDiff()))
+
+	previewText.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorYellow)
+
+	app.pages.AddPage("preview", modal(previewText, 100, 20), true, true)
+	app.app.SetFocus(previewText)
+}
+
+func (app *App) closeWritePreview() {
+	app.previewOpen = false
+	app.pages.RemovePage("preview")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// confirmWritePreview writes the previewed state. In dry-run mode it only closes the
+// preview, keeping the change in memory.
+func (app *App) confirmWritePreview() {
+	app.closeWritePreview()
+	if app.DryRun {
+		return
+	}
+	if err := app.writePersistedState(); err != nil {
+		app.showError(err)
+		return
+	}
+	app.showMessage("Saved %s", app.Config.ProjectEnvFile)
+}
+
+// cancelWritePreview discards the previewed change, restoring the last saved state.
+// In dry-run mode the change is kept in memory, since nothing is written anyway.
+func (app *App) cancelWritePreview() {
+	app.closeWritePreview()
+	if app.DryRun {
+		return
+	}
+	app.Applied = copyApplied(app.savedApplied)
+	app.ReconcileSymlinks()
+	app.refreshAll()
+	app.showMessage("Discarded unsaved change")
+}
+
+func (app *App) showRenameInput() {
+	selected := app.getSelectedOverride()
+	if selected == nil || !app.writesAllowed("Renaming") {
+		return
+	}
+
+	app.renameOpen = true
+	app.renameTarget = selected
+
+	inputField := tview.NewInputField().
+		SetLabel("New name: ").
+		SetText(selected.Name).
+		SetFieldWidth(40).
+		SetFieldBackgroundColor(tcell.ColorDefault)
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		newName := strings.TrimSpace(inputField.GetText())
+		if key != tcell.KeyEnter || newName == "" || newName == app.renameTarget.Name {
+			app.closeRenameInput()
+			return
+		}
+		if err := app.checkNewName(app.renameTarget, newName); err != nil {
+			app.closeRenameInput()
+			app.showError(err)
+			return
+		}
+		if refs := app.includedBy(app.renameTarget); len(refs) > 0 {
+			app.showRenameReferences(newName, refs)
+			return
+		}
+		app.renameSelectedOverride(newName, false)
+		app.closeRenameInput()
+	})
+
+	inputField.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Rename: %s ", selected.Name)).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("rename", modal(inputField, 60, 3), true, true)
+	app.app.SetFocus(inputField)
+}
+
+func (app *App) closeRenameInput() {
+	app.renameOpen = false
+	app.renameTarget = nil
+	app.renameNewName = ""
+	app.pages.RemovePage("rename")
+	app.pages.RemovePage("rename-refs")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// checkNewName reports why o cannot be renamed or duplicated to newName: names are
+// folder names, so they cannot contain path separators, and they must stay unique.
+func (app *App) checkNewName(o *override.Override, newName string) error {
+	if strings.ContainsAny(newName, `/\`) || strings.HasPrefix(newName, ".") {
+		return fmt.Errorf("invalid override name %q", newName)
+	}
+	if other := app.Find(newName); other != nil {
+		return fmt.Errorf("override %q already exists in %s", newName, other.FolderPath)
+	}
+	newPath := filepath.Join(filepath.Dir(o.FolderPath), newName)
+	if _, err := app.FS.Lstat(newPath); err == nil {
+		return fmt.Errorf("%s already exists", newPath)
+	}
+	return nil
+}
+
+// includedBy returns the composite overrides whose includes name o
+func (app *App) includedBy(o *override.Override) []*override.Override {
+	var refs []*override.Override
+	for _, c := range app.Overrides {
+		for _, name := range c.Includes {
+			if name == o.Name {
+				refs = append(refs, c)
+				break
+			}
+		}
+	}
+	return refs
+}
+
+// showRenameReferences asks whether the composites including the override being
+// renamed should have their includes updated to the new name
+func (app *App) showRenameReferences(newName string, refs []*override.Override) {
+	app.renameNewName = newName
+	app.pages.RemovePage("rename")
+
+	var names []string
+	for _, c := range refs {
+		names = append(names, c.Name)
+	}
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetWrap(true).
+		SetText(fmt.Sprintf(`[yellow::b]Rename %s to %s[-:-:-]
+
+Included by: %s
+
+[green]y[-] update their includes    [green]n[-] rename only    [yellow]Esc/q[-] cancel`,
+			tview.Escape(app.renameTarget.Name), tview.Escape(newName), tview.Escape(strings.Join(names, ", "))))
+	view.SetBorder(true).
+		SetTitle(" Update References ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorYellow)
+
+	app.pages.AddPage("rename-refs", modal(view, 64, 9), true, true)
+	app.app.SetFocus(view)
+}
+
+// renameSelectedOverride renames the override being renamed to newName. With
+// updateRefs, composites that include it are changed to include the new name.
+func (app *App) renameSelectedOverride(newName string, updateRefs bool) {
+	if app.renameTarget == nil {
+		return
+	}
+	if err := app.checkNewName(app.renameTarget, newName); err != nil {
+		app.showError(err)
+		return
+	}
+	var refs []*override.Override
+	if updateRefs {
+		refs = app.includedBy(app.renameTarget)
+	}
+
+	oldName := app.renameTarget.Name
+	oldPath := app.renameTarget.FolderPath
+
+	// Rename the folder on disk, moving its symlink along
+	if err := app.Rename(app.renameTarget, newName); err != nil {
+		app.showError(err)
+		return
+	}
+
+	delete(app.marked, oldName)
+	app.ui.moveOverride(oldPath, app.renameTarget.FolderPath)
+	app.saveUIState()
+
+	// Point the composites that include it at the new name
+	updated := 0
+	for _, c := range refs {
+		content, err := app.FS.ReadFile(filepath.Join(c.FolderPath, "apply.md"))
+		if err == nil {
+			var renamed string
+			if renamed, err = override.RenameInclude(string(content), oldName, newName); err == nil {
+				err = app.writeOverrideFile(c, "apply.md", []byte(renamed))
+			}
+		}
+		if err != nil {
+			app.logError(fmt.Errorf("updating includes of %s: %w", c.Name, err))
+			continue
+		}
+		app.reloadOverride(c.Name)
+		updated++
+	}
+	override.ResolveIncludes(app.Overrides)
+
+	// Re-sort overrides
+	sort.Slice(app.Overrides, func(i, j int) bool {
+		return app.Overrides[i].Name < app.Overrides[j].Name
+	})
+
+	// Save state and refresh
+	saved := app.persistState()
+	app.refreshAll()
+	if !saved {
+		return
+	}
+	if updated > 0 {
+		app.showMessage("Renamed %s to %s and updated %d composite(s)", oldName, newName, updated)
+	} else {
+		app.showMessage("Renamed %s to %s", oldName, newName)
+	}
+}
+
+func (app *App) showDuplicateInput() {
+	selected := app.getSelectedOverride()
+	if selected == nil || !app.writesAllowed("Duplicating") {
+		return
+	}
+
+	app.duplicateOpen = true
+	app.duplicateSource = selected
+
+	inputField := tview.NewInputField().
+		SetLabel("New name: ").
+		SetText(selected.Name + "_copy").
+		SetFieldWidth(40).
+		SetFieldBackgroundColor(tcell.ColorDefault)
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			newName := strings.TrimSpace(inputField.GetText())
+			if newName != "" && newName != app.duplicateSource.Name {
+				app.duplicateSelectedOverride(newName)
+			}
+		}
+		app.closeDuplicateInput()
+	})
+
+	inputField.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Duplicate: %s ", selected.Name)).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("duplicate", modal(inputField, 60, 3), true, true)
+	app.app.SetFocus(inputField)
+}
+
+func (app *App) closeDuplicateInput() {
+	app.duplicateOpen = false
+	app.duplicateSource = nil
+	app.pages.RemovePage("duplicate")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+func (app *App) duplicateSelectedOverride(newName string) {
+	if app.duplicateSource == nil {
+		return
+	}
+
+	selected := app.duplicateSource
+	if err := app.checkNewName(selected, newName); err != nil {
+		app.showError(err)
+		return
+	}
+	newPath := filepath.Join(filepath.Dir(selected.FolderPath), newName)
+
+	// Copy the folder recursively
+	if err := copyDir(app.FS, selected.FolderPath, newPath); err != nil {
+		app.showError(err)
+		return
+	}
+	// The copy starts without the original's history
+	app.FS.RemoveAll(filepath.Join(newPath, override.HistoryDir))
+
+	// Create the new override in memory
+	newOverride := *selected
+	newOverride.Name = newName
+	newOverride.FolderPath = newPath
+	app.Overrides = append(app.Overrides, &newOverride)
+
+	// Re-sort overrides
+	sort.Slice(app.Overrides, func(i, j int) bool {
+		return app.Overrides[i].Name < app.Overrides[j].Name
+	})
+
+	app.refreshAll()
+	app.showMessage("Duplicated %s as %s", selected.Name, newName)
+}
+
+// copyDir recursively copies a directory within store
+func copyDir(store fsys.Store, src, dst string) error {
+	return fsys.WalkDir(store, src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		// Calculate the destination path
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dst, relPath)
+
+		if info.IsDir() {
+			return store.MkdirAll(dstPath, info.Mode())
+		}
+
+		// Copy the file
+		data, err := store.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return store.WriteFile(dstPath, data, info.Mode())
+	})
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/state"
+)
+
+func TestCheckNewName(t *testing.T) {
+	store := fsys.NewMemory()
+	for _, name := range []string{"foo", "bar"} {
+		store.MkdirAll("/proj/conf/overrides/"+name, 0755)
+		store.WriteFile("/proj/conf/overrides/"+name+"/apply.md", []byte("---\ntype: \"++\"\n---\n"), 0644)
+	}
+	store.MkdirAll("/proj/conf/overrides/notes", 0755)
+
+	cfg := config.Default()
+	cfg.OverridesDir = "/proj/conf/overrides"
+	app := &App{Project: &state.Project{Config: cfg, FS: store, Root: "/proj", Applied: make(map[string]bool)}}
+	if err := app.LoadOverrides(); err != nil {
+		t.Fatal(err)
+	}
+	foo := app.Find("foo")
+
+	tests := []struct {
+		name    string
+		newName string
+		ok      bool
+	}{
+		{"free", "foo_copy", true},
+		{"slash", "a/b", false},
+		{"backslash", `a\b`, false},
+		{"parent", "..", false},
+		{"hidden", ".foo", false},
+		{"taken", "bar", false},
+		{"existing folder", "notes", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := app.checkNewName(foo, tt.newName); (err == nil) != tt.ok {
+				t.Errorf("checkNewName(%q) = %v, want ok %v", tt.newName, err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+var (
+	mdBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalic     = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	mdInlineCode = regexp.MustCompile("`([^`]+)`")
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	mdListItem   = regexp.MustCompile(`^(\s*)([-*+]|\d+\.)\s+(.*)$`)
+	mdHeading    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+)
+
+// renderMarkdown renders a subset of markdown (headings, emphasis, inline code, links,
+// lists, quotes and fenced code blocks) with tview color tags.
+func renderMarkdown(text string) string {
+	var b strings.Builder
+	var code strings.Builder
+	inCode := false
+	codeLang := ""
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				b.WriteString(highlightCode(strings.TrimSuffix(code.String(), "\n"), codeLang))
+				b.WriteString("\n")
+				code.Reset()
+				inCode = false
+			} else {
+				inCode = true
+				codeLang = strings.TrimPrefix(trimmed, "```")
+			}
+			continue
+		}
+		if inCode {
+			code.WriteString(line + "\n")
+			continue
+		}
+
+		if m := mdHeading.FindStringSubmatch(trimmed); m != nil {
+			fmt.Fprintf(&b, "[yellow::b]%s[-:-:-]\n", renderInline(m[2]))
+			continue
+		}
+		if m := mdListItem.FindStringSubmatch(line); m != nil {
+			bullet := "•"
+			if m[2] != "-" && m[2] != "*" && m[2] != "+" {
+				bullet = m[2]
+			}
+			fmt.Fprintf(&b, "%s[green]%s[-] %s\n", m[1], bullet, renderInline(m[3]))
+			continue
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			fmt.Fprintf(&b, "[darkgray]│ %s[-]\n", renderInline(strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))))
+			continue
+		}
+		if trimmed == "---" || trimmed == "***" {
+			b.WriteString("[darkgray]────────────────────[-]\n")
+			continue
+		}
+
+		b.WriteString(renderInline(line) + "\n")
+	}
+
+	// Unterminated code block
+	if inCode {
+		b.WriteString(highlightCode(code.String(), codeLang))
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// renderInline renders inline markdown spans within a single line
+func renderInline(text string) string {
+	// Replace code spans and links with placeholders so escaping and emphasis leave them alone
+	var spans []string
+	placeholder := func(rendered string) string {
+		spans = append(spans, rendered)
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1)
+	}
+	text = mdInlineCode.ReplaceAllStringFunc(text, func(span string) string {
+		return placeholder("[orange]" + tview.Escape(span[1:len(span)-1]) + "[-]")
+	})
+	text = mdLink.ReplaceAllStringFunc(text, func(span string) string {
+		m := mdLink.FindStringSubmatch(span)
+		return placeholder("[blue::u]" + tview.Escape(m[1]) + "[-::-] [darkgray](" + tview.Escape(m[2]) + ")[-]")
+	})
+
+	text = tview.Escape(text)
+	text = mdBold.ReplaceAllString(text, "[::b]$1$2[::-]")
+	text = mdItalic.ReplaceAllString(text, "[::i]$1$2[::-]")
+
+	for i, span := range spans {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), span, 1)
+	}
+	return text
+}
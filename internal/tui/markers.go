@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/rivo/tview"
+)
+
+// markerTexts are the marker texts of each markers.style, by override type
+var markerTexts = map[string]map[string]string{
+	config.MarkerStyleSymbols: {"+": "+", "=": "=", "++": "++", "--": "--"},
+	config.MarkerStyleBadges:  {"+": "[merge]", "=": "[replace]", "++": "[set]", "--": "[delete]"},
+	config.MarkerStyleShapes:  {"+": "●", "=": "■", "++": "▲", "--": "▼"},
+}
+
+// markerColors are the default marker colors, by override type
+var markerColors = map[string]string{"+": "green", "=": "yellow", "++": "aqua", "--": "red"}
+
+// typeMarker returns the marker for an override type, colored and styled as markers in
+// config.yaml asks. Types without a default are shown as they are.
+func (app *App) typeMarker(t string) string {
+	markers := app.Config.Markers
+	text, ok := markers.Text[t]
+	if !ok {
+		text = markerTexts[markers.Style][t]
+	}
+	if text == "" {
+		text = t
+	}
+	color := markers.Colors[t]
+	if color == "" {
+		color = markerColors[t]
+	}
+	if color == "" {
+		color = "white"
+	}
+	return "[" + color + "]" + tview.Escape(text) + "[-]"
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// mergeNodes merges the src mapping into dst the way Hydra merges configs: nested
+// mappings are merged key by key, anything else in src replaces the value in dst.
+func mergeNodes(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		found := false
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value != key.Value {
+				continue
+			}
+			if dst.Content[j+1].Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				mergeNodes(dst.Content[j+1], value)
+			} else {
+				dst.Content[j+1] = value
+			}
+			found = true
+			break
+		}
+		if !found {
+			dst.Content = append(dst.Content, key, value)
+		}
+	}
+}
+
+// encodeYAML encodes a node with the indentation lazyhydra writes files with
+func encodeYAML(node *yaml.Node) (string, error) {
+	var buf strings.Builder
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return "", err
+	}
+	encoder.Close()
+	return buf.String(), nil
+}
+
+// mergedConfig returns the base config of an override's block before and after the
+// override is merged into it, or replaces it for "=" overrides. Both are re-encoded so
+// they differ only where the override changes something.
+func (app *App) mergedConfig(o *override.Override) (base, merged string, err error) {
+	basePath, err := app.blockBaseConfig(o)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := app.FS.ReadFile(basePath)
+	if err != nil {
+		return "", "", err
+	}
+	baseRoot, err := parseYAMLMapping(data)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing %s: %w", filepath.Base(basePath), err)
+	}
+	if base, err = encodeYAML(baseRoot); err != nil {
+		return "", "", err
+	}
+
+	own, err := parseYAMLMapping([]byte(o.RenderedContent()))
+	if err != nil {
+		return "", "", fmt.Errorf("parsing %s/override.yaml: %w", o.Name, err)
+	}
+	if o.Type != "=" && o.Type != "replace" {
+		mergeNodes(baseRoot, own)
+		own = baseRoot
+	}
+	if merged, err = encodeYAML(own); err != nil {
+		return "", "", err
+	}
+	return base, merged, nil
+}
+
+// formatMergedContent returns the effective config of an override's block for the
+// content view, with the lines the override adds or changes highlighted.
+func (app *App) formatMergedContent(o *override.Override) string {
+	verb := "merged into"
+	if o.Type == "=" || o.Type == "replace" {
+		verb = "replacing"
+	}
+	target := o.Block
+	if basePath, err := app.blockBaseConfig(o); err == nil {
+		if rel, err := filepath.Rel(app.Config.ExpandPath(app.Config.HydraConfigsDir), basePath); err == nil {
+			target = filepath.ToSlash(rel)
+		}
+	}
+	header := fmt.Sprintf("[cyan::b]# %s %s %s[-:-:-]\n\n", o.Name, verb, tview.Escape(target))
+
+	base, merged, err := app.mergedConfig(o)
+	if err != nil {
+		return header + fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error()))
+	}
+
+	// Lines of the base config the override changes are left out; their new
+	// versions are shown in green
+	var b strings.Builder
+	for _, line := range lineDiff(base, merged) {
+		switch line.Op {
+		case '+':
+			fmt.Fprintf(&b, "[green]%s[-]\n", tview.Escape(line.Text))
+		case ' ':
+			fmt.Fprintf(&b, "%s\n", tview.Escape(line.Text))
+		}
+	}
+	return header + strings.TrimRight(b.String(), "\n")
+}
+
+// toggleMergedPreview switches the content view between the override and the effective
+// config of its block
+func (app *App) toggleMergedPreview() {
+	app.mergedPreview = !app.mergedPreview
+	app.updateContentAndInfo()
+	if app.mergedPreview {
+		app.showMessage("Showing the merged config of the block")
+	} else {
+		app.showMessage("Showing override.yaml")
+	}
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// stubDescription is written to apply.md of an override recreated for a missing name
+const stubDescription = "Recreated stub, fill in its metadata and override.yaml"
+
+// missingRows returns the Applied panel rows listing applied names that match no
+// override. They follow the applied overrides until they are pruned or recreated.
+func (app *App) missingRows() []appliedRow {
+	var rows []appliedRow
+	for _, name := range app.MissingApplied() {
+		rows = append(rows, appliedRow{missing: name})
+	}
+	return rows
+}
+
+// formatMissingRow returns the list text for a missing override in the Applied panel
+func formatMissingRow(row appliedRow) string {
+	return fmt.Sprintf("[red]?[-] %s [red](missing)[-]", tview.Escape(row.missing))
+}
+
+// selectedMissing returns the name under the cursor when it is a missing override
+func (app *App) selectedMissing() (string, bool) {
+	if app.currentPanelIdx != 1 {
+		return "", false
+	}
+	idx := app.appliedList.GetCurrentItem()
+	if idx < 0 || idx >= len(app.appliedRows) || app.appliedRows[idx].missing == "" {
+		return "", false
+	}
+	return app.appliedRows[idx].missing, true
+}
+
+// formatMissing explains a missing override in the content view
+func (app *App) formatMissing(name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[red::b]%s is missing[-:-:-]\n\n", tview.Escape(name))
+	fmt.Fprintf(&b, "%s records it as applied, but no override folder with this name\n", tview.Escape(app.Config.ProjectEnvFile))
+	fmt.Fprintf(&b, "was found in %s. It was probably deleted or renamed\n", tview.Escape(app.Config.OverridesDir))
+	b.WriteString("outside lazyhydra. It stays recorded until you choose what to do:\n\n")
+	b.WriteString("  [green]space/enter[-]  prune it from the applied state\n")
+	b.WriteString("  [green]n[-]            recreate it as a stub override and keep it applied")
+	return b.String()
+}
+
+// pruneMissing removes a missing override from the applied state
+func (app *App) pruneMissing(name string) {
+	if !app.stateChangesAllowed("Pruning") {
+		return
+	}
+	delete(app.Applied, name)
+	saved := app.persistState()
+	app.refreshAll()
+	if saved {
+		app.showMessage("Pruned %s from the applied state", name)
+	}
+}
+
+// recreateMissing creates an empty override for a missing name. It stays applied, so
+// the env file keeps recording it once the override is filled in.
+func (app *App) recreateMissing(name string) {
+	if !app.writesAllowed("Recreating") {
+		return
+	}
+	app.createNewOverride(name, "", override.Meta{Description: stubDescription})
+	if o := app.Find(name); o != nil {
+		if err := app.Link(o); err != nil {
+			app.showError(err)
+			return
+		}
+		for i, row := range app.appliedRows {
+			if row.override == o {
+				app.appliedList.SetCurrentItem(i)
+			}
+		}
+		app.showMessage("Recreated %s as a stub; edit it with e and E", name)
+	}
+}
+
+// reportMissing warns about applied names that match no override, so they are
+// noticed when the TUI starts or the overrides are reloaded
+func (app *App) reportMissing() {
+	missing := app.MissingApplied()
+	if len(missing) == 0 {
+		return
+	}
+	subject := fmt.Sprintf("%d applied overrides are", len(missing))
+	if len(missing) == 1 {
+		subject = fmt.Sprintf("Applied override %s is", missing[0])
+	}
+	app.setStatusMessage(fmt.Sprintf("Warning: %s missing from %s; see the Applied panel", subject, app.Config.OverridesDir), true)
+}
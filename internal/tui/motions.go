@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxCount caps count prefixes, so a mistyped 99999j does not spin
+const maxCount = 9999
+
+// handleMotionKey collects Vim-style count prefixes and the first g of gg in the main
+// view, and handles the motions that are not bound elsewhere: gg and G jump to the top
+// and bottom of the focused list, { and } to the previous and next paragraph of the
+// content view. It reports whether it consumed the key. j, k, J and K take the count
+// themselves with takeCount.
+//
+// A count cannot start with 1 or 2, which focus the panels.
+func (app *App) handleMotionKey(event *tcell.EventKey) bool {
+	r := event.Rune()
+	if event.Key() != tcell.KeyRune {
+		r = 0
+	}
+
+	if app.gPending {
+		app.gPending = false
+		if r == 'g' {
+			app.cursorTo(app.takeCount() - 1)
+			return true
+		}
+	}
+
+	switch {
+	case r >= '3' && r <= '9', r >= '0' && r <= '9' && app.countPrefix > 0:
+		app.countPrefix = min(app.countPrefix*10+int(r-'0'), maxCount)
+		return true
+	case r == 'g':
+		app.gPending = true
+		return true
+	case r == 'G':
+		if app.countPrefix > 0 {
+			app.cursorTo(app.takeCount() - 1)
+		} else {
+			app.cursorTo(app.currentList().GetItemCount() - 1)
+		}
+		return true
+	case r == '}':
+		app.jumpParagraph(app.takeCount())
+		return true
+	case r == '{':
+		app.jumpParagraph(-app.takeCount())
+		return true
+	case r == 'j', r == 'k', r == 'J', r == 'K':
+		return false
+	}
+	app.countPrefix = 0
+	return false
+}
+
+// takeCount returns the pending count prefix, or 1 without one, and clears it
+func (app *App) takeCount() int {
+	n := app.countPrefix
+	app.countPrefix = 0
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+// currentList returns the list of the focused panel
+func (app *App) currentList() *tview.List {
+	if app.currentPanelIdx == 1 {
+		return app.appliedList
+	}
+	return app.availableList
+}
+
+// cursorTo moves the cursor of the focused list to index, clamped to the list
+func (app *App) cursorTo(index int) {
+	list := app.currentList()
+	index = min(max(index, 0), list.GetItemCount()-1)
+	if index >= 0 {
+		list.SetCurrentItem(index)
+	}
+	app.updateContentAndInfo()
+}
+
+// jumpParagraph scrolls the content view by n paragraphs, forward when n is positive.
+// Paragraphs are separated by blank lines, so each file and section of the content is one.
+func (app *App) jumpParagraph(n int) {
+	lines := strings.Split(app.contentView.GetText(true), "\n")
+	starts := []int{0}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i-1]) == "" && strings.TrimSpace(lines[i]) != "" {
+			starts = append(starts, i)
+		}
+	}
+
+	row, col := app.contentView.GetScrollOffset()
+	current := 0
+	for i, start := range starts {
+		if start <= row {
+			current = i
+		}
+	}
+	// Going back from inside a paragraph first returns to its start
+	if n < 0 && starts[current] < row {
+		n++
+	}
+	target := min(max(current+n, 0), len(starts)-1)
+	app.contentView.ScrollTo(starts[target], col)
+}
+
+// pageList moves the cursor of the focused list by a page of rows, down when pages is
+// positive
+func (app *App) pageList(pages int) {
+	list := app.currentList()
+	// Every item takes two rows, its name and its description, unless descriptions are
+	// hidden
+	rows := 1
+	if app.Config.ShowDescriptions {
+		rows = 2
+	}
+	_, _, _, height := list.GetInnerRect()
+	app.cursorTo(list.GetCurrentItem() + pages*max(height/rows, 1))
+}
+
+// scrollContentHalfPage scrolls the content view by half its height, down when halves is
+// positive
+func (app *App) scrollContentHalfPage(halves int) {
+	_, _, _, height := app.contentView.GetInnerRect()
+	row, col := app.contentView.GetScrollOffset()
+	app.contentView.ScrollTo(max(row+halves*max(height/2, 1), 0), col)
+}
@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// showNewOverrideForm shows a form for the name and frontmatter of a new override,
+// prefilled from the template's apply.md when one was chosen.
+func (app *App) showNewOverrideForm(templateName string) {
+	app.inputOpen = true
+
+	var meta override.Meta
+	if templateName != "" {
+		applyPath := filepath.Join(config.TemplatesDir(), templateName, "apply.md")
+		if content, err := app.FS.ReadFile(applyPath); err == nil {
+			if frontmatter, _, ok := override.SplitFrontmatter(string(content)); ok {
+				yaml.Unmarshal([]byte(frontmatter), &meta)
+			}
+		}
+	}
+
+	typeIdx := 0
+	for i, t := range override.Types {
+		if t == meta.Type {
+			typeIdx = i
+		}
+	}
+
+	// Start inside the folder under the cursor; names may contain / to nest overrides
+	namePrefix := ""
+	if dir := app.currentFolder(); dir != "" {
+		namePrefix = dir + "/"
+	}
+
+	form := tview.NewForm().
+		AddInputField("Name", namePrefix, 40, nil, nil).
+		AddDropDown("Type", override.Types, typeIdx, nil).
+		AddInputField("Block", meta.Block, 40, nil, nil).
+		AddInputField("File", meta.File, 40, nil, nil).
+		AddInputField("Module path", meta.ModulePath, 40, nil, nil).
+		AddInputField("Module", meta.Module, 40, nil, nil).
+		AddInputField("Package", meta.Package, 40, nil, nil).
+		AddInputField("Description", meta.Description, 40, nil, nil)
+
+	text := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+	}
+
+	form.AddButton("Create", func() {
+		name := strings.Trim(text("Name"), "/")
+		if name != "" {
+			_, typ := form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
+			app.createNewOverride(name, templateName, override.Meta{
+				Type:        typ,
+				Block:       text("Block"),
+				File:        text("File"),
+				ModulePath:  text("Module path"),
+				Module:      text("Module"),
+				Package:     text("Package"),
+				Description: text("Description"),
+			})
+		}
+		app.closeInput()
+	})
+	form.AddButton("Cancel", func() {
+		app.closeInput()
+	})
+
+	title := " New Override "
+	if templateName != "" {
+		title = fmt.Sprintf(" New Override (%s) ", templateName)
+	}
+
+	form.SetFieldBackgroundColor(tcell.ColorDefault).
+		SetButtonBackgroundColor(tcell.NewRGBColor(106, 159, 181))
+	form.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("input", modal(form, 60, 21), true, true)
+	app.app.SetFocus(form)
+}
+
+func (app *App) closeInput() {
+	app.inputOpen = false
+	app.pages.RemovePage("input")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+func (app *App) createNewOverride(path, templateName string, meta override.Meta) {
+	overridePath, name, group, err := app.newOverridePath(path)
+	if err != nil {
+		app.showError(err)
+		return
+	}
+
+	fields := [][2]string{
+		{"type", meta.Type},
+		{"block", meta.Block},
+		{"file", meta.File},
+		{"module_path", meta.ModulePath},
+		{"module", meta.Module},
+		{"description", meta.Description},
+	}
+	if meta.Package != "" {
+		fields = append(fields, [2]string{"package", meta.Package})
+	}
+
+	applyPath := filepath.Join(overridePath, "apply.md")
+	applyContent := "---\n---\n"
+
+	if templateName != "" {
+		// Render the template folder into the new override
+		data := newTemplateData(name)
+		src := filepath.Join(config.TemplatesDir(), templateName)
+		if err := renderTemplateDir(app.FS, src, overridePath, data); err != nil {
+			app.FS.RemoveAll(overridePath)
+			app.showError(err)
+			return
+		}
+		if content, err := app.FS.ReadFile(applyPath); err == nil {
+			applyContent = string(content)
+		}
+
+		// Form values were prefilled from the raw template, so render them too
+		for i := range fields {
+			if rendered, err := renderTemplateString(fields[i][1], data); err == nil {
+				fields[i][1] = rendered
+			}
+		}
+	} else {
+		// Create the folder
+		if err := app.FS.MkdirAll(overridePath, 0755); err != nil {
+			app.showError(err)
+			return
+		}
+
+		// Create empty override.yaml
+		overrideYAMLPath := filepath.Join(overridePath, "override.yaml")
+		if err := app.FS.WriteFile(overrideYAMLPath, []byte{}, 0644); err != nil {
+			app.showError(err)
+			return
+		}
+	}
+
+	// override.yaml declares the package too, for Hydra to read wherever the config is used
+	if meta.Package != "" {
+		pkg := fields[len(fields)-1][1]
+		overrideYAMLPath := filepath.Join(overridePath, "override.yaml")
+		data, _ := app.FS.ReadFile(overrideYAMLPath)
+		if err := app.FS.WriteFile(overrideYAMLPath, []byte(override.WithPackageHeader(string(data), pkg)), 0644); err != nil {
+			app.showError(err)
+			return
+		}
+	}
+
+	// Write apply.md with the frontmatter from the form
+	content, err := override.SetFrontmatterFields(applyContent, fields)
+	if err != nil {
+		app.showError(err)
+		return
+	}
+	if err := app.FS.WriteFile(applyPath, []byte(content), 0644); err != nil {
+		app.showError(err)
+		return
+	}
+
+	// Add the new override to the list
+	app.Overrides = append(app.Overrides, &override.Override{
+		Name:       name,
+		FolderPath: overridePath,
+		Dir:        group,
+	})
+	app.reloadOverride(name)
+
+	// Re-sort overrides
+	sort.Slice(app.Overrides, func(i, j int) bool {
+		return app.Overrides[i].Name < app.Overrides[j].Name
+	})
+
+	app.refreshAll()
+	app.showMessage("Created %s", name)
+}
+
+// newOverridePath resolves the folder of a new override from its path, the override name
+// optionally prefixed by group folders (logging/wandb_off). It refuses to overwrite an
+// existing override folder or reuse a name from another folder.
+func (app *App) newOverridePath(path string) (overridePath, name, group string, err error) {
+	rel := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", "", "", fmt.Errorf("override %q must stay inside the overrides directory", path)
+	}
+	name = filepath.Base(rel)
+	group = filepath.ToSlash(filepath.Dir(rel))
+	if group == "." {
+		group = ""
+	}
+
+	overridePath = filepath.Join(app.Config.ExpandPath(app.Config.OverridesDir), rel)
+	if _, err := app.FS.Stat(overridePath); err == nil {
+		return "", "", "", fmt.Errorf("override %q already exists", name)
+	}
+	for _, o := range app.Overrides {
+		if o.Name == name {
+			return "", "", "", fmt.Errorf("override %q already exists in %s", name, o.FolderPath)
+		}
+	}
+	return overridePath, name, group, nil
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// showParamsForm asks for the parameter values of an override. On save the values are
+// kept and the override is applied, or re-rendered when it is already applied.
+func (app *App) showParamsForm(o *override.Override) {
+	if !app.stateChangesAllowed("Applying") {
+		return
+	}
+	names := o.ParamNames()
+
+	app.paramsOpen = true
+
+	form := tview.NewForm()
+	for _, name := range names {
+		form.AddInputField(name+": ", o.ParamValue(name), 40, nil, nil)
+	}
+
+	label := "Apply"
+	if app.Applied[o.Name] {
+		label = "Save"
+	}
+	form.AddButton(label, func() {
+		values := make(map[string]string)
+		for i, name := range names {
+			values[name] = form.GetFormItem(i).(*tview.InputField).GetText()
+		}
+		app.closeParamsForm()
+		app.applyWithParams(o, values)
+	})
+	form.AddButton("Cancel", func() {
+		app.closeParamsForm()
+	})
+
+	form.SetFieldBackgroundColor(tcell.ColorDefault).
+		SetButtonBackgroundColor(tcell.NewRGBColor(106, 159, 181))
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Parameters: %s ", o.Name)).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(names)*2 + 5
+	if height > 25 {
+		height = 25
+	}
+	app.pages.AddPage("params", modal(form, 70, height), true, true)
+	app.app.SetFocus(form)
+}
+
+func (app *App) closeParamsForm() {
+	app.paramsOpen = false
+	app.pages.RemovePage("params")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// applyWithParams sets an override's parameter values and applies it with them.
+// Link renders the instance, so dry runs only keep the values in memory.
+func (app *App) applyWithParams(o *override.Override, values map[string]string) {
+	o.ParamValues = values
+
+	wasApplied := app.Applied[o.Name]
+	if err := app.Link(o); err != nil {
+		app.showError(err)
+		return
+	}
+	app.Applied[o.Name] = true
+	delete(app.marked, o.Name)
+	if !wasApplied {
+		app.recordApplied(o)
+		app.recordRecent(o, true)
+		app.saveUIState()
+	}
+
+	// Value overrides embed the rendered values in the override string
+	saved := app.persistState()
+	app.refreshAll()
+	if saved && wasApplied {
+		app.showMessage("Updated parameters of %s", o.Name)
+	} else if saved {
+		app.showMessage("Applied %s", o.Name)
+	}
+}
+
+// formatParams lists an override's parameters and their values for the content view
+func formatParams(o *override.Override) string {
+	content := "[green::b]# Parameters[-:-:-]\n"
+	for _, name := range o.ParamNames() {
+		content += fmt.Sprintf("%s = [yellow]%s[-]\n", name, tview.Escape(o.ParamValue(name)))
+	}
+	return content
+}
@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// pluginTimeout bounds how long a plugin may run before it is killed
+const pluginTimeout = time.Minute
+
+// pluginsDir returns the directory holding plugin executables.
+func pluginsDir() string {
+	return filepath.Join(config.Dir(), "plugins")
+}
+
+// listPlugins returns the sorted names of the executables in pluginsDir
+func listPlugins() []string {
+	entries, err := os.ReadDir(pluginsDir())
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pluginInput is the JSON a plugin receives on stdin: the applied state as printed by
+// `lazyhydra status --json`, every override, and the selection in the TUI.
+type pluginInput struct {
+	statusJSON
+	Overrides []overrideJSON `json:"overrides"`
+	Selected  string         `json:"selected,omitempty"`
+	Marked    []string       `json:"marked"`
+}
+
+// pluginOutput is the JSON a plugin may print on stdout. Empty output changes nothing.
+type pluginOutput struct {
+	Apply   []string `json:"apply"`   // overrides to apply
+	Remove  []string `json:"remove"`  // overrides to remove
+	Message string   `json:"message"` // shown in the status bar
+	Error   string   `json:"error"`   // shown as an error
+}
+
+// buildPluginInput describes the current state for a plugin
+func (app *App) buildPluginInput() pluginInput {
+	input := pluginInput{
+		statusJSON: app.buildStatusJSON(),
+		Overrides:  []overrideJSON{},
+		Marked:     []string{},
+	}
+	for _, o := range app.Overrides {
+		input.Overrides = append(input.Overrides, app.overrideToJSON(o))
+		if app.marked[o.Name] {
+			input.Marked = append(input.Marked, o.Name)
+		}
+	}
+	if o := app.getSelectedOverride(); o != nil {
+		input.Selected = o.Name
+	}
+	return input
+}
+
+// runPlugin runs a plugin executable in the project root with input on stdin and the
+// override variables in env, and parses what it prints. It does not touch the App, so
+// it can run in the background.
+func (app *App) runPlugin(name string, input pluginInput, env []string) (pluginOutput, error) {
+	var out pluginOutput
+	data, err := json.Marshal(input)
+	if err != nil {
+		return out, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, filepath.Join(pluginsDir(), name))
+	if remote == nil {
+		cmd.Dir = app.Root
+	}
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(data)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runLogged(cmd); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return out, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return out, fmt.Errorf("plugin %s printed invalid JSON: %w", name, err)
+	}
+	return out, nil
+}
+
+// applyPluginOutput applies and removes the overrides a plugin asked for, saves the state
+// once, and shows the plugin's message.
+func (app *App) applyPluginOutput(name string, out pluginOutput) {
+	if out.Error != "" {
+		app.showError(fmt.Errorf("plugin %s: %s", name, out.Error))
+		return
+	}
+
+	changed := false
+	if len(out.Apply) > 0 || len(out.Remove) > 0 {
+		if !app.stateChangesAllowed("Plugin changes") {
+			return
+		}
+		byName := make(map[string]*override.Override)
+		for _, o := range app.Overrides {
+			byName[o.Name] = o
+		}
+
+		for _, n := range out.Remove {
+			o := byName[n]
+			if o == nil || !app.Applied[n] {
+				continue
+			}
+			app.removeOverride(o)
+			changed = true
+		}
+		for _, n := range out.Apply {
+			o := byName[n]
+			if o == nil {
+				app.logError(fmt.Errorf("plugin %s: unknown override %q", name, n))
+				continue
+			}
+			if app.Applied[n] || app.rejectIncomplete(o) {
+				continue
+			}
+			if err := app.applyOverride(o); err != nil {
+				app.logError(err)
+			}
+			changed = true
+		}
+	}
+
+	saved := true
+	if changed {
+		app.saveUIState()
+		saved = app.persistState()
+	}
+	app.refreshAll()
+	switch {
+	case !saved:
+	case out.Message != "":
+		app.showMessage("%s", out.Message)
+	default:
+		app.showMessage("Plugin %s finished", name)
+	}
+}
+
+// showPlugins lists the plugin executables to run
+func (app *App) showPlugins() {
+	plugins := listPlugins()
+	if len(plugins) == 0 {
+		app.showMessage("No plugins in %s", pluginsDir())
+		return
+	}
+
+	app.pluginsOpen = true
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+	for _, p := range plugins {
+		name := p
+		list.AddItem(name, "", 0, func() {
+			app.closePlugins()
+			app.startPlugin(name)
+		})
+	}
+
+	list.SetBorder(true).
+		SetTitle(" Plugins ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(plugins) + 2
+	if height > 20 {
+		height = 20
+	}
+	app.pages.AddPage("plugins", modal(list, 50, height), true, true)
+	app.app.SetFocus(list)
+}
+
+// startPlugin runs a plugin in the background and applies its output when it finishes
+func (app *App) startPlugin(name string) {
+	input := app.buildPluginInput()
+	env := app.overrideEnv()
+	app.showMessage("Running plugin %s...", name)
+	go func() {
+		out, err := app.runPlugin(name, input, env)
+		app.app.QueueUpdateDraw(func() {
+			if err != nil {
+				app.showError(err)
+				return
+			}
+			app.applyPluginOutput(name, out)
+		})
+	}()
+}
+
+func (app *App) closePlugins() {
+	app.pluginsOpen = false
+	app.pages.RemovePage("plugins")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/rivo/tview"
+)
+
+// openBookmark opens the project bookmarked as name for --project: it returns the
+// project root and exports it as PROJECT_ROOT
+func openBookmark(name string) (string, error) {
+	cfg, err := config.Load(fsys.OS)
+	if err != nil {
+		return "", fmt.Errorf("loading config: %w", err)
+	}
+	b, ok := cfg.FindProject(name)
+	if !ok {
+		if len(cfg.Projects) == 0 {
+			return "", fmt.Errorf("no project %q: bookmark projects under projects: in %s", name, config.Path())
+		}
+		var names []string
+		for _, b := range cfg.Projects {
+			names = append(names, b.Name)
+		}
+		return "", fmt.Errorf("no project %q (bookmarked: %s)", name, strings.Join(names, ", "))
+	}
+	root := b.Root()
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("project %s: %s is not a directory", name, root)
+	}
+	os.Setenv("PROJECT_ROOT", root)
+	logging.Logger.Debug("project root", "root", root, "source", "--project "+name)
+	return root, nil
+}
+
+// currentBookmark returns the bookmark of the active project, if it has one
+func (app *App) currentBookmark() (config.Bookmark, bool) {
+	for _, b := range app.Config.Projects {
+		if b.Root() == app.Root {
+			return b, true
+		}
+	}
+	return config.Bookmark{}, false
+}
+
+// switchProject makes a bookmarked project the active one without restarting: its
+// config, overrides and applied state are loaded, its lock is taken and the watcher
+// follows it. Nothing of the project left behind needs saving but its session, since
+// every change is saved as it is made; its symlinks stay for its own runs.
+func (app *App) switchProject(b config.Bookmark) {
+	root := b.Root()
+	if root == app.Root {
+		return
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		app.showError(fmt.Errorf("project %s: %s is not a directory", b.Name, root))
+		return
+	}
+
+	// The config takes the project's .lazyhydra.yaml from PROJECT_ROOT
+	previousRoot := app.Root
+	os.Setenv("PROJECT_ROOT", root)
+	cfg, err := config.Load(app.FS)
+	if err != nil {
+		os.Setenv("PROJECT_ROOT", previousRoot)
+		app.showError(fmt.Errorf("project %s: %w", b.Name, err))
+		return
+	}
+	logging.Logger.Debug("switching project", "from", previousRoot, "to", root)
+
+	app.saveSession()
+	app.releaseLock()
+	app.Root = root
+	app.Config = cfg
+	app.Env = app.envFlag
+	app.branch = currentGitBranch(app.FS, root)
+	if cfg.BranchEnvironments && app.Env == "" && app.branch != "" {
+		app.Env = branchEnvironment(app.branch)
+	}
+	app.marked = make(map[string]bool)
+	app.collapsed = make(map[string]bool)
+	app.lockReadOnly = false
+	app.ReadOnly = app.readOnlyFlag || cfg.ReadOnly
+	var lockErr error
+	if !app.ReadOnly && !app.DryRun {
+		lockErr = app.acquireLock("TUI", app.forceLock)
+		var held *lockHeldError
+		if errors.As(lockErr, &held) {
+			app.lockReadOnly = true
+			app.ReadOnly = true
+		} else if lockErr != nil {
+			logging.Logger.Warn("could not lock the project", "root", root, "error", lockErr)
+			lockErr = nil
+		}
+	}
+	app.availableList.ShowSecondaryText(cfg.ShowDescriptions)
+	app.appliedList.ShowSecondaryText(cfg.ShowDescriptions)
+	app.layoutFromConfig()
+
+	if app.watcher != nil {
+		app.watcher.Close()
+		app.watcher = nil
+	}
+	err = app.reloadFromDisk()
+	app.startWatcher()
+	app.availableList.SetCurrentItem(0)
+	app.appliedList.SetCurrentItem(0)
+	if err != nil {
+		app.refreshAll()
+		app.showError(fmt.Errorf("loading overrides: %w", err))
+		return
+	}
+	app.updateDirenvHealth(false)
+	app.restoreSession()
+	if lockErr != nil {
+		app.showError(fmt.Errorf("%w; opened %s read-only", lockErr, b.Name))
+		return
+	}
+	app.showMessage("Switched to %s (%s)", b.Name, root)
+}
+
+// showProjectSwitcher lists the bookmarked projects to switch to
+func (app *App) showProjectSwitcher() {
+	if remote != nil {
+		app.showError(errors.New("switching projects is not supported with --remote and --container"))
+		return
+	}
+	if len(app.Config.Projects) == 0 {
+		app.showError(fmt.Errorf("no projects bookmarked; add them under projects: in %s", config.Path()))
+		return
+	}
+
+	app.projectsOpen = true
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+
+	width := 0
+	for _, b := range app.Config.Projects {
+		width = max(width, len(b.Name))
+	}
+	current := 0
+	for i, b := range app.Config.Projects {
+		label := fmt.Sprintf("  %-*s  [darkgray]%s[-]", width, tview.Escape(b.Name), tview.Escape(b.Root()))
+		if b.Root() == app.Root {
+			label = "[green]●[-] " + label[2:]
+			current = i
+		}
+		list.AddItem(label, "", 0, func() {
+			app.closeProjectSwitcher()
+			app.switchProject(b)
+		})
+	}
+	list.SetCurrentItem(current)
+
+	list.SetBorder(true).
+		SetTitle(" Projects ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := min(len(app.Config.Projects)+2, 20)
+	app.pages.AddPage("projects", modal(list, 80, height), true, true)
+	app.app.SetFocus(list)
+}
+
+func (app *App) closeProjectSwitcher() {
+	app.projectsOpen = false
+	app.pages.RemovePage("projects")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
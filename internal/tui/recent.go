@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// maxRecent is how many recently applied or removed overrides are remembered
+const maxRecent = 20
+
+// recentChange is an override recently applied or removed, for the quick switcher
+type recentChange struct {
+	Path    string    `yaml:"path"` // override folder path
+	Applied bool      `yaml:"applied"`
+	Time    time.Time `yaml:"time"`
+}
+
+// recordRecent puts an override at the top of the recently changed overrides, dropping
+// its earlier entry
+func (app *App) recordRecent(o *override.Override, applied bool) {
+	recent := []recentChange{{Path: o.FolderPath, Applied: applied, Time: time.Now()}}
+	for _, r := range app.ui.Recent {
+		if r.Path != o.FolderPath && len(recent) < maxRecent {
+			recent = append(recent, r)
+		}
+	}
+	app.ui.Recent = recent
+}
+
+// removeOverride unlinks an override and marks it removed, remembering it for the quick
+// switcher
+func (app *App) removeOverride(o *override.Override) {
+	app.Remove(o)
+	app.recordRecent(o, false)
+}
+
+// recentOverrides returns the recently changed overrides that still exist, most recent
+// first
+func (app *App) recentOverrides() ([]recentChange, []*override.Override) {
+	byPath := make(map[string]*override.Override, len(app.Overrides))
+	for _, o := range app.Overrides {
+		byPath[o.FolderPath] = o
+	}
+	var changes []recentChange
+	var overrides []*override.Override
+	for _, r := range app.ui.Recent {
+		if o := byPath[r.Path]; o != nil {
+			changes = append(changes, r)
+			overrides = append(overrides, o)
+		}
+	}
+	return changes, overrides
+}
+
+// showRecent opens the quick switcher: the recently applied and removed overrides, each
+// toggled back with its number key or Enter
+func (app *App) showRecent() {
+	changes, overrides := app.recentOverrides()
+	if len(changes) == 0 {
+		app.showMessage("No overrides applied or removed yet")
+		return
+	}
+	if !app.stateChangesAllowed("Applying and removing") {
+		return
+	}
+
+	app.recentOpen = true
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+	for i, r := range changes {
+		o := overrides[i]
+		state := "[darkgray]  [-]"
+		if app.Applied[o.Name] {
+			state = "[green]✓[-] "
+		}
+		verb := "removed"
+		if r.Applied {
+			verb = "applied"
+		}
+		var shortcut rune
+		if i < 9 {
+			shortcut = rune('1' + i)
+		}
+		list.AddItem(fmt.Sprintf("%s%s [darkgray]%s %s[-]", state, tview.Escape(o.Name), verb, r.Time.Format(detailTimeFormat)),
+			"", shortcut, func() {
+				app.closeRecent()
+				app.toggleRecent(o)
+			})
+	}
+
+	list.SetBorder(true).
+		SetTitle(" Recent Overrides ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(changes) + 2
+	if height > 22 {
+		height = 22
+	}
+	app.pages.AddPage("recent", modal(list, 70, height), true, true)
+	app.app.SetFocus(list)
+}
+
+func (app *App) closeRecent() {
+	app.recentOpen = false
+	app.pages.RemovePage("recent")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// toggleRecent applies an override chosen in the quick switcher, or removes it when it
+// is applied
+func (app *App) toggleRecent(o *override.Override) {
+	verb := "Removed"
+	var linkErr error
+	if app.Applied[o.Name] {
+		app.removeOverride(o)
+	} else {
+		if app.rejectIncomplete(o) {
+			return
+		}
+		verb = "Applied"
+		linkErr = app.applyOverride(o)
+	}
+	app.saveUIState()
+
+	saved := app.persistState()
+	app.refreshAll()
+	if linkErr != nil {
+		app.showError(linkErr)
+		return
+	}
+	if saved {
+		app.showMessage("%s %s", verb, o.Name)
+	}
+}
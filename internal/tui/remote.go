@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+)
+
+// remote is where a project opened with --remote or --container is, nil for a local
+// project
+var remote *fsys.Remote
+
+// openRemote opens the project of a --remote value, [user@]host:/path/to/project, on
+// another machine over ssh
+func openRemote(spec string) (string, error) {
+	host, root, ok := strings.Cut(spec, ":")
+	if !ok || host == "" || !path.IsAbs(root) {
+		return "", fmt.Errorf("--remote wants [user@]host:/absolute/path/to/project, got %q", spec)
+	}
+	return openProjectOn(fsys.NewSSH(host), root, spec)
+}
+
+// openContainer opens the project of a --container value, NAME[:/path/to/project], in
+// a running container with docker exec. Without a path the project is the container's
+// working directory.
+func openContainer(spec string) (string, error) {
+	name, root, _ := strings.Cut(spec, ":")
+	if name == "" || root != "" && !path.IsAbs(root) {
+		return "", fmt.Errorf("--container wants NAME or NAME:/absolute/path/to/project, got %q", spec)
+	}
+	store := fsys.NewDocker(name)
+	if root == "" {
+		output, err := store.Command("pwd", false).CombinedOutput()
+		if err != nil {
+			if out := strings.TrimSpace(string(output)); out != "" {
+				err = fmt.Errorf("%w: %s", err, out)
+			}
+			return "", fmt.Errorf("opening container %s: %w", name, err)
+		}
+		root = strings.TrimSpace(string(output))
+	}
+	return openProjectOn(store, root, spec)
+}
+
+// openProjectOn makes the project at root on store the one lazyhydra works on: files
+// inside the project are read and written there, and commands run there. It returns
+// the project root and exports it as PROJECT_ROOT.
+func openProjectOn(store *fsys.Remote, root, spec string) (string, error) {
+	if runtime.GOOS == "windows" {
+		return "", fmt.Errorf("--remote and --container are not supported on Windows; run lazyhydra in WSL")
+	}
+	root = path.Clean(root)
+
+	// Connects before the TUI starts, so ssh can ask for a password or host key
+	info, err := store.Stat(root)
+	if err != nil {
+		return "", fmt.Errorf("opening %s: %w", spec, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("opening %s: not a directory", spec)
+	}
+	remote = store
+	os.Setenv("PROJECT_ROOT", root)
+	logging.Logger.Debug("remote project", "on", store.Name, "root", root)
+	return root, nil
+}
+
+// projectStore returns the store the project's files are read and written through:
+// the local file system, with the project directory on the other side for --remote and
+// --container
+func projectStore(root string) fsys.Store {
+	if remote == nil {
+		return fsys.OS
+	}
+	return fsys.NewMount(fsys.OS, root, remote)
+}
+
+// rootCommand returns a command that runs a shell command line in the project root with
+// extra added to its environment. For a remote project it runs on the other side, with
+// a terminal if terminal is set.
+func rootCommand(root, command string, extra []string, terminal bool) *exec.Cmd {
+	if remote == nil {
+		cmd := shellCommand(command)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), extra...)
+		return cmd
+	}
+	script := "cd " + shellQuote(root) + " && exec env"
+	for _, v := range extra {
+		script += " " + shellQuote(v)
+	}
+	script += " sh -c " + shellQuote(command)
+	return remote.Command(script, terminal)
+}
+
+// editProjectFile opens a project file in the editor. A remote file is edited in a
+// local copy, which is written back when it changed.
+func (app *App) editProjectFile(filePath string) error {
+	if remote == nil {
+		return app.runEditor(filePath)
+	}
+	data, err := app.FS.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "lazyhydra-*-"+filepath.Base(filePath))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := app.runEditor(tmp.Name()); err != nil {
+		return err
+	}
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil || bytes.Equal(edited, data) {
+		return err
+	}
+	if err := fsys.WriteFileAtomic(app.FS, filePath, edited, 0644); err != nil {
+		return fmt.Errorf("writing %s back to %s: %w", filePath, remote.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// replaceMatch is an override.yaml that a search and replace changes
+type replaceMatch struct {
+	override *override.Override
+	count    int    // number of matches in the file
+	updated  string // the file's content after the replacement
+	skip     bool   // excluded from the replacement with space
+}
+
+// compileSearch compiles a search query, quoting it unless it is a regular expression.
+// ^ and $ match at every line, since override.yaml is edited line by line.
+func compileSearch(query string, isRegex bool) (*regexp.Regexp, error) {
+	if !isRegex {
+		return regexp.Compile(regexp.QuoteMeta(query))
+	}
+	return regexp.Compile("(?m)" + query)
+}
+
+// replaceMatches returns the override.yaml files that contain re with their content after
+// replacing it. A regular expression replacement may refer to groups as $1 or ${name}.
+func (app *App) replaceMatches(re *regexp.Regexp, replacement string, isRegex bool) []replaceMatch {
+	var matches []replaceMatch
+	for _, o := range app.Overrides {
+		found := re.FindAllStringIndex(o.Content, -1)
+		if len(found) == 0 {
+			continue
+		}
+		updated := re.ReplaceAllLiteralString(o.Content, replacement)
+		if isRegex {
+			updated = re.ReplaceAllString(o.Content, replacement)
+		}
+		matches = append(matches, replaceMatch{override: o, count: len(found), updated: updated})
+	}
+	return matches
+}
+
+// showReplace opens a search and replace across the override.yaml files of all
+// overrides. Each file's change is previewed as a diff and can be left out with space
+// before enter replaces in the rest.
+func (app *App) showReplace() {
+	if !app.writesAllowed("Replacing") {
+		return
+	}
+	app.replaceOpen = true
+	app.replaceResults = nil
+
+	search := tview.NewInputField().
+		SetLabel("Search:  ").
+		SetFieldBackgroundColor(tcell.ColorDefault)
+	replace := tview.NewInputField().
+		SetLabel("Replace: ").
+		SetFieldBackgroundColor(tcell.ColorDefault)
+	regex := tview.NewCheckbox().
+		SetLabel("Regex:   ")
+
+	fileList := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+	fileList.SetBorder(true).
+		SetTitle(" Files ").
+		SetTitleAlign(tview.AlignLeft)
+
+	preview := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	preview.SetBorder(true).
+		SetTitle(" Preview ").
+		SetTitleAlign(tview.AlignLeft)
+
+	app.replaceList = fileList
+	app.replacePreview = preview
+	app.replaceFocus = []tview.Primitive{search, replace, regex, fileList}
+
+	update := func() {
+		app.replaceResults = nil
+		preview.Clear()
+		if query := search.GetText(); query != "" {
+			re, err := compileSearch(query, regex.IsChecked())
+			if err != nil {
+				preview.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+			} else {
+				app.replaceResults = app.replaceMatches(re, replace.GetText(), regex.IsChecked())
+			}
+		}
+		app.refreshReplaceList()
+	}
+	search.SetChangedFunc(func(string) { update() })
+	replace.SetChangedFunc(func(string) { update() })
+	regex.SetChangedFunc(func(bool) { update() })
+
+	fileList.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		app.showReplacePreview(index)
+	})
+	fileList.SetSelectedFunc(func(int, string, string, rune) {
+		app.applyReplace()
+	})
+
+	fields := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(search, 1, 0, true).
+		AddItem(replace, 1, 0, false).
+		AddItem(regex, 1, 0, false)
+	results := tview.NewFlex().
+		AddItem(fileList, 32, 0, false).
+		AddItem(preview, 0, 1, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(fields, 3, 0, true).
+		AddItem(results, 0, 1, false)
+	layout.SetBorder(true).
+		SetTitle(" Replace in override.yaml ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("replace", modal(layout, 110, 30), true, true)
+	app.app.SetFocus(search)
+}
+
+// refreshReplaceList lists the files the current search changes, keeping the cursor
+func (app *App) refreshReplaceList() {
+	current := app.replaceList.GetCurrentItem()
+	app.replaceList.Clear()
+	total, included := 0, 0
+	for _, m := range app.replaceResults {
+		box := "[green]" + tview.Escape("[x]") + "[-]"
+		if m.skip {
+			box = "[darkgray]" + tview.Escape("[ ]") + "[-]"
+		} else {
+			total += m.count
+			included++
+		}
+		app.replaceList.AddItem(fmt.Sprintf("%s %s [darkgray](%d)[-]", box, tview.Escape(m.override.Name), m.count), "", 0, nil)
+	}
+	app.replaceList.SetTitle(fmt.Sprintf(" Files (%d of %d, %d matches) ", included, len(app.replaceResults), total))
+	if current < len(app.replaceResults) {
+		app.replaceList.SetCurrentItem(current)
+	}
+	app.showReplacePreview(app.replaceList.GetCurrentItem())
+}
+
+// showReplacePreview shows the diff the replacement makes in the file at index
+func (app *App) showReplacePreview(index int) {
+	if index < 0 || index >= len(app.replaceResults) {
+		return
+	}
+	m := app.replaceResults[index]
+	app.replacePreview.SetTitle(fmt.Sprintf(" %s/override.yaml ", m.override.Name))
+	app.replacePreview.SetText(formatDiffColored(lineDiff(m.override.Content, m.updated)))
+	app.replacePreview.ScrollToBeginning()
+}
+
+// toggleReplaceFile leaves the file under the cursor out of the replacement, or takes it
+// back in
+func (app *App) toggleReplaceFile() {
+	idx := app.replaceList.GetCurrentItem()
+	if idx < 0 || idx >= len(app.replaceResults) {
+		return
+	}
+	app.replaceResults[idx].skip = !app.replaceResults[idx].skip
+	app.refreshReplaceList()
+}
+
+// cycleReplaceFocus moves the focus to the next or previous field of the replace modal
+func (app *App) cycleReplaceFocus(delta int) {
+	for i, p := range app.replaceFocus {
+		if p.HasFocus() {
+			n := len(app.replaceFocus)
+			app.app.SetFocus(app.replaceFocus[(i+delta+n)%n])
+			return
+		}
+	}
+}
+
+func (app *App) closeReplace() {
+	app.replaceOpen = false
+	app.replaceResults = nil
+	app.replaceList = nil
+	app.replacePreview = nil
+	app.replaceFocus = nil
+	app.pages.RemovePage("replace")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// applyReplace writes the replacement to every file that was not left out. The previous
+// contents are kept in the overrides' history.
+func (app *App) applyReplace() {
+	matches := app.replaceResults
+	app.closeReplace()
+
+	files, total, reapply := 0, 0, false
+	for _, m := range matches {
+		if m.skip {
+			continue
+		}
+		if err := app.writeOverrideFile(m.override, "override.yaml", []byte(m.updated)); err != nil {
+			app.showError(fmt.Errorf("replacing in %s/override.yaml: %w", m.override.Name, err))
+			continue
+		}
+		app.reloadOverride(m.override.Name)
+		files++
+		total += m.count
+		reapply = reapply || app.Applied[m.override.Name]
+	}
+	if files == 0 {
+		return
+	}
+
+	// Value overrides embed their values in the override string
+	saved := true
+	if reapply {
+		saved = app.persistState()
+	}
+	app.refreshAll()
+	if saved {
+		app.showMessage("Replaced %d matches in %d overrides (V to revert one)", total, files)
+	}
+}
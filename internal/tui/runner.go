@@ -0,0 +1,221 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/rivo/tview"
+)
+
+// spinnerFrames animate the runner panel title while a job is running
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// maxJobs is how many jobs the jobs panel keeps; the oldest finished ones are dropped
+const maxJobs = 20
+
+// job is a project command started from the TUI
+type job struct {
+	name     string // run target, or run_command
+	command  string
+	env      []string // override variables it was started with, so a rerun gets the same overrides
+	cmd      *exec.Cmd
+	output   *tview.TextView
+	started  time.Time
+	finished time.Time
+	done     bool
+	killed   bool // stopped from the TUI
+	err      error
+}
+
+// runProjectCommand opens the run menu when run_targets are configured and otherwise
+// runs run_command
+func (app *App) runProjectCommand() {
+	if len(app.Config.RunTargets) > 0 {
+		app.showRunTargets()
+		return
+	}
+	if app.Config.RunCommand == "" {
+		app.showError(fmt.Errorf("no run_command or run_targets configured"))
+		return
+	}
+	app.startJob("run_command", app.Config.RunCommand)
+}
+
+// startJob runs a command in the background with the applied overrides in its
+// environment, streaming its output into the runner panel
+func (app *App) startJob(name, command string) {
+	app.launchJob(name, command, app.overrideEnv())
+}
+
+// launchJob runs a command in the background with env added to its environment. Jobs run
+// side by side; the runner panel shows the one started last.
+func (app *App) launchJob(name, command string, env []string) {
+	output := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true)
+	output.SetChangedFunc(func() {
+		app.app.Draw()
+	})
+
+	cmd := rootCommand(app.Root, command, env, false)
+	writer := tview.ANSIWriter(output)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	setProcessGroup(cmd)
+
+	j := &job{
+		name:    name,
+		command: command,
+		env:     env,
+		cmd:     cmd,
+		output:  output,
+		started: time.Now(),
+	}
+	app.addJob(j)
+	app.currentJob = j
+
+	fmt.Fprintf(output, "[darkgray]$ %s[-]\n\n", tview.Escape(j.command))
+	logging.Logger.Debug("starting job", "name", j.name, "command", j.command)
+	if err := cmd.Start(); err != nil {
+		commands.record(cmd, j.started, err)
+		j.done = true
+		j.err = err
+		j.finished = time.Now()
+		fmt.Fprintf(output, "[red]%s[-]\n", tview.Escape(err.Error()))
+		app.showRunner()
+		return
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		err := cmd.Wait()
+		commands.record(cmd, j.started, err)
+		close(exited)
+		app.app.QueueUpdateDraw(func() {
+			j.done = true
+			j.err = err
+			j.finished = time.Now()
+			logging.Logger.Debug("job finished", "name", j.name, "command", j.command, "error", err)
+			switch {
+			case j.killed:
+				fmt.Fprintf(output, "\n[yellow]stopped[-]\n")
+			case err != nil:
+				fmt.Fprintf(output, "\n[red]%s[-]\n", tview.Escape(err.Error()))
+			default:
+				fmt.Fprintf(output, "\n[green]done in %s[-]\n", j.finished.Sub(j.started).Round(time.Millisecond))
+			}
+			app.updateRunnerTitle()
+			app.refreshJobs()
+			if app.currentJob != j || !app.runnerOpen {
+				app.reportJob(j)
+			}
+		})
+	}()
+
+	// Animate the spinner until the job finishes
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-exited:
+				return
+			case <-ticker.C:
+				app.app.QueueUpdateDraw(func() {
+					app.updateRunnerTitle()
+					app.refreshJobs()
+				})
+			}
+		}
+	}()
+
+	app.showRunner()
+}
+
+func (app *App) showRunner() {
+	if app.currentJob == nil {
+		return
+	}
+
+	app.runnerOpen = true
+	app.currentJob.output.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorGreen)
+	app.updateRunnerTitle()
+
+	app.pages.AddPage("runner", modal(app.currentJob.output, 120, 30), true, true)
+	app.app.SetFocus(app.currentJob.output)
+}
+
+func (app *App) closeRunner() {
+	app.runnerOpen = false
+	app.pages.RemovePage("runner")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// updateRunnerTitle shows the job's state, animating the spinner while it runs
+func (app *App) updateRunnerTitle() {
+	j := app.currentJob
+	if j == nil {
+		return
+	}
+	j.output.SetTitle(fmt.Sprintf(" %s %s: %s ", j.status(), j.name, j.state()))
+}
+
+// status returns the job's state as a symbol, animating the spinner while it runs
+func (j *job) status() string {
+	switch {
+	case !j.done:
+		return string(spinnerFrames[int(time.Since(j.started)/(100*time.Millisecond))%len(spinnerFrames)])
+	case j.killed:
+		return "■"
+	case j.err != nil:
+		return "✗"
+	}
+	return "✓"
+}
+
+// state describes the job's state in words
+func (j *job) state() string {
+	if !j.done {
+		return fmt.Sprintf("%s (%s)", j.label(), j.runtime())
+	}
+	return j.label()
+}
+
+// label names the job's state in one word
+func (j *job) label() string {
+	switch {
+	case !j.done:
+		return "Running"
+	case j.killed:
+		return "Stopped"
+	case j.err != nil:
+		return "Failed"
+	}
+	return "Finished"
+}
+
+// runtime returns how long the job ran, or has been running
+func (j *job) runtime() time.Duration {
+	if j.done {
+		return j.finished.Sub(j.started).Round(time.Second)
+	}
+	return time.Since(j.started).Round(time.Second)
+}
+
+// killJob stops a running job and everything it started
+func (app *App) killJob(j *job) {
+	if j == nil || j.done || j.cmd.Process == nil {
+		return
+	}
+	j.killed = true
+	if err := killProcessGroup(j.cmd); err != nil {
+		app.showError(err)
+	}
+}
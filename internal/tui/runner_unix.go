@@ -0,0 +1,48 @@
+//go:build !windows
+
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// fallbackEditors are tried in order when neither $EDITOR nor $VISUAL is set
+var fallbackEditors = []string{"vim", "vi", "nano", "emacs"}
+
+// openCommand returns a command that opens path in the desktop's file manager or the
+// application registered for it
+func openCommand(path string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("open", path)
+	}
+	return exec.Command("xdg-open", path)
+}
+
+// shellCommand returns a command that runs line through the user's shell
+func shellCommand(line string) *exec.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	return exec.Command(shell, "-c", line)
+}
+
+// setProcessGroup starts the command in its own process group so it can be killed
+// together with any children it spawns
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup interrupts the command's whole process group
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+}
+
+// processAlive reports whether a process with pid is running
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
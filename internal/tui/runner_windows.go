@@ -0,0 +1,54 @@
+//go:build windows
+
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// fallbackEditors are tried in order when neither $EDITOR nor $VISUAL is set. VS Code
+// needs --wait to block until the file is closed.
+var fallbackEditors = []string{"code --wait", "notepad"}
+
+// openCommand returns a command that opens path in Explorer or the application
+// registered for it
+func openCommand(path string) *exec.Cmd {
+	return exec.Command("cmd", "/c", "start", "", path)
+}
+
+// shellCommand returns a command that runs line through the user's shell: $SHELL when
+// set (e.g. Git Bash), cmd.exe otherwise
+func shellCommand(line string) *exec.Cmd {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return exec.Command(shell, "-c", line)
+	}
+	comspec := os.Getenv("ComSpec")
+	if comspec == "" {
+		comspec = "cmd.exe"
+	}
+	// cmd.exe does not parse arguments the way Go quotes them, so pass the line as is
+	cmd := exec.Command(comspec)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `"` + comspec + `" /S /C "` + line + `"`}
+	return cmd
+}
+
+// setProcessGroup is a no-op on Windows
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the command's process
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// processAlive reports whether a process with pid is running. FindProcess opens the
+// process on Windows, so it fails once the process is gone.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/rivo/tview"
+)
+
+// showRunTargets opens the run menu: the run_targets from the config, each started
+// with Enter or its number key
+func (app *App) showRunTargets() {
+	app.runTargetsOpen = true
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+	for i := range app.Config.RunTargets {
+		t := &app.Config.RunTargets[i]
+		where := "panel"
+		if t.Terminal {
+			where = "terminal"
+		}
+		var shortcut rune
+		if i < 9 {
+			shortcut = rune('1' + i)
+		}
+		list.AddItem(fmt.Sprintf("%-12s [darkgray]%-8s %s[-]", tview.Escape(t.Name), where, tview.Escape(t.Command)),
+			"", shortcut, func() {
+				app.closeRunTargets()
+				app.runTarget(t, t.Terminal)
+			})
+	}
+	app.runTargetsList = list
+
+	list.SetBorder(true).
+		SetTitle(" Run ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := min(len(app.Config.RunTargets)+2, 22)
+	app.pages.AddPage("runtargets", modal(list, 90, height), true, true)
+	app.app.SetFocus(list)
+}
+
+func (app *App) closeRunTargets() {
+	app.runTargetsOpen = false
+	app.pages.RemovePage("runtargets")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// runSelectedTarget runs the target under the cursor of the run menu with the whole
+// terminal, whatever its terminal setting
+func (app *App) runSelectedTarget() {
+	t := &app.Config.RunTargets[app.runTargetsList.GetCurrentItem()]
+	app.closeRunTargets()
+	app.runTarget(t, true)
+}
+
+// runTarget renders a run target's command for the applied overrides and runs it in
+// the output panel, or with the whole terminal
+func (app *App) runTarget(t *config.RunTarget, terminal bool) {
+	command, err := t.Render(app.commandData())
+	if err != nil {
+		app.showError(fmt.Errorf("rendering the command of %s: %w", t.Name, err))
+		return
+	}
+	if !terminal {
+		app.startJob(t.Name, command)
+		return
+	}
+
+	var runErr error
+	app.app.Suspend(func() {
+		cmd := rootCommand(app.Root, command, app.overrideEnv(), true)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		// Ctrl+C is meant for the command, which gets it from the terminal itself
+		signal.Ignore(os.Interrupt)
+		defer signal.Reset(os.Interrupt)
+
+		fmt.Printf("$ %s\n\n", command)
+		logging.Logger.Debug("running target", "target", t.Name, "command", command)
+		runErr = runLogged(cmd)
+		if runErr != nil {
+			fmt.Printf("\n%s: %v\n", t.Name, runErr)
+		}
+		fmt.Print("\nPress Enter to return to lazyhydra")
+		bufio.NewReader(os.Stdin).ReadString('\n')
+	})
+	if runErr != nil {
+		app.showError(fmt.Errorf("running %s: %w", t.Name, runErr))
+		return
+	}
+	app.showMessage("%s finished", t.Name)
+}
@@ -0,0 +1,194 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// maxSearchResults caps the number of matching lines listed by the search modal
+const maxSearchResults = 200
+
+// searchResult is one matching line in an override's files
+type searchResult struct {
+	override *override.Override
+	file     string
+	line     int
+	text     string
+}
+
+// searchOverrides returns the lines of override.yaml and apply.md, across all overrides,
+// that contain query (case-insensitive).
+func (app *App) searchOverrides(query string) []searchResult {
+	if query == "" {
+		return nil
+	}
+	needle := strings.ToLower(query)
+
+	var results []searchResult
+	for _, o := range app.Overrides {
+		for _, file := range []struct{ name, content string }{
+			{"override.yaml", o.Content},
+			{"apply.md", o.ApplyInfo},
+		} {
+			for i, line := range strings.Split(file.content, "\n") {
+				if !strings.Contains(strings.ToLower(line), needle) {
+					continue
+				}
+				results = append(results, searchResult{override: o, file: file.name, line: i + 1, text: line})
+				if len(results) >= maxSearchResults {
+					return results
+				}
+			}
+		}
+	}
+	return results
+}
+
+// highlightMatch escapes a result line for tview and highlights the query in it
+func highlightMatch(text, query string) string {
+	text = strings.TrimSpace(text)
+	re, err := regexp.Compile("(?i)" + regexp.QuoteMeta(query))
+	if err != nil || query == "" {
+		return tview.Escape(text)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		b.WriteString(tview.Escape(text[last:loc[0]]))
+		b.WriteString("[black:yellow]" + tview.Escape(text[loc[0]:loc[1]]) + "[-:-]")
+		last = loc[1]
+	}
+	b.WriteString(tview.Escape(text[last:]))
+	return b.String()
+}
+
+// showSearch opens a modal that searches the contents of every override as you type
+// and jumps to the chosen override.
+func (app *App) showSearch() {
+	app.searchOpen = true
+
+	var results []searchResult
+
+	resultList := tview.NewList().
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite).
+		SetSecondaryTextColor(tcell.ColorDefault)
+
+	input := tview.NewInputField().
+		SetLabel("Search: ").
+		SetFieldBackgroundColor(tcell.ColorDefault)
+
+	input.SetChangedFunc(func(query string) {
+		results = app.searchOverrides(query)
+		resultList.Clear()
+		for _, r := range results {
+			resultList.AddItem(
+				fmt.Sprintf("%s [darkgray]%s:%d[-]", r.override.Name, r.file, r.line),
+				"  "+highlightMatch(r.text, query), 0, nil)
+		}
+		title := " Results "
+		if query != "" {
+			title = fmt.Sprintf(" Results (%d) ", len(results))
+		}
+		resultList.SetTitle(title)
+	})
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter && len(results) > 0 {
+			app.app.SetFocus(resultList)
+		}
+	})
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyDown && len(results) > 0 {
+			app.app.SetFocus(resultList)
+			return nil
+		}
+		return event
+	})
+
+	resultList.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index < len(results) {
+			app.closeSearch()
+			app.jumpToOverride(results[index].override)
+		}
+	})
+	resultList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Rune() == 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case event.Rune() == 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case event.Rune() == '/' || (event.Key() == tcell.KeyUp && resultList.GetCurrentItem() == 0):
+			app.app.SetFocus(input)
+			return nil
+		}
+		return event
+	})
+	resultList.SetBorder(true).
+		SetTitle(" Results ").
+		SetTitleAlign(tview.AlignLeft)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(resultList, 0, 1, false)
+	layout.SetBorder(true).
+		SetTitle(" Search Override Contents ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("search", modal(layout, 90, 24), true, true)
+	app.app.SetFocus(input)
+}
+
+func (app *App) closeSearch() {
+	app.searchOpen = false
+	app.pages.RemovePage("search")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// jumpToOverride focuses the panel that lists the override and moves the cursor to it,
+// expanding any collapsed folders or composites it is in.
+func (app *App) jumpToOverride(target *override.Override) {
+	if app.Applied[target.Name] {
+		for _, o := range app.getAppliedOverrides() {
+			for _, m := range o.Members() {
+				if m == target {
+					delete(app.collapsedComposites, o.Name)
+				}
+			}
+		}
+		app.refreshAll()
+		for i, row := range app.appliedRows {
+			if row.override == target {
+				app.appliedList.SetCurrentItem(i)
+			}
+		}
+		app.focusPanel(1)
+		return
+	}
+
+	for dir := target.Dir; dir != ""; {
+		delete(app.collapsed, dir)
+		i := strings.LastIndex(dir, "/")
+		if i < 0 {
+			break
+		}
+		dir = dir[:i]
+	}
+	app.refreshAll()
+
+	for i, row := range app.availableRows {
+		if row.override == target {
+			app.availableList.SetCurrentItem(i)
+		}
+	}
+	app.focusPanel(0)
+}
@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// defaultSocketPath returns where `lazyhydra serve` listens unless --socket is given
+func (app *App) defaultSocketPath() string {
+	return filepath.Join(app.Root, ".lazyhydra", "lazyhydra.sock")
+}
+
+// server answers API requests against the project's persisted state
+type server struct {
+	app *App
+	env string // environment pinned with --env or by the git branch, "" to follow the env file
+	mu  sync.Mutex
+}
+
+// reload rereads the overrides and the applied state so every request sees changes made
+// by the TUI or other lazyhydra processes.
+func (s *server) reload() error {
+	s.app.Overrides = nil
+	if err := s.app.LoadOverrides(); err != nil {
+		return err
+	}
+	s.app.Applied = make(map[string]bool)
+	s.app.Env = s.env
+	return s.app.loadPersistedState()
+}
+
+// namesRequest is the body of the apply and remove endpoints
+type namesRequest struct {
+	Names []string `json:"names"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// handle wraps an endpoint with the method check, locking and the state reload
+func (s *server) handle(method string, fn func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeJSONError(w, http.StatusMethodNotAllowed, fmt.Errorf("use %s", method))
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err := s.reload(); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		fn(w, r)
+	}
+}
+
+// applyChange applies or removes the named overrides and saves the state. Like a batch,
+// it changes all of them or none. On failure it also returns the HTTP status that fits.
+func (s *server) applyChange(command string, names []string) (int, error) {
+	if len(names) == 0 {
+		return http.StatusBadRequest, fmt.Errorf("names is empty")
+	}
+
+	app := s.app
+	changed, err := app.changeOverrides(command, names)
+	if err != nil {
+		if changed {
+			app.Applied = copyApplied(app.savedApplied)
+			app.ReconcileSymlinks()
+		}
+		if errors.Is(err, errReadOnly) {
+			return http.StatusForbidden, err
+		}
+		return http.StatusBadRequest, err
+	}
+	if !changed {
+		return http.StatusOK, nil
+	}
+	app.saveUIState()
+	if err := app.savePersistedState(); err != nil && !errors.Is(err, errWritePending) {
+		if errors.Is(err, errEnvrcConflict) {
+			return http.StatusConflict, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// change is the endpoint that applies or removes the overrides named in the body
+func (s *server) change(command string) http.HandlerFunc {
+	return s.handle(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		var req namesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("decoding request: %w", err))
+			return
+		}
+		if status, err := s.applyChange(command, req.Names); err != nil {
+			writeJSONError(w, status, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.app.buildStatusJSON())
+	})
+}
+
+// handler routes the API endpoints
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/list", s.handle(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.app.buildListJSON())
+	}))
+	mux.HandleFunc("/status", s.handle(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.app.buildStatusJSON())
+	}))
+	mux.HandleFunc("/print", s.handle(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"override_string": strings.ReplaceAll(s.app.BuildString(), "\n", " "),
+		})
+	}))
+	mux.HandleFunc("/apply", s.change("apply"))
+	mux.HandleFunc("/remove", s.change("remove"))
+	return mux
+}
+
+// listenUnix listens on a unix socket, replacing a stale socket file left behind by a
+// server that did not shut down cleanly.
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a server is already listening on %s", path)
+		}
+		os.Remove(path)
+	}
+	return net.Listen("unix", path)
+}
+
+// runServe handles `lazyhydra serve`, answering HTTP requests on a unix socket until
+// interrupted. env pins the environment the server works on; "" follows the env file.
+func (app *App) runServe(args []string, env string) error {
+	path, ok := flagValue(args, "--socket")
+	if !ok {
+		path = app.defaultSocketPath()
+	}
+
+	ln, err := listenUnix(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	srv := &http.Server{Handler: (&server{app: app, env: env}).handler()}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		srv.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving on %s\n", path)
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
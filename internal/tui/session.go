@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// session is where the TUI was left in a project, restored when the project is opened
+// again. Unlike uiState it is kept for each project.
+type session struct {
+	Root         string   `yaml:"root"`                // the project, for whoever reads the file
+	Panel        int      `yaml:"panel"`               // focused panel, 0 Available or 1 Applied
+	Available    string   `yaml:"available,omitempty"` // row under the cursor: an override name, or a folder as dir/
+	Applied      string   `yaml:"applied,omitempty"`
+	AvailableTop int      `yaml:"available_top,omitempty"` // first row shown, so the lists scroll back too
+	AppliedTop   int      `yaml:"applied_top,omitempty"`
+	Sort         string   `yaml:"sort,omitempty"`
+	ShowArchived bool     `yaml:"show_archived,omitempty"`
+	Zoom         int      `yaml:"zoom,omitempty"`
+	Collapsed    []string `yaml:"collapsed,omitempty"` // collapsed group folders of Available
+}
+
+// sessionPath returns the session file of the project at root, named by a hash of the
+// project's location
+func sessionPath(root string) string {
+	location := root
+	if remote != nil {
+		location = remote.Name + ":" + root
+	}
+	sum := sha256.Sum256([]byte(location))
+	return filepath.Join(config.StateDir(), "sessions", hex.EncodeToString(sum[:8])+".yaml")
+}
+
+// availableRowKey identifies an Available row across sessions
+func availableRowKey(row availableRow) string {
+	if row.override == nil {
+		return row.dir + "/"
+	}
+	return row.override.Name
+}
+
+// appliedRowKey identifies an Applied row across sessions
+func appliedRowKey(row appliedRow) string {
+	if row.group != "" {
+		return "[" + row.group + "]"
+	}
+	if row.override == nil {
+		return row.missing
+	}
+	return row.override.Name
+}
+
+// saveSession remembers the focused panel, the rows under the cursors, the sort, the
+// archived filter, the zoom and the collapsed folders for the project
+func (app *App) saveSession() {
+	s := session{
+		Root:         app.Root,
+		Panel:        app.currentPanelIdx,
+		Sort:         app.ui.Sort,
+		ShowArchived: app.showArchived,
+		Zoom:         app.zoom,
+	}
+	if i := app.availableList.GetCurrentItem(); i >= 0 && i < len(app.availableRows) {
+		s.Available = availableRowKey(app.availableRows[i])
+		s.AvailableTop, _ = app.availableList.GetOffset()
+	}
+	if i := app.appliedList.GetCurrentItem(); i >= 0 && i < len(app.appliedRows) {
+		s.Applied = appliedRowKey(app.appliedRows[i])
+		s.AppliedTop, _ = app.appliedList.GetOffset()
+	}
+	for dir, collapsed := range app.collapsed {
+		if collapsed {
+			s.Collapsed = append(s.Collapsed, dir)
+		}
+	}
+	sort.Strings(s.Collapsed)
+
+	data, err := yaml.Marshal(s)
+	if err == nil {
+		path := sessionPath(app.Root)
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			err = os.WriteFile(path, data, 0644)
+		}
+	}
+	if err != nil {
+		logging.Logger.Warn("could not save session", "error", err)
+	}
+}
+
+// restoreSession puts the TUI back where it was left in the project. Rows that are gone
+// leave their cursor at the top.
+func (app *App) restoreSession() {
+	var s session
+	data, err := os.ReadFile(sessionPath(app.Root))
+	if err != nil {
+		return
+	}
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		logging.Logger.Warn("ignoring invalid session file", "path", sessionPath(app.Root), "error", err)
+		return
+	}
+
+	if s.Sort != "" {
+		app.ui.Sort = s.Sort
+	}
+	app.showArchived = s.ShowArchived
+	app.collapsed = make(map[string]bool)
+	for _, dir := range s.Collapsed {
+		app.collapsed[dir] = true
+	}
+	app.refreshAll()
+
+	for i, row := range app.availableRows {
+		if availableRowKey(row) == s.Available {
+			app.availableList.SetCurrentItem(i).SetOffset(s.AvailableTop, 0)
+			break
+		}
+	}
+	for i, row := range app.appliedRows {
+		if appliedRowKey(row) == s.Applied {
+			app.appliedList.SetCurrentItem(i).SetOffset(s.AppliedTop, 0)
+			break
+		}
+	}
+	if s.Panel >= 0 && s.Panel < len(app.panels) {
+		app.currentPanelIdx = s.Panel
+	}
+	if s.Zoom >= 0 && s.Zoom < zoomLevels {
+		app.zoom = s.Zoom
+	}
+	app.applyLayout()
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+	app.updateContentAndInfo()
+	logging.Logger.Debug("restored session", "panel", s.Panel, "available", s.Available,
+		"applied", s.Applied, "zoom", s.Zoom, "folders", strings.Join(s.Collapsed, ","))
+}
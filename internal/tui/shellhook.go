@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hookSubcommands are completed as the first argument of lazyhydra and lzh
+var hookSubcommands = []string{
+	"list", "status", "stats", "path", "copy", "run", "snapshot", "trash", "batch",
+	"serve", "ipc", "diffgen", "init", "doctor", "hook", "export",
+}
+
+// The shell snippets printed by `lazyhydra hook`. Each defines:
+//   - lzh, which launches lazyhydra and then loads the env file the session saved, so
+//     the prompt and the next command see the new overrides without a cd or direnv reload
+//   - lzh-run, which runs a command with the applied overrides injected
+//   - lzh-cd, which changes to the folder of an override, or the overrides directory
+//   - completion of subcommands, and of override names for path and lzh-cd
+//
+// @SUBCOMMANDS@ is replaced with hookSubcommands.
+const bashHook = `# lazyhydra shell integration (bash)
+lzh() {
+  command lazyhydra "$@"
+  local status=$?
+  if command -v direnv >/dev/null 2>&1; then
+    eval "$(direnv export bash 2>/dev/null)"
+  fi
+  return $status
+}
+
+lzh-run() {
+  command lazyhydra run -- "$@"
+}
+
+lzh-cd() {
+  local dir
+  dir="$(command lazyhydra path "$@")" && cd "$dir"
+}
+
+_lazyhydra_complete() {
+  local cur="${COMP_WORDS[COMP_CWORD]}"
+  if [ "$COMP_CWORD" -eq 1 ] && [ "${COMP_WORDS[0]}" != "lzh-cd" ]; then
+    COMPREPLY=($(compgen -W "@SUBCOMMANDS@" -- "$cur"))
+  elif [ "${COMP_WORDS[0]}" = "lzh-cd" ] || [ "${COMP_WORDS[1]}" = "path" ]; then
+    local IFS=$'\n'
+    COMPREPLY=($(compgen -W "$(command lazyhydra list --names 2>/dev/null)" -- "$cur"))
+  fi
+}
+complete -F _lazyhydra_complete lazyhydra lzh lzh-cd
+`
+
+const zshHook = `# lazyhydra shell integration (zsh)
+lzh() {
+  command lazyhydra "$@"
+  local exit_status=$?
+  if (( $+commands[direnv] )); then
+    eval "$(direnv export zsh 2>/dev/null)"
+  fi
+  return $exit_status
+}
+
+lzh-run() {
+  command lazyhydra run -- "$@"
+}
+
+lzh-cd() {
+  local dir
+  dir="$(command lazyhydra path "$@")" && cd "$dir"
+}
+
+_lazyhydra_complete() {
+  if [[ $service == lzh-cd ]] || [[ $CURRENT -gt 2 && ${words[2]} == path ]]; then
+    compadd -- ${(f)"$(command lazyhydra list --names 2>/dev/null)"}
+  elif (( CURRENT == 2 )); then
+    compadd -- @SUBCOMMANDS@
+  fi
+}
+if (( $+functions[compdef] )); then
+  compdef _lazyhydra_complete lazyhydra lzh lzh-cd
+fi
+`
+
+const fishHook = `# lazyhydra shell integration (fish)
+function lzh
+  command lazyhydra $argv
+  set -l exit_status $status
+  if command -q direnv
+    direnv export fish 2>/dev/null | source
+  end
+  return $exit_status
+end
+
+function lzh-run
+  command lazyhydra run -- $argv
+end
+
+function lzh-cd
+  set -l dir (command lazyhydra path $argv); and cd $dir
+end
+
+complete -c lazyhydra -c lzh -f
+complete -c lazyhydra -c lzh -n __fish_use_subcommand -a '@SUBCOMMANDS@'
+complete -c lazyhydra -c lzh -n '__fish_seen_subcommand_from path' -a '(command lazyhydra list --names 2>/dev/null)'
+complete -c lzh-cd -f -a '(command lazyhydra list --names 2>/dev/null)'
+`
+
+// runHook prints the shell integration snippet for `lazyhydra hook SHELL`, to be
+// evaluated from the shell's startup file
+func runHook(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: lazyhydra hook bash|zsh|fish")
+	}
+	var snippet string
+	switch args[0] {
+	case "bash":
+		snippet = bashHook
+	case "zsh":
+		snippet = zshHook
+	case "fish":
+		snippet = fishHook
+	default:
+		return fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", args[0])
+	}
+	fmt.Print(strings.ReplaceAll(snippet, "@SUBCOMMANDS@", strings.Join(hookSubcommands, " ")))
+	return nil
+}
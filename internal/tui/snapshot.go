@@ -0,0 +1,468 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// snapshotFiles are the files of an override folder captured in a snapshot
+var snapshotFiles = []string{"apply.md", "override.yaml"}
+
+// snapshotMeta is the snapshot.yaml of a saved snapshot
+type snapshotMeta struct {
+	Created     time.Time          `yaml:"created"`
+	Environment string             `yaml:"environment"`
+	Overrides   []snapshotOverride `yaml:"overrides"`
+	GitCommit   string             `yaml:"git_commit,omitempty"` // the project's commit when saved, with record_git_commit
+	GitDirty    bool               `yaml:"git_dirty,omitempty"`  // whether tracked files had uncommitted changes
+}
+
+// snapshotOverride is an applied override recorded in a snapshot
+type snapshotOverride struct {
+	Name   string            `yaml:"name"`
+	Folder string            `yaml:"folder"` // relative to the overrides dir, e.g. "logging/wandb_off"
+	Params map[string]string `yaml:"params,omitempty"`
+}
+
+// snapshotsDir returns where the project's snapshots are kept
+func (app *App) snapshotsDir() string {
+	return filepath.Join(app.Root, ".lazyhydra", "snapshots")
+}
+
+// validateSnapshotName reports why a name cannot be used for a snapshot
+func validateSnapshotName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return nil
+}
+
+// saveSnapshot records the applied overrides together with a copy of their apply.md,
+// override.yaml and parameter values, so the configuration can be reproduced later.
+func (app *App) saveSnapshot(name string) error {
+	if err := validateSnapshotName(name); err != nil {
+		return err
+	}
+	dir := filepath.Join(app.snapshotsDir(), name)
+	if _, err := app.FS.Stat(dir); err == nil {
+		return fmt.Errorf("snapshot %s already exists", name)
+	}
+
+	overridesDir := app.Config.ExpandPath(app.Config.OverridesDir)
+	meta := snapshotMeta{Created: time.Now(), Environment: app.Env}
+	if app.Config.RecordGitCommit {
+		meta.GitCommit, meta.GitDirty, _ = gitCommit(app.Root)
+	}
+	for _, o := range app.Overrides {
+		if !app.Applied[o.Name] {
+			continue
+		}
+		rel, err := filepath.Rel(overridesDir, o.FolderPath)
+		if err != nil {
+			return err
+		}
+		entry := snapshotOverride{Name: o.Name, Folder: filepath.ToSlash(rel)}
+		if o.HasParams() {
+			entry.Params = make(map[string]string)
+			for _, param := range o.ParamNames() {
+				entry.Params[param] = o.ParamValue(param)
+			}
+		}
+		meta.Overrides = append(meta.Overrides, entry)
+
+		for _, file := range snapshotFiles {
+			data, err := app.FS.ReadFile(filepath.Join(o.FolderPath, file))
+			if err != nil {
+				continue
+			}
+			target := filepath.Join(dir, "files", rel, file)
+			if err := app.FS.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("creating snapshot: %w", err)
+			}
+			if err := app.FS.WriteFile(target, data, 0644); err != nil {
+				return fmt.Errorf("writing snapshot: %w", err)
+			}
+		}
+	}
+
+	data, err := yaml.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := app.FS.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating snapshot: %w", err)
+	}
+	logging.Logger.Debug("saved snapshot", "name", name, "applied", len(meta.Overrides))
+	return app.FS.WriteFile(filepath.Join(dir, "snapshot.yaml"), data, 0644)
+}
+
+// loadSnapshot reads a snapshot's snapshot.yaml
+func (app *App) loadSnapshot(name string) (snapshotMeta, error) {
+	var meta snapshotMeta
+	if err := validateSnapshotName(name); err != nil {
+		return meta, err
+	}
+	data, err := app.FS.ReadFile(filepath.Join(app.snapshotsDir(), name, "snapshot.yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return meta, fmt.Errorf("snapshot %s does not exist", name)
+		}
+		return meta, err
+	}
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("reading snapshot %s: %w", name, err)
+	}
+	return meta, nil
+}
+
+// snapshotCommitWarning explains how the project's git commit differs from the one a
+// snapshot was saved at, or returns "" when it matches or none was recorded. A snapshot
+// saved with uncommitted changes is reported too, since they cannot be compared.
+func (app *App) snapshotCommitWarning(meta snapshotMeta) string {
+	if meta.GitCommit == "" {
+		return ""
+	}
+	saved := describeCommit(meta.GitCommit, meta.GitDirty)
+	commit, dirty, ok := gitCommit(app.Root)
+	switch {
+	case !ok:
+		return fmt.Sprintf("the snapshot was taken at commit %s, but the project is no longer a git repository", saved)
+	case commit != meta.GitCommit:
+		return fmt.Sprintf("the snapshot was taken at commit %s, but the project is at %s", saved, describeCommit(commit, dirty))
+	case meta.GitDirty:
+		return fmt.Sprintf("the snapshot was taken at commit %s with uncommitted changes, which may differ from yours", describeCommit(meta.GitCommit, false))
+	}
+	return ""
+}
+
+// snapshotChanges returns the override files (relative to the overrides dir) whose
+// current content differs from the snapshot, i.e. what restoring would overwrite.
+func (app *App) snapshotChanges(name string, meta snapshotMeta) []string {
+	overridesDir := app.Config.ExpandPath(app.Config.OverridesDir)
+	var changed []string
+	for _, entry := range meta.Overrides {
+		for _, file := range snapshotFiles {
+			saved, err := app.FS.ReadFile(filepath.Join(app.snapshotsDir(), name, "files", filepath.FromSlash(entry.Folder), file))
+			if err != nil {
+				continue
+			}
+			current, err := app.FS.ReadFile(filepath.Join(overridesDir, filepath.FromSlash(entry.Folder), file))
+			if err != nil || !bytes.Equal(saved, current) {
+				changed = append(changed, entry.Folder+"/"+file)
+			}
+		}
+	}
+	return changed
+}
+
+// restoreSnapshot writes the snapshot's override files back, reloads the overrides and
+// replaces the applied set with the snapshot's, including parameter values. The caller
+// saves the state. It returns the files that were overwritten.
+func (app *App) restoreSnapshot(name string) ([]string, error) {
+	meta, err := app.loadSnapshot(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unlink with the current metadata, since restoring may move an override's block
+	for _, o := range app.getAppliedOverrides() {
+		app.Unlink(o)
+	}
+
+	overridesDir := app.Config.ExpandPath(app.Config.OverridesDir)
+	changed := app.snapshotChanges(name, meta)
+	for _, rel := range changed {
+		data, err := app.FS.ReadFile(filepath.Join(app.snapshotsDir(), name, "files", filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		target := filepath.Join(overridesDir, filepath.FromSlash(rel))
+		if err := app.FS.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("restoring %s: %w", rel, err)
+		}
+		if err := app.FS.WriteFile(target, data, 0644); err != nil {
+			return nil, fmt.Errorf("restoring %s: %w", rel, err)
+		}
+	}
+
+	app.Overrides = nil
+	if err := app.LoadOverrides(); err != nil {
+		return changed, err
+	}
+
+	app.Applied = make(map[string]bool)
+	for _, entry := range meta.Overrides {
+		for _, o := range app.Overrides {
+			if o.Name == entry.Name {
+				app.Applied[o.Name] = true
+				if entry.Params != nil {
+					o.ParamValues = entry.Params
+				}
+			}
+		}
+	}
+	app.marked = make(map[string]bool)
+	app.ReconcileSymlinks()
+
+	logging.Logger.Debug("restored snapshot", "name", name, "changed", changed)
+	return changed, nil
+}
+
+// snapshotInfo summarizes a saved snapshot for listings
+type snapshotInfo struct {
+	Name    string
+	Created time.Time
+	Count   int
+	Commit  string // described with describeCommit, "" when not recorded
+}
+
+// listSnapshots returns the project's snapshots, newest first
+func (app *App) listSnapshots() []snapshotInfo {
+	entries, err := app.FS.ReadDir(app.snapshotsDir())
+	if err != nil {
+		return nil
+	}
+
+	var snapshots []snapshotInfo
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		meta, err := app.loadSnapshot(entry.Name())
+		if err != nil {
+			continue
+		}
+		info := snapshotInfo{Name: entry.Name(), Created: meta.Created, Count: len(meta.Overrides)}
+		if meta.GitCommit != "" {
+			info.Commit = describeCommit(meta.GitCommit, meta.GitDirty)
+		}
+		snapshots = append(snapshots, info)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.After(snapshots[j].Created)
+	})
+	return snapshots
+}
+
+// warnSnapshotCommit prints to stderr when a snapshot about to be restored was taken at
+// another commit
+func (app *App) warnSnapshotCommit(name string) {
+	meta, err := app.loadSnapshot(name)
+	if err != nil {
+		return
+	}
+	if warning := app.snapshotCommitWarning(meta); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
+// runSnapshotCommand implements `lazyhydra snapshot save|restore|list`
+func (app *App) runSnapshotCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: lazyhydra snapshot save|restore <name> | list")
+	}
+
+	switch args[0] {
+	case "list":
+		for _, s := range app.listSnapshots() {
+			fmt.Printf("%-24s %s  %3d overrides  %s\n", s.Name, s.Created.Format("2006-01-02 15:04"), s.Count, s.Commit)
+		}
+		return nil
+	case "save", "restore":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: lazyhydra snapshot %s <name>", args[0])
+		}
+	default:
+		return fmt.Errorf("unknown snapshot command %q", args[0])
+	}
+
+	name := args[1]
+	if args[0] == "save" {
+		if app.DryRun {
+			return fmt.Errorf("saving a snapshot writes files and is not available in dry-run mode")
+		}
+		if err := app.saveSnapshot(name); err != nil {
+			return err
+		}
+		fmt.Printf("Saved snapshot %s with %d applied overrides\n", name, len(app.getAppliedOverrides()))
+		return nil
+	}
+
+	if app.DryRun || app.ReadOnly {
+		return fmt.Errorf("restoring a snapshot writes override files and is not available in dry-run or read-only mode")
+	}
+	app.warnSnapshotCommit(name)
+	changed, err := app.restoreSnapshot(name)
+	if err != nil {
+		return err
+	}
+	for _, rel := range changed {
+		fmt.Printf("restored %s\n", rel)
+	}
+	if err := app.savePersistedState(); err != nil {
+		return err
+	}
+	fmt.Printf("Restored snapshot %s with %d applied overrides\n", name, len(app.getAppliedOverrides()))
+	return nil
+}
+
+// showSnapshots lists the saved snapshots to restore, plus an entry to save a new one
+func (app *App) showSnapshots() {
+	app.snapshotsOpen = true
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+
+	list.AddItem("[darkgray]+ save current state...[-]", "", 0, func() {
+		app.closeSnapshots()
+		app.showSaveSnapshotInput()
+	})
+	snapshots := app.listSnapshots()
+	for _, s := range snapshots {
+		name := s.Name
+		details := fmt.Sprintf("%s, %d overrides", s.Created.Format("2006-01-02 15:04"), s.Count)
+		if s.Commit != "" {
+			details += ", " + s.Commit
+		}
+		list.AddItem(fmt.Sprintf("%s [darkgray]%s[-]", tview.Escape(name), details), "", 0, func() {
+			app.closeSnapshots()
+			app.showRestoreSnapshotConfirmation(name)
+		})
+	}
+
+	list.SetBorder(true).
+		SetTitle(" Snapshots ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(snapshots) + 3
+	if height > 20 {
+		height = 20
+	}
+	app.pages.AddPage("snapshots", modal(list, 70, height), true, true)
+	app.app.SetFocus(list)
+}
+
+func (app *App) closeSnapshots() {
+	app.snapshotsOpen = false
+	app.pages.RemovePage("snapshots")
+	app.pages.RemovePage("snapshot-save")
+	app.pages.RemovePage("snapshot-restore")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// showSaveSnapshotInput asks for the name of a new snapshot of the current state
+func (app *App) showSaveSnapshotInput() {
+	if !app.writesAllowed("Saving snapshots") {
+		return
+	}
+	app.snapshotsOpen = true
+
+	inputField := tview.NewInputField().
+		SetLabel("Name: ").
+		SetText(time.Now().Format("2006-01-02_1504")).
+		SetFieldWidth(40).
+		SetFieldBackgroundColor(tcell.ColorDefault)
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		name := strings.TrimSpace(inputField.GetText())
+		app.closeSnapshots()
+		if key != tcell.KeyEnter || name == "" {
+			return
+		}
+		if err := app.saveSnapshot(name); err != nil {
+			app.showError(err)
+			return
+		}
+		app.showMessage("Saved snapshot %s", name)
+	})
+
+	inputField.SetBorder(true).
+		SetTitle(" Save Snapshot ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("snapshot-save", modal(inputField, 60, 3), true, true)
+	app.app.SetFocus(inputField)
+}
+
+// showRestoreSnapshotConfirmation lists what restoring a snapshot would change
+func (app *App) showRestoreSnapshotConfirmation(name string) {
+	if !app.writesAllowed("Restoring snapshots") {
+		return
+	}
+	meta, err := app.loadSnapshot(name)
+	if err != nil {
+		app.showError(err)
+		return
+	}
+
+	app.snapshotsOpen = true
+	app.restoreTarget = name
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow::b]Restore snapshot %s[-:-:-]\n\n", tview.Escape(name))
+	if warning := app.snapshotCommitWarning(meta); warning != "" {
+		fmt.Fprintf(&b, "[yellow]Warning: %s[-]\n\n", tview.Escape(warning))
+	}
+	fmt.Fprintf(&b, "Applies %d override(s), replacing the current applied set:\n", len(meta.Overrides))
+	for _, entry := range meta.Overrides {
+		fmt.Fprintf(&b, "  [green]+[-] %s\n", tview.Escape(entry.Folder))
+	}
+	if changed := app.snapshotChanges(name, meta); len(changed) > 0 {
+		b.WriteString("\nOverwrites these files with their snapshot content:\n")
+		for _, rel := range changed {
+			fmt.Fprintf(&b, "  [red]~[-] %s\n", tview.Escape(rel))
+		}
+	}
+	b.WriteString("\n[green]Enter[-] to restore    [yellow]Esc/q[-] to cancel")
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(b.String())
+	view.SetBorder(true).
+		SetTitle(" Confirm Restore ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorYellow)
+
+	app.pages.AddPage("snapshot-restore", modal(view, 70, 20), true, true)
+	app.app.SetFocus(view)
+}
+
+// confirmRestoreSnapshot restores the snapshot chosen in the confirmation and saves the state
+func (app *App) confirmRestoreSnapshot() {
+	name := app.restoreTarget
+	app.restoreTarget = ""
+	app.closeSnapshots()
+
+	changed, err := app.restoreSnapshot(name)
+	if app.watcher != nil {
+		app.watchOverrideDirs()
+	}
+	if err != nil {
+		app.refreshAll()
+		app.showError(err)
+		return
+	}
+
+	saved := app.persistState()
+	app.refreshAll()
+	if saved {
+		app.showMessage("Restored snapshot %s (%d files overwritten)", name, len(changed))
+	}
+}
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ramy/lazyhydra/internal/override"
+)
+
+// sortModes are the orders the override lists can be shown in, cycled with s
+var sortModes = []string{"name", "applied", "modified", "priority"}
+
+// sortMode returns the active sort mode, falling back to name for unknown values
+func (app *App) sortMode() string {
+	for _, mode := range sortModes {
+		if mode == app.ui.Sort {
+			return mode
+		}
+	}
+	return "name"
+}
+
+// cycleSortMode switches to the next sort mode and remembers it for the next session
+func (app *App) cycleSortMode() {
+	current := app.sortMode()
+	for i, mode := range sortModes {
+		if mode == current {
+			app.ui.Sort = sortModes[(i+1)%len(sortModes)]
+			break
+		}
+	}
+	app.saveUIState()
+	app.refreshAll()
+	app.showMessage("Sorted by %s", app.ui.Sort)
+}
+
+// sortOverrides orders a list for display according to the active sort mode. Ties,
+// and overrides without the sorted attribute, fall back to name order.
+func (app *App) sortOverrides(list []*override.Override) {
+	var less func(a, b *override.Override) bool
+	switch app.sortMode() {
+	case "applied":
+		less = func(a, b *override.Override) bool {
+			return app.ui.LastApplied[a.FolderPath].After(app.ui.LastApplied[b.FolderPath])
+		}
+	case "modified":
+		less = func(a, b *override.Override) bool {
+			return a.Modified.After(b.Modified)
+		}
+	case "priority":
+		less = func(a, b *override.Override) bool {
+			return a.Priority > b.Priority
+		}
+	default:
+		return
+	}
+
+	// The input is already in name order, so a stable sort keeps ties alphabetical
+	sort.SliceStable(list, func(i, j int) bool {
+		return less(list[i], list[j])
+	})
+}
+
+// recordApplied remembers when an override was applied for the applied sort mode, and
+// counts how often it was for the usage statistics
+func (app *App) recordApplied(o *override.Override) {
+	app.ui.LastApplied[o.FolderPath] = time.Now()
+	app.ui.ApplyCount[o.FolderPath]++
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// overrideUsage is how often and how recently an override was applied
+type overrideUsage struct {
+	override *override.Override
+	count    int
+	last     time.Time // zero when it was never applied
+}
+
+// used reports whether the override was ever applied. Apply times were kept before
+// apply counts, so an override may have a time but no count.
+func (u overrideUsage) used() bool {
+	return u.count > 0 || !u.last.IsZero()
+}
+
+// usageStats returns the usage of every override, most applied first, then most
+// recently applied, with the overrides never applied last by name
+func (app *App) usageStats() []overrideUsage {
+	usage := make([]overrideUsage, 0, len(app.Overrides))
+	for _, o := range app.Overrides {
+		usage = append(usage, overrideUsage{
+			override: o,
+			count:    app.ui.ApplyCount[o.FolderPath],
+			last:     app.ui.LastApplied[o.FolderPath],
+		})
+	}
+	sort.SliceStable(usage, func(i, j int) bool {
+		a, b := usage[i], usage[j]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		if !a.last.Equal(b.last) {
+			return a.last.After(b.last)
+		}
+		return a.override.Name < b.override.Name
+	})
+	return usage
+}
+
+// usageJSON is the machine-readable form of an override's usage
+type usageJSON struct {
+	Name        string     `json:"name"`
+	Count       int        `json:"count"`
+	LastApplied *time.Time `json:"last_applied,omitempty"`
+}
+
+// printStats prints how often and when each override was applied, for `lazyhydra stats`
+func (app *App) printStats(asJSON bool) error {
+	usage := app.usageStats()
+	if asJSON {
+		out := []usageJSON{}
+		for _, u := range usage {
+			entry := usageJSON{Name: u.override.Name, Count: u.count}
+			if !u.last.IsZero() {
+				last := u.last
+				entry.LastApplied = &last
+			}
+			out = append(out, entry)
+		}
+		return printJSON(map[string][]usageJSON{"overrides": out})
+	}
+
+	var never []string
+	fmt.Println("Applied overrides, most used first:")
+	for _, u := range usage {
+		if !u.used() {
+			never = append(never, u.override.Name)
+			continue
+		}
+		fmt.Printf("  %5d  %s  %s\n", u.count, u.last.Format(detailTimeFormat), u.override.Name)
+	}
+	if len(never) > 0 {
+		fmt.Printf("\nNever applied (%d):\n", len(never))
+		for _, name := range never {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+	return nil
+}
+
+// formatStats renders the usage statistics for the stats view
+func (app *App) formatStats() string {
+	usage := app.usageStats()
+
+	var b strings.Builder
+	var never []string
+	b.WriteString("[yellow::b]Most used[-:-:-]\n\n")
+	fmt.Fprintf(&b, "[darkgray]%7s  %-16s  %s[-]\n", "applied", "last applied", "override")
+	for _, u := range usage {
+		if !u.used() {
+			never = append(never, u.override.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "%7d  %-16s  %s\n", u.count, u.last.Format(detailTimeFormat), tview.Escape(u.override.Name))
+	}
+	if len(never) < len(usage) {
+		b.WriteString("\n")
+	} else {
+		b.WriteString("[darkgray]No override has been applied yet[-]\n\n")
+	}
+
+	fmt.Fprintf(&b, "[yellow::b]Never applied (%d)[-:-:-]\n\n", len(never))
+	for _, name := range never {
+		fmt.Fprintf(&b, "  %s\n", tview.Escape(name))
+	}
+	return b.String()
+}
+
+// showStats shows how often and how recently each override was applied, to help find
+// the ones worth archiving or deleting
+func (app *App) showStats() {
+	app.statsOpen = true
+
+	statsText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(app.formatStats())
+
+	statsText.SetBorder(true).
+		SetTitle(" Usage Statistics ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	app.pages.AddPage("stats", modal(statsText, 80, 30), true, true)
+	app.app.SetFocus(statsText)
+}
+
+func (app *App) closeStats() {
+	app.statsOpen = false
+	app.pages.RemovePage("stats")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
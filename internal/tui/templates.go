@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+)
+
+// showTemplatePicker offers the available templates before creating a new override.
+// When no templates are installed, it goes straight to the new-override form.
+func (app *App) showTemplatePicker() {
+	if !app.writesAllowed("Creating overrides") {
+		return
+	}
+
+	templates := listTemplates(app.FS)
+	if len(templates) == 0 {
+		app.showNewOverrideForm("")
+		return
+	}
+
+	app.templateOpen = true
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+
+	list.AddItem("(blank)", "", 0, func() {
+		app.closeTemplatePicker()
+		app.showNewOverrideForm("")
+	})
+	for _, name := range templates {
+		templateName := name
+		list.AddItem(templateName, "", 0, func() {
+			app.closeTemplatePicker()
+			app.showNewOverrideForm(templateName)
+		})
+	}
+
+	list.SetBorder(true).
+		SetTitle(" Choose Template ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(templates) + 3
+	if height > 20 {
+		height = 20
+	}
+	app.pages.AddPage("templates", modal(list, 50, height), true, true)
+	app.app.SetFocus(list)
+}
+
+func (app *App) closeTemplatePicker() {
+	app.templateOpen = false
+	app.pages.RemovePage("templates")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// listTemplates returns the sorted names of the template folders in config.TemplatesDir.
+func listTemplates(store fsys.Store) []string {
+	entries, err := store.ReadDir(config.TemplatesDir())
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// templateData holds the placeholder values available to override templates,
+// e.g. {{.Name}}, {{.Date}} and {{.User}}.
+type templateData struct {
+	Name string
+	Date string
+	User string
+}
+
+func newTemplateData(name string) templateData {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = os.Getenv("USERNAME")
+	}
+	return templateData{
+		Name: name,
+		Date: time.Now().Format("2006-01-02"),
+		User: user,
+	}
+}
+
+// templateKeywords are the actions of text/template that look like a {{name}} parameter
+var templateKeywords = map[string]bool{"end": true, "else": true, "break": true, "continue": true, "nil": true, "true": true, "false": true}
+
+// escapeParams rewrites the {{name}} parameters of a parameterized override into actions
+// printing them as written, so a template can hold one: text/template would read them
+// as calls of undefined functions. Template fields such as {{.Name}} are left alone.
+func escapeParams(text string) string {
+	return override.ReplaceParams(text, func(name, placeholder string) string {
+		if templateKeywords[name] {
+			return placeholder
+		}
+		return "{{`" + placeholder + "`}}"
+	})
+}
+
+// renderTemplateDir copies a template folder to dst, rendering every file through text/template.
+func renderTemplateDir(store fsys.Store, src, dst string, data templateData) error {
+	return fsys.WalkDir(store, src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dst, relPath)
+
+		if info.IsDir() {
+			return store.MkdirAll(dstPath, 0755)
+		}
+
+		content, err := store.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tmpl, err := template.New(relPath).Parse(escapeParams(string(content)))
+		if err != nil {
+			return fmt.Errorf("parsing template %s: %w", relPath, err)
+		}
+
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("rendering template %s: %w", relPath, err)
+		}
+		return store.WriteFile(dstPath, []byte(buf.String()), info.Mode())
+	})
+}
+
+// renderTemplateString renders a single string through text/template.
+func renderTemplateString(text string, data templateData) (string, error) {
+	tmpl, err := template.New("").Parse(escapeParams(text))
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/ramy/lazyhydra/internal/fsys"
+)
+
+func TestRenderTemplateString(t *testing.T) {
+	data := templateData{Name: "exp", Date: "2024-01-02", User: "ada"}
+	tests := []struct {
+		name, in, want string
+	}{
+		{"fields", "{{.Name}} by {{.User}} on {{.Date}}", "exp by ada on 2024-01-02"},
+		{"params", "lr: {{lr}}\nbatch: {{ batch_size }}\n", "lr: {{lr}}\nbatch: {{ batch_size }}\n"},
+		{"params and fields", "name: {{.Name}}\nlr: {{lr}}", "name: exp\nlr: {{lr}}"},
+		{"keywords", "{{if .User}}by {{.User}}{{else}}anonymous{{end}} {{seed}}", "by ada {{seed}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTemplateString(tt.in, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("renderTemplateString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplateDir(t *testing.T) {
+	store := fsys.NewMemory()
+	store.MkdirAll("/templates/sweep", 0755)
+	store.WriteFile("/templates/sweep/apply.md", []byte("---\ntype: \"+\"\nparams:\n  lr: \"0.1\"\n---\n{{.Name}} by {{.User}}\n"), 0644)
+	store.WriteFile("/templates/sweep/override.yaml", []byte("optimizer:\n  lr: {{lr}}\n"), 0644)
+	store.MkdirAll("/overrides", 0755)
+
+	if err := renderTemplateDir(store, "/templates/sweep", "/overrides/exp", templateData{Name: "exp", User: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := store.ReadFile("/overrides/exp/apply.md"); string(data) != "---\ntype: \"+\"\nparams:\n  lr: \"0.1\"\n---\nexp by ada\n" {
+		t.Errorf("apply.md = %q", data)
+	}
+	if data, _ := store.ReadFile("/overrides/exp/override.yaml"); string(data) != "optimizer:\n  lr: {{lr}}\n" {
+		t.Errorf("override.yaml = %q, want its placeholder kept", data)
+	}
+}
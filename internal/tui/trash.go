@@ -0,0 +1,313 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// trashInfoFile records where a trashed override folder came from. It is written into
+// the folder in the trash and removed again when the override is restored.
+const trashInfoFile = ".trashinfo.yaml"
+
+// trashTimeFormat prefixes the folder names in the trash, so they sort by deletion time
+const trashTimeFormat = "20060102-150405"
+
+// trashEntry is a deleted override kept in the trash
+type trashEntry struct {
+	ID      string    `yaml:"-"` // folder name in the trash, <timestamp>-<name>
+	Name    string    `yaml:"name"`
+	Path    string    `yaml:"path"` // the folder it was deleted from
+	Deleted time.Time `yaml:"deleted"`
+}
+
+// trashDir returns where deleted overrides are kept
+func trashDir() string {
+	return filepath.Join(config.StateDir(), "trash")
+}
+
+// moveDir renames a folder, copying it when a rename is not possible, e.g. because the
+// trash is on another file system
+func moveDir(store fsys.Store, src, dst string) error {
+	err := store.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	if copyErr := copyDir(store, src, dst); copyErr != nil {
+		store.RemoveAll(dst)
+		return err
+	}
+	return store.RemoveAll(src)
+}
+
+// trashOverride moves an override folder into the trash instead of deleting it
+func (app *App) trashOverride(name, folder string) error {
+	now := time.Now()
+	id := now.Format(trashTimeFormat) + "-" + name
+	for i := 2; ; i++ {
+		if _, err := app.FS.Stat(filepath.Join(trashDir(), id)); err != nil {
+			break
+		}
+		id = now.Format(trashTimeFormat) + "-" + name + "-" + strconv.Itoa(i)
+	}
+	target := filepath.Join(trashDir(), id)
+
+	if err := app.FS.MkdirAll(trashDir(), 0755); err != nil {
+		return fmt.Errorf("creating trash: %w", err)
+	}
+	if err := moveDir(app.FS, folder, target); err != nil {
+		return fmt.Errorf("moving %s to the trash: %w", name, err)
+	}
+	data, err := yaml.Marshal(trashEntry{Name: name, Path: folder, Deleted: now})
+	if err != nil {
+		return err
+	}
+	logging.Logger.Debug("moved override to trash", "name", name, "trash", target)
+	return app.FS.WriteFile(filepath.Join(target, trashInfoFile), data, 0644)
+}
+
+// listTrash returns the overrides in the trash, most recently deleted first
+func (app *App) listTrash() []trashEntry {
+	entries, err := app.FS.ReadDir(trashDir())
+	if err != nil {
+		return nil
+	}
+
+	var trash []trashEntry
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := app.FS.ReadFile(filepath.Join(trashDir(), entry.Name(), trashInfoFile))
+		if err != nil {
+			continue
+		}
+		var t trashEntry
+		if err := yaml.Unmarshal(data, &t); err != nil {
+			logging.Logger.Warn("ignoring trash entry with invalid info", "entry", entry.Name(), "error", err)
+			continue
+		}
+		t.ID = entry.Name()
+		trash = append(trash, t)
+	}
+	sort.Slice(trash, func(i, j int) bool {
+		return trash[i].Deleted.After(trash[j].Deleted)
+	})
+	return trash
+}
+
+// findTrash returns the most recently deleted trash entry with the given ID or override name
+func (app *App) findTrash(name string) (trashEntry, bool) {
+	for _, t := range app.listTrash() {
+		if t.ID == name || t.Name == name {
+			return t, true
+		}
+	}
+	return trashEntry{}, false
+}
+
+// restoreTrash moves a trashed override back to where it was deleted from and reloads
+// the overrides. It refuses when an override with the same name exists again.
+func (app *App) restoreTrash(t trashEntry) error {
+	if o := app.Find(t.Name); o != nil {
+		return fmt.Errorf("override %q already exists in %s", t.Name, o.FolderPath)
+	}
+	if _, err := app.FS.Stat(t.Path); err == nil {
+		return fmt.Errorf("%s already exists", t.Path)
+	}
+
+	src := filepath.Join(trashDir(), t.ID)
+	if err := app.FS.MkdirAll(filepath.Dir(t.Path), 0755); err != nil {
+		return fmt.Errorf("restoring %s: %w", t.Name, err)
+	}
+	if err := moveDir(app.FS, src, t.Path); err != nil {
+		return fmt.Errorf("restoring %s: %w", t.Name, err)
+	}
+	app.FS.Remove(filepath.Join(t.Path, trashInfoFile))
+	logging.Logger.Debug("restored override from trash", "name", t.Name, "path", t.Path)
+
+	app.Overrides = nil
+	if err := app.LoadOverrides(); err != nil {
+		return err
+	}
+	app.ReconcileSymlinks()
+	return nil
+}
+
+// purgeTrash permanently deletes the trash entries deleted longer than olderThan ago,
+// or all of them when olderThan is 0. It returns how many were deleted.
+func (app *App) purgeTrash(olderThan time.Duration) (int, error) {
+	count := 0
+	for _, t := range app.listTrash() {
+		if olderThan > 0 && time.Since(t.Deleted) < olderThan {
+			continue
+		}
+		if err := app.FS.RemoveAll(filepath.Join(trashDir(), t.ID)); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// parseAge parses a duration for --older-than, which besides Go durations such as
+// 36h accepts whole days such as 30d
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// runTrashCommand implements `lazyhydra trash list|restore|purge`
+func (app *App) runTrashCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: lazyhydra trash list | restore <name> | purge [--older-than AGE]")
+	}
+
+	switch args[0] {
+	case "list":
+		for _, t := range app.listTrash() {
+			fmt.Printf("%-24s %s  %s\n", t.Name, t.Deleted.Format("2006-01-02 15:04"), homeRelative(t.Path))
+		}
+		return nil
+
+	case "restore":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: lazyhydra trash restore <name>")
+		}
+		if app.DryRun || app.ReadOnly {
+			return fmt.Errorf("restoring from the trash writes override files and is not available in dry-run or read-only mode")
+		}
+		t, ok := app.findTrash(args[1])
+		if !ok {
+			return fmt.Errorf("%s is not in the trash", args[1])
+		}
+		if err := app.restoreTrash(t); err != nil {
+			return err
+		}
+		fmt.Printf("Restored %s to %s\n", t.Name, t.Path)
+		return nil
+
+	case "purge":
+		if app.DryRun || app.ReadOnly {
+			return fmt.Errorf("purging the trash deletes files and is not available in dry-run or read-only mode")
+		}
+		var olderThan time.Duration
+		if value, ok := flagValue(args[1:], "--older-than"); ok {
+			age, err := parseAge(value)
+			if err != nil {
+				return err
+			}
+			olderThan = age
+		}
+		count, err := app.purgeTrash(olderThan)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Purged %d overrides from the trash\n", count)
+		return nil
+	}
+	return fmt.Errorf("unknown trash command %q", args[0])
+}
+
+// showTrash lists the deleted overrides. Enter restores the one under the cursor and D
+// deletes it for good.
+func (app *App) showTrash() {
+	app.trashEntries = app.listTrash()
+	if len(app.trashEntries) == 0 {
+		app.showMessage("The trash is empty")
+		return
+	}
+	if !app.writesAllowed("Restoring overrides") {
+		return
+	}
+
+	app.trashOpen = true
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.NewRGBColor(106, 159, 181)).
+		SetSelectedTextColor(tcell.ColorWhite)
+	for _, t := range app.trashEntries {
+		entry := t
+		list.AddItem(fmt.Sprintf("%s [darkgray]%s, %s[-]", tview.Escape(t.Name), t.Deleted.Format("2006-01-02 15:04"),
+			tview.Escape(homeRelative(filepath.Dir(t.Path)))), "", 0, func() {
+			app.closeTrash()
+			app.restoreFromTrash(entry)
+		})
+	}
+	app.trashList = list
+
+	list.SetBorder(true).
+		SetTitle(" Trash ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(app.trashEntries) + 2
+	if height > 20 {
+		height = 20
+	}
+	app.pages.AddPage("trash", modal(list, 70, height), true, true)
+	app.app.SetFocus(list)
+}
+
+func (app *App) closeTrash() {
+	app.trashOpen = false
+	app.trashList = nil
+	app.pages.RemovePage("trash")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// restoreFromTrash restores a trashed override and selects it in the Available panel
+func (app *App) restoreFromTrash(t trashEntry) {
+	err := app.restoreTrash(t)
+	if app.watcher != nil {
+		app.watchOverrideDirs()
+	}
+	app.refreshAll()
+	if err != nil {
+		app.showError(err)
+		return
+	}
+	if o := app.Find(t.Name); o != nil {
+		app.jumpToOverride(o)
+	}
+	app.showMessage("Restored %s", t.Name)
+}
+
+// purgeSelectedTrash permanently deletes the trash entry under the cursor
+func (app *App) purgeSelectedTrash() {
+	idx := app.trashList.GetCurrentItem()
+	if idx < 0 || idx >= len(app.trashEntries) {
+		return
+	}
+	t := app.trashEntries[idx]
+	if err := app.FS.RemoveAll(filepath.Join(trashDir(), t.ID)); err != nil {
+		app.showError(err)
+		return
+	}
+	app.closeTrash()
+	app.showTrash()
+	app.showMessage("Deleted %s permanently", t.Name)
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/state"
+)
+
+func TestTrashRestore(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	store := fsys.NewMemory()
+	store.MkdirAll("/proj/conf/overrides/foo", 0755)
+	store.WriteFile("/proj/conf/overrides/foo/apply.md", []byte("---\ntype: \"+\"\nblock: a.b\n---\n"), 0644)
+	store.WriteFile("/proj/conf/overrides/foo/override.yaml", []byte("lr: 0.1\n"), 0644)
+
+	cfg := config.Default()
+	cfg.OverridesDir = "/proj/conf/overrides"
+	cfg.HydraConfigsDir = "/proj/conf"
+	app := &App{Project: &state.Project{Config: cfg, FS: store, Root: "/proj", Applied: make(map[string]bool)}}
+	if err := app.LoadOverrides(); err != nil {
+		t.Fatal(err)
+	}
+	foo := app.Find("foo")
+	if _, err := app.Apply(foo); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.trashOverride(foo.Name, foo.FolderPath); err != nil {
+		t.Fatal(err)
+	}
+	app.Forget(foo)
+	if _, err := store.Stat("/proj/conf/overrides/foo"); err == nil {
+		t.Error("trashed folder still exists")
+	}
+	trash := app.listTrash()
+	if len(trash) != 1 || trash[0].Name != "foo" || trash[0].Path != "/proj/conf/overrides/foo" {
+		t.Fatalf("trash = %+v, want foo", trash)
+	}
+
+	// Restoring brings the folder back, without the trash info, and relinks it
+	app.Applied["foo"] = true
+	if err := app.restoreTrash(trash[0]); err != nil {
+		t.Fatal(err)
+	}
+	if app.Find("foo") == nil {
+		t.Error("restored override is not loaded")
+	}
+	if data, err := store.ReadFile("/proj/conf/overrides/foo/override.yaml"); err != nil || string(data) != "lr: 0.1\n" {
+		t.Errorf("restored override.yaml = %q, %v", data, err)
+	}
+	if _, err := store.Stat("/proj/conf/overrides/foo/" + trashInfoFile); err == nil {
+		t.Error("restored folder still holds the trash info")
+	}
+	if _, err := store.Readlink("/proj/conf/a/b/foo_override.yaml"); err != nil {
+		t.Errorf("restored override is not linked: %v", err)
+	}
+	if len(app.listTrash()) != 0 {
+		t.Error("restored override is still in the trash")
+	}
+
+	// Restoring onto an existing override is refused
+	if err := app.trashOverride("foo", "/proj/conf/overrides/foo"); err != nil {
+		t.Fatal(err)
+	}
+	store.MkdirAll("/proj/conf/overrides/foo", 0755)
+	if err := app.restoreTrash(app.listTrash()[0]); err == nil {
+		t.Error("restored onto an existing folder")
+	}
+}
@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/override"
+)
+
+// availableRow is one line of the Available panel: either a group folder or an override
+type availableRow struct {
+	dir      string // group folder relative to the overrides directory (folder rows only)
+	override *override.Override
+	depth    int
+}
+
+// buildAvailableRows lays out the available overrides as a tree grouped by folder.
+// Pinned overrides come first regardless of their folder, then folders before the
+// overrides next to them. Collapsed folders hide their contents.
+func (app *App) buildAvailableRows() []availableRow {
+	var rows []availableRow
+	byDir := make(map[string][]*override.Override)
+	subdirs := make(map[string]map[string]bool)
+	for _, o := range app.getAvailableOverrides() {
+		if app.isPinned(o) {
+			rows = append(rows, availableRow{override: o})
+			continue
+		}
+		byDir[o.Dir] = append(byDir[o.Dir], o)
+
+		// Register every ancestor folder so intermediate groups show up too
+		for dir := o.Dir; dir != ""; {
+			parent := ""
+			if i := strings.LastIndex(dir, "/"); i >= 0 {
+				parent = dir[:i]
+			}
+			if subdirs[parent] == nil {
+				subdirs[parent] = make(map[string]bool)
+			}
+			subdirs[parent][dir] = true
+			dir = parent
+		}
+	}
+
+	var add func(dir string, depth int)
+	add = func(dir string, depth int) {
+		var children []string
+		for child := range subdirs[dir] {
+			children = append(children, child)
+		}
+		sort.Strings(children)
+
+		for _, child := range children {
+			rows = append(rows, availableRow{dir: child, depth: depth})
+			if !app.collapsed[child] {
+				add(child, depth+1)
+			}
+		}
+		for _, o := range byDir[dir] {
+			rows = append(rows, availableRow{override: o, depth: depth})
+		}
+	}
+	add("", 0)
+
+	return rows
+}
+
+// countAvailableIn returns how many available overrides live in dir or below it
+func (app *App) countAvailableIn(dir string) int {
+	n := 0
+	for _, o := range app.getAvailableOverrides() {
+		if o.Dir == dir || strings.HasPrefix(o.Dir, dir+"/") {
+			n++
+		}
+	}
+	return n
+}
+
+// formatAvailableRow returns the list text for a row of the Available panel
+func (app *App) formatAvailableRow(row availableRow) string {
+	indent := strings.Repeat("  ", row.depth)
+	if row.override != nil {
+		return indent + app.markPrefix(row.override) + incompletePrefix(row.override) + app.pinPrefix(row.override) + app.iconPrefix(row.override) + row.override.Name + compositeSuffix(row.override) + archivedSuffix(row.override)
+	}
+
+	name := row.dir[strings.LastIndex(row.dir, "/")+1:]
+	if app.collapsed[row.dir] {
+		return fmt.Sprintf("%s[blue]▸ %s/[-] [darkgray](%d)[-]", indent, name, app.countAvailableIn(row.dir))
+	}
+	return fmt.Sprintf("%s[blue]▾ %s/[-]", indent, name)
+}
+
+// selectedFolder returns the folder under the cursor of the Available panel, if any
+func (app *App) selectedFolder() (string, bool) {
+	if app.currentPanelIdx != 0 {
+		return "", false
+	}
+	idx := app.availableList.GetCurrentItem()
+	if idx < 0 || idx >= len(app.availableRows) || app.availableRows[idx].override != nil {
+		return "", false
+	}
+	return app.availableRows[idx].dir, true
+}
+
+// currentFolder returns the folder the cursor of the Available panel is in, used as the
+// default location for new overrides.
+func (app *App) currentFolder() string {
+	if dir, ok := app.selectedFolder(); ok {
+		return dir
+	}
+	if app.currentPanelIdx == 0 {
+		if o := app.getSelectedOverride(); o != nil {
+			return o.Dir
+		}
+	}
+	return ""
+}
+
+// isPinned reports whether an override is pinned to the top of the Available panel
+func (app *App) isPinned(o *override.Override) bool {
+	return app.ui.Pinned[o.FolderPath]
+}
+
+// pinPrefix returns the list prefix shown before pinned overrides
+func (app *App) pinPrefix(o *override.Override) string {
+	if app.isPinned(o) {
+		return "[yellow]★[-] "
+	}
+	return ""
+}
+
+// togglePin pins or unpins the targeted overrides and remembers it for the next session
+func (app *App) togglePin() {
+	targets := app.actionTargets()
+	if len(targets) == 0 {
+		return
+	}
+
+	// Pin all targets unless every one of them is already pinned
+	pin := false
+	for _, o := range targets {
+		if !app.isPinned(o) {
+			pin = true
+		}
+	}
+	for _, o := range targets {
+		if pin {
+			app.ui.Pinned[o.FolderPath] = true
+		} else {
+			delete(app.ui.Pinned, o.FolderPath)
+		}
+	}
+	app.saveUIState()
+	app.refreshAll()
+
+	verb := "Pinned"
+	if !pin {
+		verb = "Unpinned"
+	}
+	if len(targets) == 1 {
+		app.showMessage("%s %s", verb, targets[0].Name)
+	} else {
+		app.showMessage("%s %d overrides", verb, len(targets))
+	}
+}
+
+// toggleFolder collapses or expands a group folder in the Available panel
+func (app *App) toggleFolder(dir string) {
+	if app.collapsed[dir] {
+		delete(app.collapsed, dir)
+	} else {
+		app.collapsed[dir] = true
+	}
+	app.refreshAll()
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// uiState is TUI state remembered across sessions, kept in the state directory
+// rather than the project so it never shows up in version control.
+type uiState struct {
+	Sort         string               `yaml:"sort,omitempty"`
+	LastApplied  map[string]time.Time `yaml:"last_applied,omitempty"`  // keyed by override folder path
+	ApplyCount   map[string]int       `yaml:"apply_count,omitempty"`   // keyed by override folder path
+	Recent       []recentChange       `yaml:"recent,omitempty"`        // most recently applied or removed first
+	Pinned       map[string]bool      `yaml:"pinned,omitempty"`        // override folder paths
+	Split        int                  `yaml:"split,omitempty"`         // tenths of the width taken by the lists
+	NoWrap       bool                 `yaml:"no_wrap,omitempty"`       // the content view does not wrap long lines
+	LineNumbers  bool                 `yaml:"line_numbers,omitempty"`  // override.yaml is shown with line numbers
+	Columns      bool                 `yaml:"columns,omitempty"`       // the lists show type and block columns
+	GroupApplied bool                 `yaml:"group_applied,omitempty"` // Applied is grouped under block headers
+}
+
+// uiStatePath returns the file the TUI state is stored in
+func uiStatePath() string {
+	return filepath.Join(config.StateDir(), "state.yaml")
+}
+
+// loadUIState reads the saved TUI state. A missing or unreadable file yields empty state.
+func loadUIState() *uiState {
+	state := &uiState{}
+	if data, err := os.ReadFile(uiStatePath()); err == nil {
+		if err := yaml.Unmarshal(data, state); err != nil {
+			logging.Logger.Warn("ignoring invalid state file", "path", uiStatePath(), "error", err)
+		}
+	}
+	if state.LastApplied == nil {
+		state.LastApplied = make(map[string]time.Time)
+	}
+	if state.ApplyCount == nil {
+		state.ApplyCount = make(map[string]int)
+	}
+	if state.Pinned == nil {
+		state.Pinned = make(map[string]bool)
+	}
+	return state
+}
+
+// save writes the TUI state to disk
+func (s *uiState) save() error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(uiStatePath()), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(uiStatePath(), data, 0644)
+}
+
+// moveOverride carries an override's pin, apply time and count and recent changes over
+// to its new folder path
+func (s *uiState) moveOverride(oldPath, newPath string) {
+	if s.Pinned[oldPath] {
+		delete(s.Pinned, oldPath)
+		s.Pinned[newPath] = true
+	}
+	if t, ok := s.LastApplied[oldPath]; ok {
+		delete(s.LastApplied, oldPath)
+		s.LastApplied[newPath] = t
+	}
+	if n, ok := s.ApplyCount[oldPath]; ok {
+		delete(s.ApplyCount, oldPath)
+		s.ApplyCount[newPath] = n
+	}
+	for i := range s.Recent {
+		if s.Recent[i].Path == oldPath {
+			s.Recent[i].Path = newPath
+		}
+	}
+}
+
+// saveUIState saves the TUI state, logging rather than interrupting on failure
+func (app *App) saveUIState() {
+	if err := app.ui.save(); err != nil {
+		app.logError(err)
+	}
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+func (app *App) showValueEditor() {
+	selected := app.getSelectedOverride()
+	if selected == nil {
+		return
+	}
+
+	// Parameterized overrides are edited through their parameters instead
+	if selected.HasParams() {
+		app.showParamsForm(selected)
+		return
+	}
+	if !app.writesAllowed("Editing values") {
+		return
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(selected.Content), &doc); err != nil {
+		app.showError(fmt.Errorf("parsing %s/override.yaml: %w", selected.Name, err))
+		return
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		app.showError(fmt.Errorf("%s/override.yaml has no top-level keys", selected.Name))
+		return
+	}
+
+	// Only top-level scalar values are editable inline
+	root := doc.Content[0]
+	var valueNodes []*yaml.Node
+	form := tview.NewForm()
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			continue
+		}
+		valueNodes = append(valueNodes, value)
+		form.AddInputField(key.Value+": ", value.Value, 40, nil, nil)
+	}
+	if len(valueNodes) == 0 {
+		app.showError(fmt.Errorf("%s/override.yaml has no top-level scalar values", selected.Name))
+		return
+	}
+
+	app.valuesOpen = true
+
+	form.AddButton("Save", func() {
+		changed := false
+		for i, node := range valueNodes {
+			text := form.GetFormItem(i).(*tview.InputField).GetText()
+			if text != node.Value {
+				node.Value = text
+				// Drop the old tag so the new value's type is inferred on write
+				node.Tag = ""
+				changed = true
+			}
+		}
+		if changed {
+			app.writeOverrideValues(selected, &doc)
+		}
+		app.closeValueEditor()
+	})
+	form.AddButton("Cancel", func() {
+		app.closeValueEditor()
+	})
+
+	form.SetFieldBackgroundColor(tcell.ColorDefault).
+		SetButtonBackgroundColor(tcell.NewRGBColor(106, 159, 181))
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Edit Values: %s ", selected.Name)).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	height := len(valueNodes)*2 + 5
+	if height > 25 {
+		height = 25
+	}
+	app.pages.AddPage("values", modal(form, 70, height), true, true)
+	app.app.SetFocus(form)
+}
+
+func (app *App) closeValueEditor() {
+	app.valuesOpen = false
+	app.pages.RemovePage("values")
+	app.app.SetFocus(app.panels[app.currentPanelIdx])
+	app.updateBorderColors()
+}
+
+// writeOverrideValues writes an edited YAML document back to the override's override.yaml.
+func (app *App) writeOverrideValues(o *override.Override, doc *yaml.Node) {
+	var buf strings.Builder
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		app.showError(err)
+		return
+	}
+	encoder.Close()
+
+	if err := app.writeOverrideFile(o, "override.yaml", []byte(buf.String())); err != nil {
+		app.showError(err)
+		return
+	}
+
+	app.reloadOverride(o.Name)
+
+	// Value overrides embed their values in the override string
+	saved := true
+	if app.Applied[o.Name] {
+		saved = app.persistState()
+	}
+	app.refreshAll()
+	if saved {
+		app.showMessage("Saved values for %s", o.Name)
+	}
+}
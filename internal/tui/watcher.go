@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/logging"
+	"github.com/ramy/lazyhydra/internal/override"
+)
+
+// watchDebounce is how long filesystem events must settle before the TUI reloads
+const watchDebounce = 200 * time.Millisecond
+
+// startWatcher watches the overrides directory, the project env file and config.yaml so
+// that changes made outside lazyhydra (git pull, another terminal) show up live.
+func (app *App) startWatcher() error {
+	// Changes on a remote host send no events here; R reloads them
+	if remote != nil {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	app.watcher = watcher
+
+	app.watchOverrideDirs()
+
+	// Watch the env file's directory rather than the file itself, since editors
+	// and atomic writes replace the file instead of modifying it in place
+	envPath := app.EnvFilePath()
+	watcher.Add(filepath.Dir(envPath))
+	watcher.Add(config.Dir())
+
+	// Branch checkouts rewrite HEAD in the git directory
+	headPath := ""
+	if gitDir, ok := findGitDir(app.FS, app.Root); ok {
+		watcher.Add(gitDir)
+		headPath = filepath.Join(gitDir, "HEAD")
+	}
+
+	go app.watchLoop(watcher, app.Config.ExpandPath(app.Config.OverridesDir), envPath, headPath)
+	return nil
+}
+
+// watchOverrideDirs adds the overrides directory, each group folder and each override
+// folder to the watcher. fsnotify is not recursive, so folders created later are added on reload.
+func (app *App) watchOverrideDirs() {
+	dir := app.Config.ExpandPath(app.Config.OverridesDir)
+	app.watcher.Add(dir)
+	for _, o := range app.Overrides {
+		app.watcher.Add(o.FolderPath)
+		for group := o.Dir; group != "" && group != "."; group = filepath.ToSlash(filepath.Dir(group)) {
+			app.watcher.Add(filepath.Join(dir, filepath.FromSlash(group)))
+		}
+	}
+}
+
+// watchLoop reloads the TUI once the events of watcher settle. It gets the watcher and
+// paths it follows as arguments, since reloadConfig replaces them on the UI goroutine
+// while this loop runs, and exits when the watcher is closed.
+func (app *App) watchLoop(watcher *fsnotify.Watcher, overridesDir, envPath, headPath string) {
+	configPath := config.Path()
+	var configChanged atomic.Bool
+
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	// The UI goroutine asks for another try here while a modal is open; the timer is
+	// only touched by this loop
+	retry := make(chan struct{}, 1)
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Name == configPath {
+				configChanged.Store(true)
+				timer.Reset(watchDebounce)
+				continue
+			}
+			// Only config.yaml matters in the config directory
+			if filepath.Dir(event.Name) == filepath.Dir(configPath) && event.Name != overridesDir {
+				continue
+			}
+			// Only the env file matters in its directory; the project root is often busy
+			if filepath.Dir(event.Name) == filepath.Dir(envPath) && event.Name != envPath &&
+				event.Name != overridesDir {
+				continue
+			}
+			// Versions kept in an override's history are not edits of the override
+			if filepath.Base(event.Name) == override.HistoryDir {
+				continue
+			}
+			// Only HEAD matters in the git directory, which is busy during commits
+			if headPath != "" && filepath.Dir(event.Name) == filepath.Dir(headPath) && event.Name != headPath {
+				continue
+			}
+			timer.Reset(watchDebounce)
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+		case <-retry:
+			timer.Reset(watchDebounce)
+		case <-timer.C:
+			app.app.QueueUpdateDraw(func() {
+				// Don't pull state out from under an open modal; try again once it closes
+				if app.modalOpen() {
+					select {
+					case retry <- struct{}{}:
+					default:
+					}
+					return
+				}
+				if configChanged.Swap(false) {
+					app.reloadConfig()
+				} else {
+					app.reloadFromDisk()
+				}
+				app.followGitBranch()
+			})
+		}
+	}
+}
+
+// reloadFromDisk re-reads all overrides and the persisted state, then refreshes the UI.
+func (app *App) reloadFromDisk() error {
+	app.Overrides = nil
+	if err := app.LoadOverrides(); err != nil {
+		return err
+	}
+
+	app.Applied = make(map[string]bool)
+	app.loadPersistedState()
+	app.ReconcileSymlinks()
+
+	if app.watcher != nil {
+		app.watchOverrideDirs()
+	}
+
+	app.refreshAll()
+	app.reportMissing()
+	return nil
+}
+
+// reloadConfig re-reads config.yaml and applies it without restarting: overrides are
+// re-read from the configured directory, symlinks move to where the new config puts
+// them and the watcher follows the new paths. An invalid config is reported and the
+// current one kept.
+func (app *App) reloadConfig() {
+	cfg, err := config.Load(app.FS)
+	if err != nil {
+		app.showError(fmt.Errorf("reloading config: %w", err))
+		return
+	}
+
+	// Unlink with the current config, since the new one may place symlinks elsewhere
+	for _, o := range app.getAppliedOverrides() {
+		app.Unlink(o)
+	}
+	app.Config = cfg
+	app.ReadOnly = app.readOnlyFlag || app.lockReadOnly || cfg.ReadOnly
+	app.availableList.ShowSecondaryText(cfg.ShowDescriptions)
+	app.appliedList.ShowSecondaryText(cfg.ShowDescriptions)
+	app.layoutFromConfig()
+	logging.Logger.Debug("reloaded config", "path", config.Path())
+
+	if app.watcher != nil {
+		app.watcher.Close()
+		app.watcher = nil
+	}
+	err = app.reloadFromDisk()
+	app.startWatcher()
+	if err != nil {
+		app.refreshAll()
+		app.showError(fmt.Errorf("loading overrides: %w", err))
+		return
+	}
+	app.updateDirenvHealth(false)
+	app.showMessage("Reloaded %s", config.Path())
+}
@@ -0,0 +1,159 @@
+// Package lazyhydra lets other Go tools work with a project's Hydra overrides the way
+// the lazyhydra CLI does: load them, apply and remove them, and build the override
+// string.
+//
+//	p, err := lazyhydra.Load("/path/to/project")
+//	if err != nil {
+//		return err
+//	}
+//	if err := p.Apply("wandb_off"); err != nil {
+//		return err
+//	}
+//	fmt.Println(p.BuildString())
+//	return p.Save()
+package lazyhydra
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ramy/lazyhydra/internal/config"
+	"github.com/ramy/lazyhydra/internal/fsys"
+	"github.com/ramy/lazyhydra/internal/override"
+	"github.com/ramy/lazyhydra/internal/state"
+)
+
+// Config is lazyhydra's configuration, read from config.yaml in the config directory
+type Config = config.Config
+
+// Override is one override folder with the metadata from its apply.md
+type Override = override.Override
+
+// ErrReadOnly is returned when changing the applied state of a read-only project
+var ErrReadOnly = errors.New("project is read-only")
+
+// Project is a project's overrides and the set applied in its active environment
+type Project struct {
+	state *state.Project
+}
+
+// Load reads the config, the project's overrides and the state persisted in its env
+// file. Config paths such as $PROJECT_ROOT/conf refer to root; the process environment
+// is left alone.
+func Load(root string) (*Project, error) {
+	cfg, err := config.LoadRoot(fsys.OS, root)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &state.Project{
+		Config:   cfg,
+		FS:       fsys.OS,
+		Root:     root,
+		ReadOnly: cfg.ReadOnly,
+		Applied:  make(map[string]bool),
+	}
+	if err := p.LoadOverrides(); err != nil {
+		return nil, err
+	}
+	if _, err := p.LoadApplied(); err != nil {
+		return nil, fmt.Errorf("loading persisted state: %w", err)
+	}
+	return &Project{state: p}, nil
+}
+
+// Config returns the configuration the project was loaded with
+func (p *Project) Config() *Config {
+	return p.state.Config
+}
+
+// Environment returns the active environment
+func (p *Project) Environment() string {
+	return p.state.Env
+}
+
+// Overrides returns every override, sorted by name
+func (p *Project) Overrides() []*Override {
+	return p.state.Overrides
+}
+
+// Applied returns the applied overrides, sorted by name
+func (p *Project) Applied() []*Override {
+	var applied []*Override
+	for _, o := range p.state.Overrides {
+		if p.state.Applied[o.Name] {
+			applied = append(applied, o)
+		}
+	}
+	return applied
+}
+
+// Apply applies the named overrides and links them into the Hydra config tree. Composite
+// overrides bring the overrides they include. It stops at the first name that is unknown
+// or incomplete, or uses what the project's Hydra version does not support; call Save
+// to persist the result.
+func (p *Project) Apply(names ...string) error {
+	if p.state.ReadOnly {
+		return ErrReadOnly
+	}
+	for _, name := range names {
+		o := p.state.Find(name)
+		if o == nil {
+			return fmt.Errorf("override %q not found", name)
+		}
+		if p.state.Applied[name] {
+			continue
+		}
+		if problems := o.LintProblems(); len(problems) > 0 {
+			return fmt.Errorf("%s is incomplete: %s", name, problems[0])
+		}
+		if problems := o.HydraProblems(p.state.Config); len(problems) > 0 {
+			return fmt.Errorf("%s: %s", name, problems[0])
+		}
+		if _, err := p.state.Apply(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove removes the named overrides and their symlinks. Call Save to persist the result.
+func (p *Project) Remove(names ...string) error {
+	if p.state.ReadOnly {
+		return ErrReadOnly
+	}
+	for _, name := range names {
+		o := p.state.Find(name)
+		if o == nil {
+			return fmt.Errorf("override %q not found", name)
+		}
+		if p.state.Applied[name] {
+			p.state.Remove(o)
+		}
+	}
+	return nil
+}
+
+// BuildString returns the Hydra override string of the applied overrides on one line,
+// as printed by `lazyhydra -p --sep=space`.
+func (p *Project) BuildString() string {
+	return strings.ReplaceAll(p.state.BuildString(), "\n", " ")
+}
+
+// Args returns the Hydra CLI arguments of the applied overrides
+func (p *Project) Args() []string {
+	return p.state.BuildArgs()
+}
+
+// Save writes the applied state to the project's env file, and to overrides_file when
+// it is set. Unlike the CLI it does not run direnv_command or the configured hooks.
+func (p *Project) Save() error {
+	if p.state.ReadOnly {
+		return ErrReadOnly
+	}
+	if _, err := p.state.WriteEnvFile(); err != nil {
+		return err
+	}
+	return p.state.WriteOverridesFile()
+}
@@ -0,0 +1,69 @@
+package lazyhydra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles writes files, by path relative to dir, creating their directories
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("LAZYHYDRA_CONFIG_DIR", t.TempDir())
+	t.Setenv("PROJECT_ROOT", "/elsewhere")
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"conf/overrides/foo/apply.md":      "---\ntype: \"+\"\nblock: a.b\n---\n",
+		"conf/overrides/foo/override.yaml": "lr: 0.1\n",
+		"conf/overrides/bar/apply.md":      "---\ntype: \"++\"\n---\n",
+		"conf/overrides/bar/override.yaml": "seed: 7\n",
+		".lazyhydra.yaml":                  "hydra_version: \"1.3\"\n",
+	})
+
+	p, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("PROJECT_ROOT"); got != "/elsewhere" {
+		t.Errorf("Load changed PROJECT_ROOT to %s", got)
+	}
+	if len(p.Overrides()) != 2 {
+		t.Fatalf("loaded %d overrides from %s, want 2", len(p.Overrides()), root)
+	}
+	if version, _ := p.Config().Hydra(); version != "1.3" {
+		t.Errorf("Hydra version %q, want 1.3 from the project's .lazyhydra.yaml", version)
+	}
+
+	if err := p.Apply("foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.BuildString(), "++seed=7 +a/b=foo_override"; got != want {
+		t.Errorf("BuildString() = %q, want %q", got, want)
+	}
+	if target, err := os.Readlink(filepath.Join(root, "conf", "a", "b", "foo_override.yaml")); err != nil || target != filepath.Join(root, "conf", "overrides", "foo", "override.yaml") {
+		t.Errorf("foo is linked to %q, %v", target, err)
+	}
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Applied()) != 2 {
+		t.Errorf("reloaded %d applied overrides, want 2", len(reloaded.Applied()))
+	}
+}